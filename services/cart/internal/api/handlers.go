@@ -3,23 +3,50 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/devmanishoffl/sabhyatam-cart/internal/client"
 	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/notify"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/pricing"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/promo"
 	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
 )
 
 type Handler struct {
-	store   *store.RedisStore
-	pclient *client.ProductClient
+	store       *store.RedisStore
+	pg          *store.PGStore // nil when Postgres is not configured
+	pclient     *client.ProductClient
+	auth        *client.AuthClient
+	orders      *client.OrdersClient
+	events      notify.Sender
+	pricing     pricing.Rules
+	mergePolicy string
 }
 
-func NewHandler(s *store.RedisStore, pc *client.ProductClient) *Handler {
+func NewHandler(
+	s *store.RedisStore,
+	pg *store.PGStore,
+	pc *client.ProductClient,
+	ac *client.AuthClient,
+	oc *client.OrdersClient,
+	events notify.Sender,
+	rules pricing.Rules,
+	mergePolicy string,
+) *Handler {
 	return &Handler{
-		store:   s,
-		pclient: pc,
+		store:       s,
+		pg:          pg,
+		pclient:     pc,
+		auth:        ac,
+		orders:      oc,
+		events:      events,
+		pricing:     rules,
+		mergePolicy: mergePolicy,
 	}
 }
 
@@ -47,33 +74,18 @@ func (h *Handler) AddItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// fetch existing item (merge quantity)
-	existing, _ := h.store.GetItem(ctx, key, req.ProductID)
-	newQty := req.Quantity
-	if existing != nil {
-		newQty += existing.Quantity
-	}
-
 	// validate product
-	prodResp, err := h.pclient.GetProductDetail(ctx, req.ProductID)
-	if err != nil {
-		http.Error(w, "product validation failed", http.StatusBadGateway)
-		return
-	}
-
-	// Extract product object
-	product, ok := prodResp["product"].(map[string]any)
+	product, ok := h.lookupProduct(w, r, req.ProductID)
 	if !ok {
-		// Fallback: If product service returns raw product (no wrapper)
-		product = prodResp
+		return
 	}
 
-	// stock check
-	if stockRaw, ok := product["stock"]; ok {
-		if stock := asInt(stockRaw); stock >= 0 && stock < newQty {
-			http.Error(w, "insufficient stock", http.StatusConflict)
-			return
-		}
+	// stock and purchase rules cap the resulting line quantity; the store
+	// checks it against the quantity already in the cart atomically
+	maxQty, rule, err := h.lineCap(ctx, key, req.ProductID, product)
+	if err != nil {
+		http.Error(w, "could not check purchase limits", http.StatusBadGateway)
+		return
 	}
 
 	// snapshot price (Product service returns Integer Rupee, Cart needs Integer Paise for logic)
@@ -82,14 +94,21 @@ func (h *Handler) AddItem(w http.ResponseWriter, r *http.Request) {
 		price = asMoney(p)
 	}
 
-	item := model.CartItem{
+	item, err := h.store.AddItem(ctx, key, model.CartItem{
 		ProductID: req.ProductID,
-		Quantity:  newQty,
+		Quantity:  req.Quantity,
 		UnitPrice: price,
 		Currency:  "INR",
+	}, maxQty)
+	if errors.Is(err, store.ErrInsufficientStock) {
+		if rule != nil {
+			writeViolation(w, rule)
+			return
+		}
+		http.Error(w, "insufficient stock", http.StatusConflict)
+		return
 	}
-
-	if err := h.store.SetItem(ctx, key, req.ProductID, item); err != nil {
+	if err != nil {
 		http.Error(w, "redis error", http.StatusInternalServerError)
 		return
 	}
@@ -127,20 +146,27 @@ func (h *Handler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	existing, err := h.store.GetItem(ctx, key, req.ProductID)
-	if err != nil || existing == nil {
-		http.Error(w, "item not found", http.StatusNotFound)
+	limit, rule, err := h.lineLimit(ctx, key, req.ProductID, nil)
+	if err != nil {
+		http.Error(w, "could not check purchase limits", http.StatusBadGateway)
+		return
+	}
+	if rule != nil && req.Quantity > limit {
+		writeViolation(w, rule)
 		return
 	}
 
-	existing.Quantity = req.Quantity
-
-	if err := h.store.SetItem(ctx, key, req.ProductID, *existing); err != nil {
+	item, err := h.store.SetQuantity(ctx, key, req.ProductID, req.Quantity)
+	if errors.Is(err, store.ErrItemNotFound) {
+		http.Error(w, "item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
 		http.Error(w, "redis error", http.StatusInternalServerError)
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(existing)
+	_ = json.NewEncoder(w).Encode(item)
 }
 
 func (h *Handler) RemoveItem(w http.ResponseWriter, r *http.Request) {
@@ -172,31 +198,77 @@ func (h *Handler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := h.store.GetAll(ctx, key)
+	resp, lines, err := h.buildCart(ctx, key)
 	if err != nil {
 		http.Error(w, "redis error", http.StatusInternalServerError)
 		return
 	}
+	h.applyTotals(ctx, key, resp, lines)
+	if resp.Merge, err = h.store.TakeMergeReport(ctx, key); err != nil {
+		log.Println("cart merge report:", err)
+	}
+	log.Println("CART SESSION:", ctx.Value(CtxSessionID))
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// cartLine is a hydrated line with what promotions and pricing need.
+type cartLine struct {
+	promo.Line
+	HSNCode     string
+	WeightGrams int
+}
+
+// buildCart hydrates a cart, fixing up lines that changed since they were
+// added, and returns the lines for the promotion engine and pricing.
+func (h *Handler) buildCart(ctx context.Context, key string) (*model.CartResponse, []cartLine, error) {
+	items, err := h.store.GetAll(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	resp := model.CartResponse{
+	resp := &model.CartResponse{
 		Currency: "INR",
 	}
+	var lines []cartLine
 
+	// 1. Hydrate every line in as few round trips as possible
+	ids := make([]string, 0, len(items))
 	for _, it := range items {
-		prodResp, err := h.pclient.GetProductDetail(ctx, it.ProductID)
-		if err != nil {
+		ids = append(ids, it.ProductID)
+	}
+	products, missing := h.pclient.HydrateProducts(ctx, ids)
+
+	for _, it := range items {
+		productRaw, ok := products[it.ProductID]
+		if !ok && missing[it.ProductID] {
+			// deleted product → auto remove, and tell the shopper
+			h.removeLine(ctx, key, it.ProductID, "", model.RemovedDeleted)
 			continue
 		}
-
-		productRaw, ok := prodResp["product"].(map[string]any)
 		if !ok {
-			// Fallback: If product service returns raw product
-			productRaw = prodResp
+			// product service unreachable → keep the line on its snapshot
+			lineTotal := it.UnitPrice * int64(it.Quantity)
+			resp.Items = append(resp.Items, model.HydratedItem{
+				Product:          map[string]any{"id": it.ProductID},
+				Quantity:         it.Quantity,
+				UnitPrice:        it.UnitPrice,
+				LineTotal:        lineTotal,
+				RegistryID:       it.RegistryID,
+				RegistryQuantity: it.RegistryUnits(),
+				Unavailable:      true,
+			})
+			lines = append(lines, cartLine{Line: promo.Line{ProductID: it.ProductID, UnitPrice: it.UnitPrice, Quantity: it.Quantity}})
+			resp.Subtotal += lineTotal
+			resp.ItemCount += it.Quantity
+			continue
 		}
+		title, _ := productRaw["title"].(string)
 
 		// unpublished product → auto remove
 		if pub, ok := productRaw["published"].(bool); ok && !pub {
-			_ = h.store.DeleteItem(ctx, key, it.ProductID)
+			h.removeLine(ctx, key, it.ProductID, title, model.RemovedUnpublished)
 			continue
 		}
 
@@ -209,97 +281,231 @@ func (h *Handler) GetCart(w http.ResponseWriter, r *http.Request) {
 		// 3. Enforce stock limits
 		if s, ok := productRaw["stock"]; ok {
 			maxQty := asInt(s)
-			if it.Quantity > maxQty {
-				it.Quantity = maxQty
-				if it.Quantity <= 0 {
-					_ = h.store.DeleteItem(ctx, key, it.ProductID)
+			if maxQty >= 0 && it.Quantity > maxQty {
+				clamped, err := h.store.ClampQuantity(ctx, key, it.ProductID, maxQty)
+				if err == nil {
+					n := model.Notice{
+						Type:        model.NoticeQuantityReduced,
+						ProductID:   it.ProductID,
+						Title:       title,
+						OldQuantity: it.Quantity,
+						NewQuantity: maxQty,
+					}
+					if clamped == nil {
+						n = model.Notice{
+							Type:      model.NoticeItemRemoved,
+							ProductID: it.ProductID,
+							Title:     title,
+							Reason:    model.RemovedOutOfStock,
+						}
+					}
+					if err := h.store.AddNotice(ctx, key, n); err != nil {
+						log.Println("cart notice:", err)
+					}
+				}
+				if err != nil || clamped == nil {
 					continue
 				}
-				_ = h.store.SetItem(ctx, key, it.ProductID, it)
+				it = *clamped
 			}
 		}
 
 		lineTotal := unitPrice * int64(it.Quantity)
 
 		// 4. Extract hero image
-		// FIX: Check INSIDE productRaw["media"] first, then siblings
-		image := ""
-
-		// Helper to find image in a list
-		findImage := func(list []any) string {
-			if len(list) > 0 {
-				if m, ok := list[0].(map[string]any); ok {
-					if u, ok := m["url"].(string); ok {
-						return u
-					}
-				}
-			}
-			return ""
-		}
-
-		// Check nested media (Standard)
-		if media, ok := productRaw["media"].([]any); ok {
-			image = findImage(media)
-		}
+		image, _ := productRaw["image_url"].(string)
 
-		// If not found, check root media (Legacy/Wrapper)
-		if image == "" {
-			if media, ok := prodResp["media"].([]any); ok {
-				image = findImage(media)
-			}
-		}
-
-		resp.Items = append(resp.Items, model.HydratedItem{
+		line := model.HydratedItem{
 			Product: map[string]any{
 				"id":    productRaw["id"],
 				"title": productRaw["title"],
 				"slug":  productRaw["slug"],
 				"image": image,
 			},
-			Quantity:  it.Quantity,
-			UnitPrice: unitPrice,
-			LineTotal: lineTotal,
+			Quantity:         it.Quantity,
+			UnitPrice:        unitPrice,
+			LineTotal:        lineTotal,
+			RegistryID:       it.RegistryID,
+			RegistryQuantity: it.RegistryUnits(),
+		}
+
+		// 5. Price moved since it was added (old snapshots may lack one)
+		if it.UnitPrice > 0 && it.UnitPrice != unitPrice {
+			n := model.Notice{
+				Type:      model.NoticePriceIncreased,
+				ProductID: it.ProductID,
+				Title:     title,
+				OldPrice:  it.UnitPrice,
+				NewPrice:  unitPrice,
+			}
+			if unitPrice < it.UnitPrice {
+				n.Type = model.NoticePriceDecreased
+			}
+			line.Notices = append(line.Notices, n)
+		}
+
+		resp.Items = append(resp.Items, line)
+		category, _ := productRaw["category"].(string)
+		attrs, _ := productRaw["attributes"].(map[string]any)
+		lines = append(lines, cartLine{
+			Line: promo.Line{
+				ProductID: it.ProductID,
+				Category:  category,
+				UnitPrice: unitPrice,
+				Quantity:  it.Quantity,
+			},
+			HSNCode:     hsnCode(attrs["hsn_code"]),
+			WeightGrams: asInt(attrs["weight_grams"]),
 		})
 
 		resp.Subtotal += lineTotal
 		resp.ItemCount += it.Quantity
 	}
-	log.Println("CART SESSION:", ctx.Value(CtxSessionID))
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	h.attachNotices(ctx, key, resp)
+	return resp, lines, nil
 }
 
-func (h *Handler) MergeCarts(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		GuestID string `json:"guest_id"`
-		UserID  string `json:"user_id"`
+// removeLine drops a line the shopper can no longer buy and records why.
+func (h *Handler) removeLine(ctx context.Context, key, productID, title, reason string) {
+	if err := h.store.DeleteItem(ctx, key, productID); err != nil {
+		log.Println("cart remove:", err)
+		return
 	}
+	err := h.store.AddNotice(ctx, key, model.Notice{
+		Type:      model.NoticeItemRemoved,
+		ProductID: productID,
+		Title:     title,
+		Reason:    reason,
+	})
+	if err != nil {
+		log.Println("cart notice:", err)
+	}
+}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid body", http.StatusBadRequest)
+// attachNotices adds the stored notices to a hydrated cart: quantity
+// reductions go on their line, removals on the cart. Notices about lines the
+// shopper has since removed are dropped from the response.
+func (h *Handler) attachNotices(ctx context.Context, key string, resp *model.CartResponse) {
+	resp.Notices = []model.Notice{}
+
+	stored, err := h.store.GetNotices(ctx, key)
+	if err != nil {
+		log.Println("cart notices:", err)
+	}
+	for _, n := range stored {
+		if n.Type == model.NoticeItemRemoved {
+			resp.Notices = append(resp.Notices, n)
+			continue
+		}
+		for i := range resp.Items {
+			if resp.Items[i].Product["id"] == n.ProductID {
+				resp.Items[i].Notices = append(resp.Items[i].Notices, n)
+				break
+			}
+		}
+	}
+
+	resp.RequiresAcknowledgement = len(resp.Notices) > 0
+	for _, it := range resp.Items {
+		if len(it.Notices) > 0 {
+			resp.RequiresAcknowledgement = true
+		}
+	}
+}
+
+// AcknowledgeNotices accepts the current cart: price snapshots move to the
+// live prices and stored notices are cleared, so checkout can proceed.
+func (h *Handler) AcknowledgeNotices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
 		return
 	}
 
-	src := "cart:guest:" + req.GuestID
-	dst := "cart:user:" + req.UserID
+	items, err := h.store.GetAll(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
 
-	if err := h.store.Merge(r.Context(), dst, src); err != nil {
-		http.Error(w, "merge failed", http.StatusInternalServerError)
+	ids := make([]string, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	products, err := h.pclient.GetProducts(ctx, ids)
+	if err != nil {
+		http.Error(w, "product lookup failed", http.StatusBadGateway)
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "merged"})
+	for _, it := range items {
+		productRaw, ok := products[it.ProductID]
+		if !ok {
+			continue
+		}
+		v, ok := productRaw["price"]
+		if !ok {
+			continue
+		}
+		price := asMoney(v)
+		if price == it.UnitPrice {
+			continue
+		}
+		if _, err := h.store.SetUnitPrice(ctx, key, it.ProductID, price); err != nil && !errors.Is(err, store.ErrItemNotFound) {
+			http.Error(w, "redis error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.store.ClearNotices(ctx, key); err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "acknowledged"})
 }
 
 // ---------- helpers ----------
-func resolveKey(ctx context.Context) string {
-	if sid := ctx.Value(CtxSessionID); sid != nil && sid.(string) != "" {
-		return "session:" + sid.(string)
+
+// lookupProduct loads a product a shopper wants to add, answering 404 if it
+// is gone and 409 if it is unpublished.
+func (h *Handler) lookupProduct(w http.ResponseWriter, r *http.Request, productID string) (map[string]any, bool) {
+	products, err := h.pclient.GetProducts(r.Context(), []string{productID})
+	if err != nil {
+		http.Error(w, "product validation failed", http.StatusBadGateway)
+		return nil, false
+	}
+	product, ok := products[productID]
+	if !ok {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return nil, false
 	}
+	if pub, ok := product["published"].(bool); ok && !pub {
+		http.Error(w, "product not available", http.StatusConflict)
+		return nil, false
+	}
+	return product, true
+}
+
+// productStock is the product's stock, or -1 when it is not reported.
+func productStock(product map[string]any) int {
+	if v, ok := product["stock"]; ok {
+		return asInt(v)
+	}
+	return -1
+}
+
+// resolveKey picks the cart for a request: a verified user's own cart, else
+// the guest session's. Guest carts are merged into the user's on sign-in.
+func resolveKey(ctx context.Context) string {
 	if uid := ctx.Value(CtxUserID); uid != nil && uid.(string) != "" {
 		return "user:" + uid.(string)
 	}
+	if sid := ctx.Value(CtxSessionID); sid != nil && sid.(string) != "" {
+		return "session:" + sid.(string)
+	}
 	return ""
 }
 
@@ -331,6 +537,20 @@ func asMoney(v any) int64 {
 	}
 }
 
+// hsnCode reads attributes.hsn_code, which admins may enter as a number.
+func hsnCode(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x)
+	case float64:
+		return strconv.FormatInt(int64(x), 10)
+	case json.Number:
+		return x.String()
+	default:
+		return ""
+	}
+}
+
 func (h *Handler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	key := resolveKey(ctx)
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+// ParseMergePolicy validates CART_MERGE_POLICY; empty means sum.
+func ParseMergePolicy(v string) (string, error) {
+	switch v {
+	case "":
+		return model.MergeSum, nil
+	case model.MergeSum, model.MergeMax, model.MergeKeepUser:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown merge policy %q", v)
+}
+
+// MergeOnLogin folds the guest session's cart into the user's the first
+// time a request carries both a verified user and that session, whether
+// the session came from the cookie or X-SESSION-ID. The session ID is the
+// guest cart's only credential, so whoever holds it may already read and
+// change that cart; merging it needs no more trust than that. The report
+// is kept for the next GetCart. A failed merge leaves the guest cart in
+// place to be retried on the next request.
+func (h *Handler) MergeOnLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		uid, _ := ctx.Value(CtxUserID).(string)
+		sid, _ := ctx.Value(CtxSessionID).(string)
+		if uid != "" && sid != "" {
+			if err := h.mergeSession(ctx, "user:"+uid, "session:"+sid); err != nil {
+				log.Println("cart merge:", err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mergeSession merges src into dst, clamping to current stock, and saves
+// the report. Sessions with nothing to merge are left alone.
+func (h *Handler) mergeSession(ctx context.Context, dst, src string) error {
+	pending, err := h.store.HasGuestData(ctx, src)
+	if err != nil || !pending {
+		return err
+	}
+
+	items, err := h.store.GetAll(ctx, src)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+
+	// Products that can't be loaded merge unclamped; hydration clamps them
+	// once the product service answers.
+	limits := map[string]int{}
+	products, missing := h.pclient.HydrateProducts(ctx, ids)
+	for _, id := range ids {
+		if p, ok := products[id]; ok {
+			if stock := productStock(p); stock >= 0 {
+				limits[id] = stock
+			}
+		} else if missing[id] {
+			limits[id] = 0
+		}
+	}
+
+	report, err := h.store.Merge(ctx, dst, src, h.mergePolicy, limits)
+	if err != nil {
+		return err
+	}
+	if err := h.store.SaveMergeReport(ctx, dst, report); err != nil {
+		log.Println("cart merge report:", err)
+	}
+	if h.pg != nil {
+		for _, key := range []string{dst, src} {
+			if err := h.store.TouchCart(ctx, key); err != nil {
+				log.Println("cart activity:", err)
+			}
+		}
+	}
+	return nil
+}
+
+// MergeCarts returns what MergeOnLogin merged for the signed-in user, so a
+// client can show it right after sign-in. It retries the merge first, in
+// case the automatic one failed.
+func (h *Handler) MergeCarts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid, _ := ctx.Value(CtxUserID).(string)
+	if uid == "" {
+		http.Error(w, "sign in to merge carts", http.StatusUnauthorized)
+		return
+	}
+	key := "user:" + uid
+
+	if sid, _ := ctx.Value(CtxSessionID).(string); sid != "" {
+		if err := h.mergeSession(ctx, key, "session:"+sid); err != nil {
+			http.Error(w, "redis error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	report, err := h.store.TakeMergeReport(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		report = &model.MergeReport{Policy: h.mergePolicy, Lines: []model.MergeLine{}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
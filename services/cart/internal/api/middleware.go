@@ -2,8 +2,13 @@ package api
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"os"
+	"strings"
 
+	"github.com/devmanishoffl/sabhyatam-cart/internal/client"
 	"github.com/google/uuid"
 )
 
@@ -14,37 +19,110 @@ const (
 	CtxUserID    ctxKey = "user_id"
 )
 
-func UserSessionMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// UserSessionMiddleware puts the shopper's identity in the context. A user
+// ID is only taken from a bearer token Supabase accepts, or from
+// X-USER-ID on calls carrying the internal service key; a bare X-USER-ID
+// is ignored. Every request also gets a session, new if need be.
+func UserSessionMiddleware(auth *client.AuthClient) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			ctx := r.Context()
+
+			uid, err := verifiedUserID(r, auth)
+			if err != nil {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			if uid != "" {
+				ctx = context.WithValue(ctx, CtxUserID, uid)
+			}
+
+			cookie, _ := r.Cookie("sabhyatam_session")
+
+			// 1️⃣ INTERNAL / API CALLS: trust header first
+			if sid := r.Header.Get("X-SESSION-ID"); sid != "" {
+				ctx = context.WithValue(ctx, CtxSessionID, sid)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			// 2️⃣ BROWSER: read cookie
+			if cookie != nil && cookie.Value != "" {
+				ctx = context.WithValue(ctx, CtxSessionID, cookie.Value)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			// Signed-in users without a session don't need one
+			if uid != "" {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
 
-		ctx := r.Context()
+			// 3️⃣ New guest session (browser only)
+			sessionID := uuid.NewString()
 
-		// 1️⃣ INTERNAL / API CALLS: trust header first
-		if sid := r.Header.Get("X-SESSION-ID"); sid != "" {
-			ctx = context.WithValue(ctx, CtxSessionID, sid)
+			http.SetCookie(w, &http.Cookie{
+				Name:     "sabhyatam_session",
+				Value:    sessionID,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+
+			ctx = context.WithValue(ctx, CtxSessionID, sessionID)
 			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func verifiedUserID(r *http.Request, auth *client.AuthClient) (string, error) {
+	if key := os.Getenv("INTERNAL_SERVICE_KEY"); key != "" && r.Header.Get("X-INTERNAL-KEY") == key {
+		return r.Header.Get("X-USER-ID"), nil
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", nil
+	}
+	uid, err := auth.VerifyToken(r.Context(), token)
+	if errors.Is(err, client.ErrInvalidToken) {
+		return "", err
+	}
+	if err != nil {
+		// Supabase unreachable: carry on as a guest rather than lock the
+		// shopper out of their cart.
+		log.Println("verify token:", err)
+		return "", nil
+	}
+	return uid, nil
+}
+
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		adminKey := os.Getenv("ADMIN_KEY")
+		if adminKey == "" {
+			http.Error(w, "admin key not configured", http.StatusInternalServerError)
 			return
 		}
-
-		// 2️⃣ BROWSER: read cookie
-		if cookie, err := r.Cookie("sabhyatam_session"); err == nil && cookie.Value != "" {
-			ctx = context.WithValue(ctx, CtxSessionID, cookie.Value)
-			next.ServeHTTP(w, r.WithContext(ctx))
+		if r.Header.Get("X-ADMIN-KEY") != adminKey {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		// 3️⃣ New guest session (browser only)
-		sessionID := uuid.NewString()
-
-		http.SetCookie(w, &http.Cookie{
-			Name:     "sabhyatam_session",
-			Value:    sessionID,
-			Path:     "/",
-			HttpOnly: true,
-			SameSite: http.SameSiteLaxMode,
-		})
-
-		ctx = context.WithValue(ctx, CtxSessionID, sessionID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// InternalOnly guards service-to-service endpoints with the shared
+// INTERNAL_SERVICE_KEY.
+func InternalOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := os.Getenv("INTERNAL_SERVICE_KEY")
+		if key == "" || r.Header.Get("X-INTERNAL-KEY") != key {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/pricing"
+)
+
+// applyTotals applies promotions, then works out the GST included in the
+// discounted lines and the delivery charges on top, and lists the cart rules
+// the result breaks.
+func (h *Handler) applyTotals(ctx context.Context, key string, resp *model.CartResponse, lines []cartLine) {
+	h.applyPromotions(ctx, key, resp, promoLines(lines))
+
+	opts, err := h.store.GetDelivery(ctx, key)
+	if err != nil {
+		log.Println("cart delivery:", err)
+	}
+	resp.Delivery = opts
+
+	pl := make([]pricing.Line, 0, len(lines))
+	for _, l := range lines {
+		pl = append(pl, pricing.Line{
+			ProductID:   l.ProductID,
+			HSNCode:     l.HSNCode,
+			UnitPrice:   l.UnitPrice,
+			Quantity:    l.Quantity,
+			WeightGrams: l.WeightGrams,
+		})
+	}
+	res := pricing.Price(h.pricing, pl, resp.DiscountTotal, opts)
+	resp.Tax = res.Tax
+	resp.Charges = res.Charges
+	resp.GrandTotal = res.GrandTotal
+
+	h.applyRules(ctx, resp, lines)
+}
+
+// SetDelivery expects: { "pincode": "221001", "cod": false }. The pincode
+// decides the CGST+SGST or IGST split; cod adds the COD surcharge. An empty
+// pincode clears it.
+func (h *Handler) SetDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	var req model.DeliveryOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	req.Pincode = strings.TrimSpace(req.Pincode)
+	if req.Pincode != "" {
+		if _, ok := pricing.StateForPincode(req.Pincode); !ok {
+			http.Error(w, "we do not recognise this pincode", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	if err := h.store.SetDelivery(ctx, key, req); err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	resp, lines, err := h.buildCart(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	h.applyTotals(ctx, key, resp, lines)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/promo"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func requestUserID(ctx context.Context) string {
+	uid, _ := ctx.Value(CtxUserID).(string)
+	return uid
+}
+
+// evaluate runs the promotion engine over a cart's lines.
+func (h *Handler) evaluate(ctx context.Context, lines []promo.Line, coupon string) (promo.Result, error) {
+	promos, err := h.store.ListPromotions(ctx)
+	if err != nil {
+		return promo.Result{}, err
+	}
+	userID := requestUserID(ctx)
+	usage, err := h.store.PromotionUsage(ctx, promos, userID)
+	if err != nil {
+		return promo.Result{}, err
+	}
+	return promo.Evaluate(promos, usage, promo.Cart{
+		Lines:  lines,
+		UserID: userID,
+		Coupon: coupon,
+		Now:    time.Now(),
+	}), nil
+}
+
+func promoLines(lines []cartLine) []promo.Line {
+	out := make([]promo.Line, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, l.Line)
+	}
+	return out
+}
+
+// applyPromotions fills the discount fields of a hydrated cart. Promotions
+// are a bonus, so a failure leaves the cart at full price.
+func (h *Handler) applyPromotions(ctx context.Context, key string, resp *model.CartResponse, lines []promo.Line) {
+	resp.Discounts = []model.DiscountLine{}
+	resp.Total = resp.Subtotal
+
+	coupon, err := h.store.GetCoupon(ctx, key)
+	if err != nil {
+		log.Println("cart coupon:", err)
+	}
+	resp.CouponCode = coupon
+
+	res, err := h.evaluate(ctx, lines, coupon)
+	if err != nil {
+		log.Println("cart promotions:", err)
+		return
+	}
+	if res.Discounts != nil {
+		resp.Discounts = res.Discounts
+	}
+	resp.DiscountTotal = res.Total
+	resp.Total = resp.Subtotal - res.Total
+	resp.CouponError = res.CouponError
+}
+
+// ApplyCoupon expects: { "code": "FESTIVE10" }. The code must apply to the
+// cart as it stands; otherwise the reason is returned with 422 and the cart
+// keeps its previous coupon. A cart holds one coupon at a time.
+func (h *Handler) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Code) == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+
+	if _, err := h.store.FindPromotionByCode(ctx, code); errors.Is(err, store.ErrPromotionNotFound) {
+		http.Error(w, "this code is not valid", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	resp, lines, err := h.buildCart(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	res, err := h.evaluate(ctx, promoLines(lines), code)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if res.CouponError != "" {
+		http.Error(w, res.CouponError, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.store.SetCoupon(ctx, key, code); err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	h.applyTotals(ctx, key, resp, lines)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.ClearCoupon(ctx, key); err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "removed"})
+}
+
+// RedeemPromotions is called by the orders service as an order is placed:
+// { "order_id": "...", "user_id": "...", "promotion_ids": [...] }. It counts
+// one use of each promotion, or fails with 409 if one is used up or gone,
+// in which case the order must not go ahead. Repeated calls for the same
+// order count once.
+func (h *Handler) RedeemPromotions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderID      string   `json:"order_id"`
+		UserID       string   `json:"user_id"`
+		PromotionIDs []string `json:"promotion_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	redeemed, err := h.store.RedeemPromotions(ctx, req.OrderID, req.UserID, req.PromotionIDs)
+	switch {
+	case errors.Is(err, store.ErrPromotionExhausted), errors.Is(err, store.ErrPromotionNotFound):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	// Keep the durable copy; if it cannot be written, give the uses back
+	// and fail so the order is not placed with an unrecorded redemption.
+	if redeemed && h.pg != nil {
+		if err := h.pg.RecordRedemption(ctx, req.OrderID, req.UserID, req.PromotionIDs); err != nil {
+			if _, rerr := h.store.ReleasePromotions(ctx, req.OrderID); rerr != nil {
+				log.Println("release unrecorded redemption:", rerr)
+			}
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	status := "redeemed"
+	if !redeemed {
+		status = "already_redeemed"
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// ReleasePromotions is called by the orders service when an unpaid order is
+// cancelled: { "order_id": "..." }. It gives back the uses the order
+// counted; repeated calls release once.
+func (h *Handler) ReleasePromotions(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderID string `json:"order_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	released, err := h.store.ReleasePromotions(ctx, req.OrderID)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	// Deleted even when Redis had nothing to release, so a retry after a
+	// failed delete still clears the durable copy.
+	if h.pg != nil {
+		if err := h.pg.DeleteRedemption(ctx, req.OrderID); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	status := "released"
+	if !released {
+		status = "not_redeemed"
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// --- ADMIN ---
+
+func (h *Handler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	promos, err := h.store.ListPromotions(r.Context())
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	usage, err := h.store.PromotionUsage(r.Context(), promos, "")
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	type row struct {
+		model.Promotion
+		Redemptions int `json:"redemptions"`
+	}
+	out := make([]row, 0, len(promos))
+	for _, p := range promos {
+		out = append(out, row{p, usage[p.ID].Global})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"promotions": out})
+}
+
+func (h *Handler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	h.savePromotion(w, r, "", true)
+}
+
+func (h *Handler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	h.savePromotion(w, r, chi.URLParam(r, "id"), false)
+}
+
+func (h *Handler) savePromotion(w http.ResponseWriter, r *http.Request, id string, create bool) {
+	var p model.Promotion
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if !create {
+		p.ID = id
+	}
+	if err := p.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.store.SavePromotion(r.Context(), &p, create)
+	switch {
+	case errors.Is(err, store.ErrPromotionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrPromotionExists), errors.Is(err, store.ErrCodeTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if h.pg != nil {
+		if err := h.pg.SavePromotion(r.Context(), &p); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if create {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+func (h *Handler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := h.store.DeletePromotion(r.Context(), id)
+	if errors.Is(err, store.ErrPromotionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if h.pg != nil {
+		if err := h.pg.DeletePromotion(r.Context(), id); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
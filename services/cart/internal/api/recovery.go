@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/notify"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// TrackActivity marks the request's cart as changed after any successful
+// POST, for the snapshot worker to pick up.
+func (h *Handler) TrackActivity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.pg == nil || r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		if ww.Status() < 400 {
+			if key := resolveKey(r.Context()); key != "" {
+				if err := h.store.TouchCart(r.Context(), key); err != nil {
+					log.Println("cart activity:", err)
+				}
+			}
+		}
+	})
+}
+
+// RecoverCart expects: { "token": "..." } from a reminder link and adds the
+// snapshot's lines to the current cart. Lines already in the cart are left
+// as they are.
+func (h *Handler) RecoverCart(w http.ResponseWriter, r *http.Request) {
+	if h.pg == nil {
+		http.Error(w, "cart recovery is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	hash := store.HashRecoveryToken(req.Token)
+
+	snap, err := h.pg.SnapshotForToken(ctx, hash)
+	if errors.Is(err, store.ErrTokenNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	items := make(map[string]string, len(snap.Items))
+	for _, it := range snap.Items {
+		b, _ := json.Marshal(it)
+		items[it.ProductID] = string(b)
+	}
+	if _, err := h.store.RestoreItems(ctx, key, items); err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.pg.MarkRecovered(ctx, snap.CartKey, hash); err != nil {
+		log.Println("cart recovery:", err)
+	}
+	if err := h.pg.RecordEvent(ctx, snap.CartKey, model.EventCartRecovered, map[string]string{"into": key}); err != nil {
+		log.Println("cart recovery:", err)
+	}
+	e := notify.Event{
+		Type:       model.EventCartRecovered,
+		CartKey:    snap.CartKey,
+		Items:      snap.Items,
+		Subtotal:   snap.Subtotal,
+		Currency:   "INR",
+		OccurredAt: time.Now().UTC(),
+	}
+	if snap.UserID != nil {
+		e.UserID = *snap.UserID
+	}
+	if err := h.events.Send(ctx, e); err != nil {
+		log.Println("cart recovery event:", err)
+	}
+
+	resp, lines, err := h.buildCart(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	h.applyTotals(ctx, key, resp, lines)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// SetReminders expects: { "opt_out": true } from a signed-in shopper, or
+// with "token" from a reminder link's unsubscribe. Links get forwarded, so
+// a token can only opt out, once; opting back in needs the shopper signed
+// in.
+func (h *Handler) SetReminders(w http.ResponseWriter, r *http.Request) {
+	if h.pg == nil {
+		http.Error(w, "cart recovery is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := r.Context()
+
+	var req struct {
+		OptOut bool   `json:"opt_out"`
+		Token  string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	userID := requestUserID(ctx)
+	if req.Token != "" {
+		if !req.OptOut {
+			http.Error(w, "sign in to turn reminders back on", http.StatusForbidden)
+			return
+		}
+		uid, err := h.pg.UseTokenForOptOut(ctx, store.HashRecoveryToken(req.Token))
+		if errors.Is(err, store.ErrTokenNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+		userID = uid
+	}
+	if userID == "" {
+		http.Error(w, "sign in to change reminders", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.pg.SetReminderOptOut(ctx, userID, req.OptOut); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]bool{"opt_out": req.OptOut})
+}
+
+// --- ADMIN ---
+
+func (h *Handler) ListAbandonedCarts(w http.ResponseWriter, r *http.Request) {
+	if h.pg == nil {
+		http.Error(w, "cart recovery is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	carts, total, err := h.pg.ListAbandoned(r.Context(), page, limit)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"carts": carts,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// MarkCartConverted is called by the orders service once an order is paid:
+// { "order_id": "...", "cart_key": "user:..." }, where cart_key is the
+// cart the order was prepared from. The cart stops getting reminders until
+// it changes again. Repeat calls are harmless.
+func (h *Handler) MarkCartConverted(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderID string `json:"order_id"`
+		CartKey string `json:"cart_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	var userID *string
+	if uid, ok := strings.CutPrefix(req.CartKey, "user:"); ok && uid != "" {
+		userID = &uid
+	} else if sid, ok := strings.CutPrefix(req.CartKey, "session:"); !ok || sid == "" {
+		http.Error(w, "invalid cart key", http.StatusBadRequest)
+		return
+	}
+	if h.pg == nil {
+		// no snapshots, so no reminders to stop
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "not_tracked"})
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.pg.MarkConverted(ctx, req.CartKey, userID); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.pg.RecordEvent(ctx, req.CartKey, model.EventCartConverted, map[string]string{"order_id": req.OrderID}); err != nil {
+		log.Println("cart converted:", err)
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "converted"})
+}
@@ -9,15 +9,63 @@ import (
 func RegisterRoutes(r *chi.Mux, h *Handler) {
 	r.Route("/v1/cart", func(r chi.Router) {
 
-		r.Use(UserSessionMiddleware)
+		r.Use(UserSessionMiddleware(h.auth))
+		r.Use(h.MergeOnLogin)
+		r.Use(h.TrackActivity)
 		r.Get("/", h.GetCart)
 		r.Post("/add", h.AddItem)
 		r.Post("/clear", h.ClearCart)
+		r.Post("/acknowledge", h.AcknowledgeNotices)
 
 		r.Post("/update", h.UpdateItem)
 		r.Post("/remove", h.RemoveItem)
 		r.Post("/merge", h.MergeCarts)
+		r.Post("/move-to-wishlist", h.MoveToWishlist)
+
+		r.Get("/wishlist", h.GetWishlist)
+		r.Post("/wishlist/add", h.AddToWishlist)
+		r.Post("/wishlist/remove", h.RemoveFromWishlist)
+		r.Post("/wishlist/move-to-cart", h.MoveToCart)
+
+		r.Post("/coupon/apply", h.ApplyCoupon)
+		r.Post("/coupon/remove", h.RemoveCoupon)
+
+		r.Post("/delivery", h.SetDelivery)
+
+		r.Post("/recover", h.RecoverCart)
+		r.Post("/reminders", h.SetReminders)
+
+		r.Post("/share", h.ShareCart)
+		r.Post("/registry", h.CreateRegistry)
+		r.Get("/shares", h.ListShares)
+		r.Get("/shared/{id}", h.GetSharedCart)
+		r.Post("/shared/{id}/import", h.ImportSharedCart)
+		r.Post("/shared/{id}/items", h.UpdateRegistryItem)
+		r.Post("/shared/{id}/close", h.CloseShare)
 	})
+
+	r.Route("/v1/admin/promotions", func(r chi.Router) {
+		r.Use(AdminOnly)
+		r.Get("/", h.ListPromotions)
+		r.Post("/", h.CreatePromotion)
+		r.Put("/{id}", h.UpdatePromotion)
+		r.Delete("/{id}", h.DeletePromotion)
+	})
+
+	r.Route("/v1/admin/cart-rules", func(r chi.Router) {
+		r.Use(AdminOnly)
+		r.Get("/", h.ListCartRules)
+		r.Post("/", h.CreateCartRule)
+		r.Put("/{id}", h.UpdateCartRule)
+		r.Delete("/{id}", h.DeleteCartRule)
+	})
+
+	r.With(AdminOnly).Get("/v1/admin/carts/abandoned", h.ListAbandonedCarts)
+
+	r.With(InternalOnly).Post("/internal/promotions/redeem", h.RedeemPromotions)
+	r.With(InternalOnly).Post("/internal/promotions/release", h.ReleasePromotions)
+	r.With(InternalOnly).Post("/internal/registries/purchases", h.RecordRegistryPurchases)
+	r.With(InternalOnly).Post("/internal/carts/converted", h.MarkCartConverted)
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		w.Write([]byte("ok"))
@@ -0,0 +1,377 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func activeRules(rules []model.CartRule, ruleType string) []model.CartRule {
+	var out []model.CartRule
+	for _, r := range rules {
+		if r.Active && r.Type == ruleType {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func ruleMessage(r model.CartRule, format string, args ...any) string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// lineCap returns the most of a product the cart's line may hold, stock and
+// purchase rules together (-1 for no cap), and the rule when it is the rule
+// that binds.
+func (h *Handler) lineCap(ctx context.Context, key, productID string, product map[string]any) (int, *model.RuleViolation, error) {
+	maxQty := productStock(product)
+	limit, rule, err := h.lineLimit(ctx, key, productID, product)
+	if err != nil {
+		return -1, nil, err
+	}
+	if rule != nil && (maxQty < 0 || limit < maxQty) {
+		return limit, rule, nil
+	}
+	return maxQty, nil, nil
+}
+
+// lineLimit returns the most of productID the cart's line may hold under
+// the active rules, with the rule that sets it, or -1 and nil when no rule
+// applies. product may be nil; it is loaded only if a category rule needs
+// its category.
+func (h *Handler) lineLimit(ctx context.Context, key, productID string, product map[string]any) (int, *model.RuleViolation, error) {
+	rules, err := h.store.ListCartRules(ctx)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	limit := -1
+	var binding *model.RuleViolation
+	tighten := func(n int, v model.RuleViolation) {
+		n = max(n, 0)
+		if limit < 0 || n < limit {
+			limit = n
+			v.Allowed = n
+			binding = &v
+		}
+	}
+
+	for _, r := range activeRules(rules, model.RuleMaxQuantity) {
+		if r.ProductID == productID {
+			tighten(r.Max, model.RuleViolation{
+				Code:      model.ViolationProductLimit,
+				RuleID:    r.ID,
+				Message:   ruleMessage(r, "you can buy at most %d of this item per order", r.Max),
+				ProductID: productID,
+				Limit:     r.Max,
+			})
+		}
+	}
+
+	if catRules := categoryRules(rules); len(catRules) > 0 {
+		if product == nil {
+			products, err := h.pclient.GetProducts(ctx, []string{productID})
+			if err != nil {
+				return -1, nil, err
+			}
+			product = products[productID]
+		}
+		category, _ := product["category"].(string)
+		if r, ok := catRules[category]; ok && category != "" {
+			others, err := h.categoryQuantity(ctx, key, category, productID)
+			if err != nil {
+				return -1, nil, err
+			}
+			tighten(r.Max-others, model.RuleViolation{
+				Code:      model.ViolationCategoryLimit,
+				RuleID:    r.ID,
+				Message:   ruleMessage(r, "you can buy at most %d items from %s per order", r.Max, category),
+				ProductID: productID,
+				Category:  category,
+				Limit:     r.Max,
+			})
+		}
+	}
+
+	for _, r := range activeRules(rules, model.RuleLifetimeLimit) {
+		if r.ProductID != productID {
+			continue
+		}
+		uid := requestUserID(ctx)
+		if uid == "" {
+			tighten(0, model.RuleViolation{
+				Code:      model.ViolationLoginRequired,
+				RuleID:    r.ID,
+				Message:   "sign in to buy this limited item",
+				ProductID: productID,
+				Limit:     r.Max,
+			})
+			continue
+		}
+		bought, err := h.orders.PurchasedQuantities(ctx, uid, []string{productID})
+		if err != nil {
+			return -1, nil, err
+		}
+		tighten(r.Max-bought[productID], model.RuleViolation{
+			Code:      model.ViolationLifetimeLimit,
+			RuleID:    r.ID,
+			Message:   ruleMessage(r, "you can buy at most %d of this item in total", r.Max),
+			ProductID: productID,
+			Limit:     r.Max,
+		})
+	}
+
+	return limit, binding, nil
+}
+
+// categoryRules returns the strictest active category limit per category.
+func categoryRules(rules []model.CartRule) map[string]model.CartRule {
+	out := map[string]model.CartRule{}
+	for _, r := range activeRules(rules, model.RuleMaxQuantity) {
+		if r.Category == "" {
+			continue
+		}
+		if cur, ok := out[r.Category]; !ok || r.Max < cur.Max {
+			out[r.Category] = r
+		}
+	}
+	return out
+}
+
+// categoryQuantity counts the cart's units in category, leaving out
+// excludeID.
+func (h *Handler) categoryQuantity(ctx context.Context, key, category, excludeID string) (int, error) {
+	items, err := h.store.GetAll(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for _, it := range items {
+		if it.ProductID != excludeID {
+			ids = append(ids, it.ProductID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	products, err := h.pclient.GetProducts(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, it := range items {
+		if p, ok := products[it.ProductID]; ok && it.ProductID != excludeID {
+			if c, _ := p["category"].(string); c == category {
+				total += it.Quantity
+			}
+		}
+	}
+	return total, nil
+}
+
+// applyRules lists the rules a hydrated cart breaks. Lifetime limits need
+// the orders service; if it cannot be reached the cart is marked
+// unverified rather than let through.
+func (h *Handler) applyRules(ctx context.Context, resp *model.CartResponse, lines []cartLine) {
+	resp.Violations = []model.RuleViolation{}
+
+	rules, err := h.store.ListCartRules(ctx)
+	if err != nil {
+		log.Println("cart rules:", err)
+		return
+	}
+	if len(rules) == 0 || len(lines) == 0 {
+		return
+	}
+
+	qty := map[string]int{}
+	catQty := map[string]int{}
+	for _, l := range lines {
+		qty[l.ProductID] += l.Quantity
+		if l.Category != "" {
+			catQty[l.Category] += l.Quantity
+		}
+	}
+
+	for _, r := range activeRules(rules, model.RuleMaxQuantity) {
+		if r.ProductID != "" && qty[r.ProductID] > r.Max {
+			resp.Violations = append(resp.Violations, model.RuleViolation{
+				Code:      model.ViolationProductLimit,
+				RuleID:    r.ID,
+				Message:   ruleMessage(r, "you can buy at most %d of this item per order", r.Max),
+				ProductID: r.ProductID,
+				Limit:     r.Max,
+				Allowed:   r.Max,
+			})
+		}
+		if r.Category != "" && catQty[r.Category] > r.Max {
+			resp.Violations = append(resp.Violations, model.RuleViolation{
+				Code:     model.ViolationCategoryLimit,
+				RuleID:   r.ID,
+				Message:  ruleMessage(r, "you can buy at most %d items from %s per order", r.Max, r.Category),
+				Category: r.Category,
+				Limit:    r.Max,
+				Allowed:  r.Max,
+			})
+		}
+	}
+
+	var lifetime []model.CartRule
+	var ids []string
+	for _, r := range activeRules(rules, model.RuleLifetimeLimit) {
+		if qty[r.ProductID] > 0 {
+			lifetime = append(lifetime, r)
+			ids = append(ids, r.ProductID)
+		}
+	}
+	if len(lifetime) > 0 {
+		uid := requestUserID(ctx)
+		var bought map[string]int
+		if uid != "" {
+			bought, err = h.orders.PurchasedQuantities(ctx, uid, ids)
+		}
+		switch {
+		case uid == "":
+			for _, r := range lifetime {
+				resp.Violations = append(resp.Violations, model.RuleViolation{
+					Code:      model.ViolationLoginRequired,
+					RuleID:    r.ID,
+					Message:   "sign in to buy this limited item",
+					ProductID: r.ProductID,
+					Limit:     r.Max,
+				})
+			}
+		case err != nil:
+			log.Println("cart lifetime limits:", err)
+			resp.Violations = append(resp.Violations, model.RuleViolation{
+				Code:    model.ViolationLimitsUnverified,
+				Message: "we could not check purchase limits right now; please try again shortly",
+			})
+		default:
+			for _, r := range lifetime {
+				if qty[r.ProductID]+bought[r.ProductID] > r.Max {
+					resp.Violations = append(resp.Violations, model.RuleViolation{
+						Code:      model.ViolationLifetimeLimit,
+						RuleID:    r.ID,
+						Message:   ruleMessage(r, "you can buy at most %d of this item in total", r.Max),
+						ProductID: r.ProductID,
+						Limit:     r.Max,
+						Allowed:   max(r.Max-bought[r.ProductID], 0),
+					})
+				}
+			}
+		}
+	}
+
+	for _, r := range activeRules(rules, model.RuleMinOrderValue) {
+		if resp.Total < r.MinValue {
+			resp.Violations = append(resp.Violations, model.RuleViolation{
+				Code:     model.ViolationMinOrderValue,
+				RuleID:   r.ID,
+				Message:  ruleMessage(r, "add ₹%.2f more to check out", float64(r.MinValue-resp.Total)/100),
+				MinValue: r.MinValue,
+			})
+		}
+	}
+}
+
+// writeViolation refuses a cart change that would break a rule.
+func writeViolation(w http.ResponseWriter, v *model.RuleViolation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+		*model.RuleViolation
+	}{v.Message, v})
+}
+
+// --- ADMIN ---
+
+func (h *Handler) ListCartRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.store.ListCartRules(r.Context())
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"rules": rules})
+}
+
+func (h *Handler) CreateCartRule(w http.ResponseWriter, r *http.Request) {
+	h.saveCartRule(w, r, "", true)
+}
+
+func (h *Handler) UpdateCartRule(w http.ResponseWriter, r *http.Request) {
+	h.saveCartRule(w, r, chi.URLParam(r, "id"), false)
+}
+
+func (h *Handler) saveCartRule(w http.ResponseWriter, r *http.Request, id string, create bool) {
+	var rule model.CartRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if !create {
+		rule.ID = id
+	}
+	if err := rule.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.store.SaveCartRule(r.Context(), &rule, create)
+	switch {
+	case errors.Is(err, store.ErrRuleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrRuleExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if h.pg != nil {
+		if err := h.pg.SaveCartRule(r.Context(), &rule); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if create {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(rule)
+}
+
+func (h *Handler) DeleteCartRule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := h.store.DeleteCartRule(r.Context(), id)
+	if errors.Is(err, store.ErrRuleNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	if h.pg != nil {
+		if err := h.pg.DeleteCartRule(r.Context(), id); err != nil {
+			http.Error(w, "db error", http.StatusInternalServerError)
+			return
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
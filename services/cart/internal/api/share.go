@@ -0,0 +1,520 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxShareTitle   = 120
+	maxShareMessage = 1000
+)
+
+type shareRequest struct {
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	EventDate string `json:"event_date"`
+}
+
+// validate trims the request and parses its event date (YYYY-MM-DD).
+func (req *shareRequest) validate() (*time.Time, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Message = strings.TrimSpace(req.Message)
+	if utf8.RuneCountInString(req.Title) > maxShareTitle {
+		return nil, errors.New("title is too long")
+	}
+	if utf8.RuneCountInString(req.Message) > maxShareMessage {
+		return nil, errors.New("message is too long")
+	}
+	if req.EventDate == "" {
+		return nil, nil
+	}
+	d, err := time.Parse("2006-01-02", req.EventDate)
+	if err != nil {
+		return nil, errors.New("event_date must be YYYY-MM-DD")
+	}
+	return &d, nil
+}
+
+// isShareOwner reports whether the request comes from the cart a share was
+// made from, or from the signed-in shopper who made it.
+func isShareOwner(ctx context.Context, share *model.SharedCart) bool {
+	if key := resolveKey(ctx); key != "" && key == share.OwnerKey {
+		return true
+	}
+	uid := requestUserID(ctx)
+	return uid != "" && share.OwnerID != nil && *share.OwnerID == uid
+}
+
+// remaining is how many of a shared line are still wanted: all of it for a
+// snapshot, what has not been bought yet for a registry.
+func remaining(share *model.SharedCart, it model.SharedItem) int {
+	if share.Kind != model.ShareRegistry {
+		return it.Quantity
+	}
+	return max(it.Quantity-it.Purchased, 0)
+}
+
+// createShare publishes the request's cart as a share of the given kind.
+func (h *Handler) createShare(w http.ResponseWriter, r *http.Request, kind string) {
+	if h.pg == nil {
+		http.Error(w, "cart sharing is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	var req shareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	eventDate, err := req.validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	share := &model.SharedCart{
+		Kind:      kind,
+		OwnerKey:  key,
+		Title:     req.Title,
+		Message:   req.Message,
+		EventDate: eventDate,
+		Items:     []model.SharedItem{},
+	}
+	if uid := requestUserID(ctx); uid != "" {
+		share.OwnerID = &uid
+	}
+	if kind == model.ShareRegistry {
+		if share.OwnerID == nil {
+			http.Error(w, "sign in to create a registry", http.StatusUnauthorized)
+			return
+		}
+		if share.Title == "" {
+			http.Error(w, "title is required", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// share the cart as the shopper sees it, not lines that have gone
+	_, lines, err := h.buildCart(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	for _, l := range lines {
+		share.Items = append(share.Items, model.SharedItem{
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			UnitPrice: l.UnitPrice,
+		})
+	}
+	if kind == model.ShareSnapshot && len(share.Items) == 0 {
+		http.Error(w, "cart is empty", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.pg.CreateShare(ctx, share); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(share)
+}
+
+// ShareCart expects: { "title": "...", "message": "..." } (both optional)
+// and snapshots the cart into a read-only link.
+func (h *Handler) ShareCart(w http.ResponseWriter, r *http.Request) {
+	h.createShare(w, r, model.ShareSnapshot)
+}
+
+// CreateRegistry expects: { "title": "...", "message": "...",
+// "event_date": "2026-12-01" } from a signed-in shopper and starts a gift
+// registry from the cart's lines. Guests buy from it by importing it.
+func (h *Handler) CreateRegistry(w http.ResponseWriter, r *http.Request) {
+	h.createShare(w, r, model.ShareRegistry)
+}
+
+// ListShares returns the shares and registries made from the current cart.
+func (h *Handler) ListShares(w http.ResponseWriter, r *http.Request) {
+	if h.pg == nil {
+		http.Error(w, "cart sharing is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	shares, err := h.pg.ListShares(ctx, key)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"shares": shares})
+}
+
+// loadShare fetches the share named in the URL, writing the error response
+// if it cannot.
+func (h *Handler) loadShare(w http.ResponseWriter, r *http.Request) (*model.SharedCart, bool) {
+	if h.pg == nil {
+		http.Error(w, "cart sharing is not enabled", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	share, err := h.pg.GetShare(r.Context(), chi.URLParam(r, "id"))
+	if errors.Is(err, store.ErrShareNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return share, true
+}
+
+// GetSharedCart shows a shared cart read-only, at today's prices. For a
+// registry, Remaining is what guests can still buy.
+func (h *Handler) GetSharedCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+
+	ids := make([]string, 0, len(share.Items))
+	for _, it := range share.Items {
+		ids = append(ids, it.ProductID)
+	}
+	products, _ := h.pclient.HydrateProducts(ctx, ids)
+
+	resp := model.SharedCartResponse{
+		ID:        share.ID,
+		Kind:      share.Kind,
+		Title:     share.Title,
+		Message:   share.Message,
+		EventDate: share.EventDate,
+		Items:     []model.HydratedSharedItem{},
+		Currency:  "INR",
+		ExpiresAt: share.ExpiresAt,
+		Closed:    share.ClosedAt != nil,
+		IsOwner:   isShareOwner(ctx, share),
+		CreatedAt: share.CreatedAt,
+	}
+	for _, it := range share.Items {
+		line := model.HydratedSharedItem{
+			Product:   map[string]any{"id": it.ProductID},
+			Quantity:  it.Quantity,
+			Purchased: it.Purchased,
+			Remaining: remaining(share, it),
+			UnitPrice: it.UnitPrice,
+		}
+
+		productRaw, ok := products[it.ProductID]
+		pub, hasPub := productRaw["published"].(bool)
+		if !ok || (hasPub && !pub) {
+			line.Unavailable = true
+			resp.Items = append(resp.Items, line)
+			continue
+		}
+
+		image, _ := productRaw["image_url"].(string)
+		line.Product = map[string]any{
+			"id":    productRaw["id"],
+			"title": productRaw["title"],
+			"slug":  productRaw["slug"],
+			"image": image,
+		}
+		line.UnitPrice = asMoney(productRaw["price"])
+		line.LineTotal = line.UnitPrice * int64(line.Remaining)
+		line.InStock = productStock(productRaw) != 0
+
+		resp.Items = append(resp.Items, line)
+		resp.Subtotal += line.LineTotal
+		resp.ItemCount += line.Remaining
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// ImportSharedCart copies a shared cart into the viewer's cart; the body
+// may limit it to { "product_ids": [...] }. Snapshot lines are added at
+// their shared quantity and registry lines at what is still wanted, marked
+// as the registry's units so only those count towards it. Each line is capped by
+// stock and purchase rules; the response says what was added.
+func (h *Handler) ImportSharedCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ProductIDs []string `json:"product_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	if share.ClosedAt != nil {
+		http.Error(w, "this list has been closed", http.StatusGone)
+		return
+	}
+	registryID := ""
+	if share.Kind == model.ShareRegistry {
+		if isShareOwner(ctx, share) {
+			http.Error(w, "you cannot buy gifts from your own registry", http.StatusUnprocessableEntity)
+			return
+		}
+		registryID = share.ID
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range req.ProductIDs {
+		wanted[id] = true
+	}
+	var items []model.SharedItem
+	ids := []string{}
+	for _, it := range share.Items {
+		if len(wanted) == 0 || wanted[it.ProductID] {
+			items = append(items, it)
+			ids = append(ids, it.ProductID)
+		}
+	}
+
+	products, err := h.pclient.GetProducts(ctx, ids)
+	if err != nil {
+		http.Error(w, "product validation failed", http.StatusBadGateway)
+		return
+	}
+	current, err := h.store.GetAll(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	inCart := map[string]int{}
+	for _, it := range current {
+		inCart[it.ProductID] = it.Quantity
+	}
+
+	report := []model.ImportLine{}
+	for _, it := range items {
+		line := model.ImportLine{ProductID: it.ProductID, Requested: remaining(share, it)}
+		report = append(report, h.importLine(ctx, key, registryID, line, products[it.ProductID], inCart[it.ProductID]))
+	}
+
+	resp, lines, err := h.buildCart(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	h.applyTotals(ctx, key, resp, lines)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"lines": report,
+		"cart":  resp,
+	})
+}
+
+// importLine adds as much of one shared line as stock and purchase rules
+// allow on top of the inCart units already there.
+func (h *Handler) importLine(ctx context.Context, key, registryID string, line model.ImportLine, product map[string]any, inCart int) model.ImportLine {
+	if line.Requested <= 0 {
+		line.Result = model.ImportSkipped
+		line.Reason = "already bought"
+		return line
+	}
+	if product == nil {
+		line.Result = model.ImportUnavailable
+		line.Reason = "product not found"
+		return line
+	}
+	if pub, ok := product["published"].(bool); ok && !pub {
+		line.Result = model.ImportUnavailable
+		line.Reason = "product not available"
+		return line
+	}
+
+	allowed := line.Requested
+	line.Reason = "insufficient stock"
+	if stock := productStock(product); stock >= 0 && stock-inCart < allowed {
+		allowed = stock - inCart
+	}
+	limit, rule, err := h.lineLimit(ctx, key, line.ProductID, product)
+	if err != nil {
+		log.Println("cart import limits:", err)
+		line.Result = model.ImportSkipped
+		line.Reason = "could not check purchase limits"
+		return line
+	}
+	if rule != nil && limit-inCart < allowed {
+		allowed = limit - inCart
+		line.Reason = rule.Message
+	}
+	if allowed <= 0 {
+		line.Result = model.ImportSkipped
+		return line
+	}
+
+	item := model.CartItem{
+		ProductID: line.ProductID,
+		Quantity:  allowed,
+		UnitPrice: asMoney(product["price"]),
+		Currency:  "INR",
+	}
+	if registryID != "" {
+		item.RegistryID = registryID
+		item.RegistryQuantity = allowed
+	}
+	_, err = h.store.AddItem(ctx, key, item, inCart+allowed)
+	if errors.Is(err, store.ErrOtherRegistry) {
+		line.Result = model.ImportSkipped
+		line.Reason = "already in your cart for another registry; check it out first"
+		return line
+	}
+	if err != nil {
+		// the cart changed underneath us; leave the line for a retry
+		line.Result = model.ImportSkipped
+		line.Reason = "cart changed, please try again"
+		return line
+	}
+
+	line.Added = allowed
+	line.Result = model.ImportAdded
+	if allowed < line.Requested {
+		line.Result = model.ImportReduced
+	} else {
+		line.Reason = ""
+	}
+	return line
+}
+
+// UpdateRegistryItem expects: { "product_id": "...", "quantity": 2 } from
+// the registry's owner; quantity 0 takes the product off the registry.
+func (h *Handler) UpdateRegistryItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	if !isShareOwner(ctx, share) {
+		http.Error(w, "only the owner can change this registry", http.StatusForbidden)
+		return
+	}
+	if share.Kind != model.ShareRegistry {
+		http.Error(w, "shared carts cannot be changed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	req, ok := decodeProductRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.Quantity < 0 {
+		http.Error(w, "quantity must be >= 0", http.StatusBadRequest)
+		return
+	}
+
+	var price int64
+	if req.Quantity > 0 {
+		product, ok := h.lookupProduct(w, r, req.ProductID)
+		if !ok {
+			return
+		}
+		price = asMoney(product["price"])
+	}
+	if err := h.pg.SetShareItem(ctx, share.ID, req.ProductID, req.Quantity, price); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+
+	share, err := h.pg.GetShare(ctx, share.ID)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(share)
+}
+
+// CloseShare stops a shared cart or registry from being imported. It can
+// still be viewed until it expires.
+func (h *Handler) CloseShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	share, ok := h.loadShare(w, r)
+	if !ok {
+		return
+	}
+	if !isShareOwner(ctx, share) {
+		http.Error(w, "only the owner can close this list", http.StatusForbidden)
+		return
+	}
+	if err := h.pg.CloseShare(ctx, share.ID); err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "closed"})
+}
+
+// RecordRegistryPurchases is called by the orders service once an order is
+// paid: { "order_id": "...", "user_id": "...", "items": [{ "registry_id":
+// "...", "product_id": "...", "quantity": 1 }] }. Repeat calls for the same
+// order are ignored.
+func (h *Handler) RecordRegistryPurchases(w http.ResponseWriter, r *http.Request) {
+	if h.pg == nil {
+		http.Error(w, "cart sharing is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		OrderID string                   `json:"order_id"`
+		UserID  *string                  `json:"user_id"`
+		Items   []model.RegistryPurchase `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OrderID == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	for _, it := range req.Items {
+		if it.RegistryID == "" || it.ProductID == "" || it.Quantity <= 0 {
+			http.Error(w, "invalid registry item", http.StatusBadRequest)
+			return
+		}
+	}
+
+	n, err := h.pg.RecordRegistryPurchases(r.Context(), req.OrderID, req.UserID, req.Items)
+	if err != nil {
+		http.Error(w, "db error", http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]int{"recorded": n})
+}
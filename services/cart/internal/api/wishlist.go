@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+)
+
+type productRequest struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+func decodeProductRequest(w http.ResponseWriter, r *http.Request) (*productRequest, bool) {
+	var req productRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ProductID == "" {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// GetWishlist returns the saved products, newest first, hydrated like the
+// cart. Deleted products are dropped; unpublished ones and ones that could
+// not be loaded stay saved but are marked unavailable.
+func (h *Handler) GetWishlist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.store.GetWishlist(ctx, key)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].AddedAt.After(saved[j].AddedAt) })
+
+	ids := make([]string, 0, len(saved))
+	for _, it := range saved {
+		ids = append(ids, it.ProductID)
+	}
+	products, missing := h.pclient.HydrateProducts(ctx, ids)
+
+	resp := model.WishlistResponse{
+		Items:    []model.HydratedWishlistItem{},
+		Currency: "INR",
+	}
+	for _, it := range saved {
+		productRaw, ok := products[it.ProductID]
+		if !ok && missing[it.ProductID] {
+			_ = h.store.RemoveWishlist(ctx, key, it.ProductID)
+			continue
+		}
+
+		item := model.HydratedWishlistItem{
+			Product:    map[string]any{"id": it.ProductID},
+			UnitPrice:  it.UnitPrice,
+			SavedPrice: it.UnitPrice,
+			AddedAt:    it.AddedAt,
+		}
+		if !ok {
+			item.Unavailable = true
+		} else {
+			image, _ := productRaw["image_url"].(string)
+			item.Product = map[string]any{
+				"id":    productRaw["id"],
+				"title": productRaw["title"],
+				"slug":  productRaw["slug"],
+				"image": image,
+			}
+			if v, ok := productRaw["price"]; ok {
+				item.UnitPrice = asMoney(v)
+			}
+			item.InStock = productStock(productRaw) != 0
+			if pub, ok := productRaw["published"].(bool); ok && !pub {
+				item.Unavailable = true
+				item.InStock = false
+			}
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	resp.Count = len(resp.Items)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// AddToWishlist expects: { "product_id": "..." }
+func (h *Handler) AddToWishlist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeProductRequest(w, r)
+	if !ok {
+		return
+	}
+	product, ok := h.lookupProduct(w, r, req.ProductID)
+	if !ok {
+		return
+	}
+
+	item := model.WishlistItem{
+		ProductID: req.ProductID,
+		UnitPrice: asMoney(product["price"]),
+		AddedAt:   time.Now().UTC(),
+	}
+	added, err := h.store.AddWishlist(ctx, key, item)
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	status := "saved"
+	if !added {
+		status = "already_saved"
+	}
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+func (h *Handler) RemoveFromWishlist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeProductRequest(w, r)
+	if !ok {
+		return
+	}
+	err := h.store.RemoveWishlist(ctx, key, req.ProductID)
+	if err != nil && !errors.Is(err, store.ErrItemNotFound) {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// MoveToCart expects: { "product_id": "...", "quantity": 1 }. Quantity
+// defaults to 1 and is added to any quantity already in the cart.
+func (h *Handler) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeProductRequest(w, r)
+	if !ok {
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		http.Error(w, "quantity must be > 0", http.StatusBadRequest)
+		return
+	}
+
+	product, ok := h.lookupProduct(w, r, req.ProductID)
+	if !ok {
+		return
+	}
+
+	// the same caps as AddItem, so saving first can't get round them
+	maxQty, rule, err := h.lineCap(ctx, key, req.ProductID, product)
+	if err != nil {
+		http.Error(w, "could not check purchase limits", http.StatusBadGateway)
+		return
+	}
+
+	item, err := h.store.MoveToCart(ctx, key, model.CartItem{
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		UnitPrice: asMoney(product["price"]),
+		Currency:  "INR",
+	}, maxQty)
+	switch {
+	case errors.Is(err, store.ErrItemNotFound):
+		http.Error(w, "item not in wishlist", http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrInsufficientStock) && rule != nil:
+		writeViolation(w, rule)
+		return
+	case errors.Is(err, store.ErrInsufficientStock):
+		http.Error(w, "insufficient stock", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(item)
+}
+
+// MoveToWishlist expects: { "product_id": "..." } for a line in the cart.
+func (h *Handler) MoveToWishlist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := resolveKey(ctx)
+	if key == "" {
+		http.Error(w, "no user/session", http.StatusBadRequest)
+		return
+	}
+
+	req, ok := decodeProductRequest(w, r)
+	if !ok {
+		return
+	}
+	err := h.store.MoveToWishlist(ctx, key, req.ProductID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		http.Error(w, "item not in cart", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "redis error", http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "moved"})
+}
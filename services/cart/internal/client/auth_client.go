@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidToken means Supabase rejected the bearer token.
+var ErrInvalidToken = errors.New("invalid token")
+
+// maxTokenCache bounds how long a verified token is trusted without asking
+// Supabase again; a token is never trusted past its own expiry.
+const maxTokenCache = 5 * time.Minute
+
+type verifiedToken struct {
+	userID  string
+	expires time.Time
+}
+
+// AuthClient verifies Supabase access tokens the same way the reviews
+// service does, by asking Supabase who the token belongs to.
+type AuthClient struct {
+	base    string
+	anonKey string
+	c       *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]verifiedToken
+}
+
+func NewAuthClientFromEnv() *AuthClient {
+	return &AuthClient{
+		base:    strings.TrimRight(os.Getenv("SUPABASE_URL"), "/"),
+		anonKey: os.Getenv("SUPABASE_ANON_KEY"),
+		c:       &http.Client{Timeout: 5 * time.Second},
+		tokens:  map[string]verifiedToken{},
+	}
+}
+
+// VerifyToken returns the user ID a bearer token was issued to.
+func (a *AuthClient) VerifyToken(ctx context.Context, token string) (string, error) {
+	sum := sha256.Sum256([]byte(token))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	now := time.Now()
+	a.mu.Lock()
+	if v, ok := a.tokens[cacheKey]; ok && now.Before(v.expires) {
+		a.mu.Unlock()
+		return v.userID, nil
+	}
+	a.mu.Unlock()
+
+	if a.base == "" {
+		return "", fmt.Errorf("SUPABASE_URL not configured")
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", a.base+"/auth/v1/user", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("apikey", a.anonKey)
+
+	resp, err := a.c.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", ErrInvalidToken
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("supabase returned %d", resp.StatusCode)
+	}
+
+	var body struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.ID == "" {
+		return "", ErrInvalidToken
+	}
+
+	expires := now.Add(maxTokenCache)
+	if exp, ok := tokenExpiry(token); ok && exp.Before(expires) {
+		expires = exp
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.tokens) >= maxCacheEntries {
+		for k, v := range a.tokens {
+			if !now.Before(v.expires) {
+				delete(a.tokens, k)
+			}
+		}
+	}
+	a.tokens[cacheKey] = verifiedToken{userID: body.ID, expires: expires}
+
+	return body.ID, nil
+}
+
+// tokenExpiry reads the exp claim of a JWT without checking its signature;
+// Supabase has already vouched for the token.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
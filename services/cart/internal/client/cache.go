@@ -0,0 +1,70 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// staleFactor is how many TTLs past expiry an entry may still be served
+// when the product service is failing.
+const staleFactor = 20
+
+// maxCacheEntries triggers a sweep of entries too old even to serve stale.
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	// product is nil for IDs the product service reported missing.
+	product map[string]any
+	fetched time.Time
+}
+
+// productCache is a small in-process TTL cache of product lookups.
+type productCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newProductCache(ttl time.Duration) *productCache {
+	return &productCache{ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// get returns a fresh entry, or with stale set one up to staleFactor TTLs
+// old. A zero TTL disables the cache.
+func (c *productCache) get(id string, stale bool) (cacheEntry, bool) {
+	if c.ttl <= 0 {
+		return cacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	maxAge := c.ttl
+	if stale {
+		maxAge *= staleFactor
+	}
+	if time.Since(e.fetched) > maxAge {
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+func (c *productCache) put(id string, product map[string]any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if time.Since(e.fetched) > c.ttl*staleFactor {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[id] = cacheEntry{product: product, fetched: time.Now()}
+}
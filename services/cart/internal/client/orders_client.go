@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+type OrdersClient struct {
+	base string
+	c    *http.Client
+}
+
+func NewOrdersClientFromEnv() *OrdersClient {
+	base := os.Getenv("ORDERS_SVC_BASE")
+	if base == "" {
+		base = "http://localhost:8082"
+	}
+	return &OrdersClient{base: base, c: &http.Client{Timeout: 3 * time.Second}}
+}
+
+// PurchasedQuantities returns how many of each product the user has
+// ordered, for lifetime purchase limits. Products never ordered are absent.
+func (o *OrdersClient) PurchasedQuantities(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	u := o.base + "/v1/orders/internal/purchased-quantities?product_ids=" +
+		url.QueryEscape(strings.Join(productIDs, ","))
+	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
+	req.Header.Set("X-USER-ID", userID)
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := o.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("orders service returned %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Quantities map[string]int `json:"quantities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out.Quantities, nil
+}
@@ -1,17 +1,28 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 )
 
+const (
+	// batchSize matches the product service's per-request limit.
+	batchSize        = 100
+	batchConcurrency = 4
+	batchTimeout     = 2 * time.Second
+)
+
 type ProductClient struct {
-	base string
-	c    *http.Client
+	base  string
+	c     *http.Client
+	cache *productCache
 }
 
 func NewProductClientFromEnv() *ProductClient {
@@ -19,16 +30,121 @@ func NewProductClientFromEnv() *ProductClient {
 	if base == "" {
 		base = "http://localhost:8080"
 	}
+
+	// Product data is cached briefly: long enough to absorb a burst of cart
+	// reads, short enough that price and stock stay close to live. Checkout
+	// re-checks both against the product service anyway.
+	ttlSeconds := 15
+	if v := os.Getenv("CART_PRODUCT_CACHE_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &ttlSeconds)
+	}
+
 	return &ProductClient{
-		base: base,
-		c:    &http.Client{Timeout: 5 * time.Second},
+		base:  base,
+		c:     &http.Client{Timeout: 5 * time.Second},
+		cache: newProductCache(time.Duration(ttlSeconds) * time.Second),
+	}
+}
+
+// GetProducts returns products keyed by ID, from the cache where fresh and
+// from POST /v1/products/batch otherwise. IDs the product service does not
+// know (or has deleted) are absent from the map. Any failed lookup fails the
+// call.
+func (p *ProductClient) GetProducts(ctx context.Context, productIDs []string) (map[string]map[string]any, error) {
+	found, _, err := p.lookup(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// HydrateProducts is GetProducts for rendering a cart: it never fails.
+// Products the service reported gone are in missing; IDs in neither map
+// could not be loaded (and had no stale cache entry) and should be shown as
+// unavailable rather than dropped.
+func (p *ProductClient) HydrateProducts(ctx context.Context, productIDs []string) (found map[string]map[string]any, missing map[string]bool) {
+	found, missing, err := p.lookup(ctx, productIDs)
+	if err != nil {
+		log.Println("product hydration:", err)
 	}
+	return found, missing
 }
 
-// GetProductDetail calls product service GET /v1/products/{id}
-func (p *ProductClient) GetProductDetail(ctx context.Context, productID string) (map[string]any, error) {
-	url := fmt.Sprintf("%s/v1/products/%s", p.base, productID)
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+// lookup serves fresh cache entries and fetches the rest in batches of
+// batchSize, at most batchConcurrency at a time, each bounded by
+// batchTimeout. When a batch fails its IDs fall back to stale entries.
+func (p *ProductClient) lookup(ctx context.Context, productIDs []string) (map[string]map[string]any, map[string]bool, error) {
+	found := map[string]map[string]any{}
+	missing := map[string]bool{}
+
+	var todo []string
+	for _, id := range productIDs {
+		if e, ok := p.cache.get(id, false); ok {
+			if e.product == nil {
+				missing[id] = true
+			} else {
+				found[id] = e.product
+			}
+			continue
+		}
+		todo = append(todo, id)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		sem      = make(chan struct{}, batchConcurrency)
+	)
+	for start := 0; start < len(todo); start += batchSize {
+		chunk := todo[start:min(start+batchSize, len(todo))]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			bctx, cancel := context.WithTimeout(ctx, batchTimeout)
+			defer cancel()
+			products, err := p.fetchBatch(bctx, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range chunk {
+				if err == nil {
+					product := products[id]
+					p.cache.put(id, product)
+					if product == nil {
+						missing[id] = true
+					} else {
+						found[id] = product
+					}
+				} else if e, ok := p.cache.get(id, true); ok {
+					if e.product == nil {
+						missing[id] = true
+					} else {
+						found[id] = e.product
+					}
+				}
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	return found, missing, firstErr
+}
+
+// fetchBatch calls product service POST /v1/products/batch.
+func (p *ProductClient) fetchBatch(ctx context.Context, productIDs []string) (map[string]map[string]any, error) {
+	b, _ := json.Marshal(map[string][]string{"ids": productIDs})
+	req, _ := http.NewRequestWithContext(ctx, "POST", p.base+"/v1/products/batch", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	// unpublished products are only listed for internal callers
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
 	resp, err := p.c.Do(req)
 	if err != nil {
 		return nil, err
@@ -37,9 +153,12 @@ func (p *ProductClient) GetProductDetail(ctx context.Context, productID string)
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("product service returned %d", resp.StatusCode)
 	}
-	var out map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+
+	var body struct {
+		Products map[string]map[string]any `json:"products"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return body.Products, nil
 }
@@ -0,0 +1,59 @@
+package model
+
+// Supply types decide how GST is split.
+const (
+	SupplyIntraState = "intra_state" // CGST + SGST
+	SupplyInterState = "inter_state" // IGST
+)
+
+// Tax components.
+const (
+	TaxCGST = "CGST"
+	TaxSGST = "SGST"
+	TaxIGST = "IGST"
+)
+
+// Charge types.
+const (
+	ChargeShipping     = "shipping"
+	ChargeCODSurcharge = "cod_surcharge"
+)
+
+// DeliveryOptions is what the shopper told us about delivery so far.
+type DeliveryOptions struct {
+	Pincode string `json:"pincode,omitempty"`
+	COD     bool   `json:"cod"`
+}
+
+// TaxLine is the GST included in one cart line after its share of
+// discounts. Rate is a percentage.
+type TaxLine struct {
+	ProductID    string  `json:"product_id"`
+	HSNCode      string  `json:"hsn_code"`
+	Rate         float64 `json:"rate"`
+	TaxableValue int64   `json:"taxable_value"`
+	Tax          int64   `json:"tax"`
+}
+
+type TaxComponent struct {
+	Type   string `json:"type"`
+	Amount int64  `json:"amount"`
+}
+
+// TaxBreakdown explains the GST already included in the cart's prices.
+// SupplyType and Components stay empty until a pincode is known.
+type TaxBreakdown struct {
+	Inclusive  bool           `json:"inclusive"`
+	Lines      []TaxLine      `json:"lines"`
+	Total      int64          `json:"total"`
+	SupplyType string         `json:"supply_type,omitempty"`
+	State      string         `json:"state,omitempty"`
+	Components []TaxComponent `json:"components,omitempty"`
+}
+
+// ChargeLine is an amount added on top of the cart total.
+type ChargeLine struct {
+	Type   string `json:"type"`
+	Label  string `json:"label"`
+	Amount int64  `json:"amount"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Promotion types.
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// Promotion is a coupon (Code set) or an automatic offer (Code empty).
+// Money fields are in paise, like the rest of the cart.
+type Promotion struct {
+	ID          string `json:"id"`
+	Code        string `json:"code,omitempty"`
+	Description string `json:"description"`
+
+	// Type is percent (Value is 1-100, capped by MaxDiscount if set) or
+	// fixed (Value is the amount off).
+	Type        string `json:"type"`
+	Value       int64  `json:"value"`
+	MaxDiscount int64  `json:"max_discount,omitempty"`
+
+	// Thresholds apply to the eligible lines only.
+	MinSubtotal int64 `json:"min_subtotal,omitempty"`
+	MinQuantity int   `json:"min_quantity,omitempty"`
+
+	// Scope: with neither set, every line is eligible; with both, a line
+	// matching either is.
+	Categories []string `json:"categories,omitempty"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+
+	// Usage limits count orders placed and not cancelled unpaid; zero
+	// means unlimited.
+	UsageLimit   int `json:"usage_limit,omitempty"`
+	PerUserLimit int `json:"per_user_limit,omitempty"`
+
+	// Stackable promotions combine with each other; a non-stackable one is
+	// only ever applied alone.
+	Stackable bool `json:"stackable"`
+	Active    bool `json:"active"`
+}
+
+var promoIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Validate normalises the code and checks the promotion is well formed.
+func (p *Promotion) Validate() error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if !promoIDPattern.MatchString(p.ID) {
+		return fmt.Errorf("id must be lowercase letters, digits, - or _")
+	}
+	switch p.Type {
+	case PromoPercent:
+		if p.Value < 1 || p.Value > 100 {
+			return fmt.Errorf("percent value must be between 1 and 100")
+		}
+	case PromoFixed:
+		if p.Value <= 0 {
+			return fmt.Errorf("fixed value must be positive")
+		}
+	default:
+		return fmt.Errorf("type must be percent or fixed")
+	}
+	if p.MaxDiscount < 0 || p.MinSubtotal < 0 || p.MinQuantity < 0 || p.UsageLimit < 0 || p.PerUserLimit < 0 {
+		return fmt.Errorf("limits and thresholds cannot be negative")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// DiscountLine is one promotion applied to a cart.
+type DiscountLine struct {
+	PromotionID string `json:"promotion_id"`
+	Code        string `json:"code,omitempty"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
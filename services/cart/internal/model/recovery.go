@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Snapshot statuses.
+const (
+	SnapshotActive    = "active"
+	SnapshotAbandoned = "abandoned"
+	SnapshotRecovered = "recovered"
+	SnapshotEmptied   = "emptied"
+	SnapshotConverted = "converted"
+)
+
+// Cart events recorded and sent to the notifier.
+const (
+	EventCartAbandoned = "cart.abandoned"
+	EventCartRecovered = "cart.recovered"
+	EventCartConverted = "cart.converted"
+)
+
+// CartSnapshot is the durable copy of a Redis cart. LastActivityAt is when
+// the cart was last changed; RemindersSent counts abandonment stages
+// reached since then.
+type CartSnapshot struct {
+	CartKey        string     `json:"cart_key"`
+	UserID         *string    `json:"user_id,omitempty"`
+	Items          []CartItem `json:"items"`
+	Subtotal       int64      `json:"subtotal"`
+	ItemCount      int        `json:"item_count"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	Status         string     `json:"status"`
+	RemindersSent  int        `json:"reminders_sent"`
+	AbandonedAt    *time.Time `json:"abandoned_at,omitempty"`
+	RecoveredAt    *time.Time `json:"recovered_at,omitempty"`
+}
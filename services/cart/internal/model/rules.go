@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cart rule types.
+const (
+	// RuleMaxQuantity caps how many of a product, or of all products in a
+	// category together, one cart may hold.
+	RuleMaxQuantity = "max_quantity"
+	// RuleLifetimeLimit caps how many of a product one customer may ever
+	// order, counting past orders from the orders service.
+	RuleLifetimeLimit = "lifetime_limit"
+	// RuleMinOrderValue sets the smallest discounted total that may check
+	// out.
+	RuleMinOrderValue = "min_order_value"
+)
+
+// Rule violation codes.
+const (
+	ViolationProductLimit     = "product_limit_exceeded"
+	ViolationCategoryLimit    = "category_limit_exceeded"
+	ViolationLifetimeLimit    = "lifetime_limit_exceeded"
+	ViolationLoginRequired    = "login_required"
+	ViolationMinOrderValue    = "below_min_order_value"
+	ViolationLimitsUnverified = "limits_unverified"
+)
+
+// CartRule is an admin-defined purchase rule. Money is in paise.
+type CartRule struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+
+	// Scope for max_quantity (one of the two) and lifetime_limit
+	// (ProductID only).
+	ProductID string `json:"product_id,omitempty"`
+	Category  string `json:"category,omitempty"`
+
+	Max      int   `json:"max,omitempty"`
+	MinValue int64 `json:"min_value,omitempty"`
+
+	// Message overrides the default explanation shown to shoppers.
+	Message string `json:"message,omitempty"`
+	Active  bool   `json:"active"`
+}
+
+// Validate checks the rule is well formed for its type.
+func (r *CartRule) Validate() error {
+	r.ProductID = strings.TrimSpace(r.ProductID)
+	r.Category = strings.TrimSpace(r.Category)
+	if !promoIDPattern.MatchString(r.ID) {
+		return fmt.Errorf("id must be lowercase letters, digits, - or _")
+	}
+	switch r.Type {
+	case RuleMaxQuantity:
+		if (r.ProductID == "") == (r.Category == "") {
+			return fmt.Errorf("max_quantity needs exactly one of product_id or category")
+		}
+		if r.Max < 1 {
+			return fmt.Errorf("max must be at least 1")
+		}
+	case RuleLifetimeLimit:
+		if r.ProductID == "" || r.Category != "" {
+			return fmt.Errorf("lifetime_limit needs product_id")
+		}
+		if r.Max < 1 {
+			return fmt.Errorf("max must be at least 1")
+		}
+	case RuleMinOrderValue:
+		if r.MinValue <= 0 {
+			return fmt.Errorf("min_value must be positive")
+		}
+		if r.ProductID != "" || r.Category != "" {
+			return fmt.Errorf("min_order_value applies to the whole cart")
+		}
+	default:
+		return fmt.Errorf("type must be max_quantity, lifetime_limit or min_order_value")
+	}
+	return nil
+}
+
+// RuleViolation explains why a cart change was refused or why the cart
+// cannot check out. Allowed is how many of the product the line may hold.
+type RuleViolation struct {
+	Code      string `json:"code"`
+	RuleID    string `json:"rule_id,omitempty"`
+	Message   string `json:"message"`
+	ProductID string `json:"product_id,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	Allowed   int    `json:"allowed"`
+	MinValue  int64  `json:"min_value,omitempty"`
+}
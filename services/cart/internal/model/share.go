@@ -0,0 +1,93 @@
+package model
+
+import "time"
+
+// Share kinds. A snapshot is a read-only copy of a cart; a registry is a
+// list others buy from, tracking how much of each line has been bought.
+const (
+	ShareSnapshot = "snapshot"
+	ShareRegistry = "registry"
+)
+
+// SharedItem is a line of a shared cart. UnitPrice is the price in paise
+// when it was shared; Purchased only moves for registries.
+type SharedItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	Purchased int    `json:"purchased"`
+}
+
+// SharedCart is a cart published at a link. OwnerKey is the cart it was
+// shared from and is never sent to viewers.
+type SharedCart struct {
+	ID        string       `json:"id"`
+	Kind      string       `json:"kind"`
+	OwnerKey  string       `json:"-"`
+	OwnerID   *string      `json:"-"`
+	Title     string       `json:"title"`
+	Message   string       `json:"message,omitempty"`
+	EventDate *time.Time   `json:"event_date,omitempty"`
+	Items     []SharedItem `json:"items"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+	ClosedAt  *time.Time   `json:"closed_at,omitempty"`
+}
+
+type HydratedSharedItem struct {
+	Product   map[string]any `json:"product"`
+	Quantity  int            `json:"quantity"`
+	Purchased int            `json:"purchased"`
+	Remaining int            `json:"remaining"`
+	UnitPrice int64          `json:"unit_price"`
+	LineTotal int64          `json:"line_total"`
+	InStock   bool           `json:"in_stock"`
+
+	// Unavailable marks a product that is unpublished, deleted or could
+	// not be loaded; it cannot be imported.
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+// SharedCartResponse is a shared cart as a viewer sees it, priced at
+// today's prices. IsOwner is set for the shopper who shared it.
+type SharedCartResponse struct {
+	ID        string               `json:"id"`
+	Kind      string               `json:"kind"`
+	Title     string               `json:"title"`
+	Message   string               `json:"message,omitempty"`
+	EventDate *time.Time           `json:"event_date,omitempty"`
+	Items     []HydratedSharedItem `json:"items"`
+	Subtotal  int64                `json:"subtotal"`
+	ItemCount int                  `json:"item_count"`
+	Currency  string               `json:"currency"`
+	ExpiresAt *time.Time           `json:"expires_at,omitempty"`
+	Closed    bool                 `json:"closed"`
+	IsOwner   bool                 `json:"is_owner"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// Import results for a shared cart line.
+const (
+	ImportAdded       = "added"
+	ImportReduced     = "reduced"
+	ImportSkipped     = "skipped"
+	ImportUnavailable = "unavailable"
+)
+
+// ImportLine says what became of one shared line when it was copied into
+// a cart.
+type ImportLine struct {
+	ProductID string `json:"product_id"`
+	Requested int    `json:"requested"`
+	Added     int    `json:"added"`
+	Result    string `json:"result"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// RegistryPurchase is a registry line bought in a paid order.
+type RegistryPurchase struct {
+	RegistryID string `json:"registry_id"`
+	ProductID  string `json:"product_id"`
+	Quantity   int    `json:"quantity"`
+}
@@ -1,10 +1,31 @@
 package model
 
+import "time"
+
 type CartItem struct {
 	ProductID string `json:"product_id"`
 	Quantity  int    `json:"quantity"`
 	UnitPrice int64  `json:"unit_price"`
 	Currency  string `json:"currency"`
+
+	// RegistryID is set on lines imported from a gift registry, and
+	// RegistryQuantity is how many of the line's units were imported for
+	// it; only those count towards the registry once the order is paid.
+	// Units added any other way are the buyer's own.
+	RegistryID       string `json:"registry_id,omitempty"`
+	RegistryQuantity int    `json:"registry_quantity,omitempty"`
+}
+
+// RegistryUnits is how many of the line's units are for its registry.
+// Lines saved before RegistryQuantity existed count whole.
+func (it CartItem) RegistryUnits() int {
+	if it.RegistryID == "" {
+		return 0
+	}
+	if it.RegistryQuantity <= 0 || it.RegistryQuantity > it.Quantity {
+		return it.Quantity
+	}
+	return it.RegistryQuantity
 }
 
 type HydratedItem struct {
@@ -12,6 +33,16 @@ type HydratedItem struct {
 	Quantity  int            `json:"quantity"`
 	UnitPrice int64          `json:"unit_price"`
 	LineTotal int64          `json:"line_total"`
+	Notices   []Notice       `json:"notices,omitempty"`
+
+	// RegistryID is the gift registry the line was imported from, if any,
+	// and RegistryQuantity how many of its units are for the registry.
+	RegistryID       string `json:"registry_id,omitempty"`
+	RegistryQuantity int    `json:"registry_quantity,omitempty"`
+
+	// Unavailable marks a line whose product could not be loaded; it is
+	// shown from the snapshot and blocks checkout until it loads again.
+	Unavailable bool `json:"unavailable,omitempty"`
 }
 
 type CartResponse struct {
@@ -19,4 +50,127 @@ type CartResponse struct {
 	Subtotal  int64          `json:"subtotal"`
 	ItemCount int            `json:"item_count"`
 	Currency  string         `json:"currency"`
+
+	// Discounts are the promotions applied; Total is Subtotal less their
+	// sum. CouponError says why CouponCode is not applied, if it isn't.
+	Discounts     []DiscountLine `json:"discounts"`
+	DiscountTotal int64          `json:"discount_total"`
+	Total         int64          `json:"total"`
+	CouponCode    string         `json:"coupon_code,omitempty"`
+	CouponError   string         `json:"coupon_error,omitempty"`
+
+	// Tax is the GST included in Total; Charges (delivery, COD) are added
+	// on top of it to give GrandTotal.
+	Delivery   DeliveryOptions `json:"delivery"`
+	Tax        TaxBreakdown    `json:"tax"`
+	Charges    []ChargeLine    `json:"charges"`
+	GrandTotal int64           `json:"grand_total"`
+
+	// Notices holds cart-level notices, i.e. lines that were removed.
+	Notices []Notice `json:"notices"`
+	// RequiresAcknowledgement is set while any notice is outstanding;
+	// checkout is refused until POST /v1/cart/acknowledge.
+	RequiresAcknowledgement bool `json:"requires_acknowledgement"`
+
+	// Violations are purchase rules the cart breaks, e.g. after a rule
+	// changed or a merge; checkout is refused while there are any.
+	Violations []RuleViolation `json:"violations"`
+
+	// Merge reports, once, what a sign-in merged into this cart.
+	Merge *MergeReport `json:"merge,omitempty"`
+}
+
+// Notice types.
+const (
+	NoticePriceIncreased  = "price_increased"
+	NoticePriceDecreased  = "price_decreased"
+	NoticeQuantityReduced = "quantity_reduced"
+	NoticeItemRemoved     = "item_removed"
+)
+
+// Reasons for NoticeItemRemoved.
+const (
+	RemovedUnpublished = "unpublished"
+	RemovedDeleted     = "deleted"
+	RemovedOutOfStock  = "out_of_stock"
+)
+
+// Notice tells the shopper that a line changed since it was added. Prices
+// are in paise.
+type Notice struct {
+	Type        string `json:"type"`
+	ProductID   string `json:"product_id"`
+	Title       string `json:"title,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	OldPrice    int64  `json:"old_price,omitempty"`
+	NewPrice    int64  `json:"new_price,omitempty"`
+	OldQuantity int    `json:"old_quantity,omitempty"`
+	NewQuantity int    `json:"new_quantity,omitempty"`
+}
+
+// WishlistItem is a saved product. UnitPrice is the price in paise when it
+// was saved.
+type WishlistItem struct {
+	ProductID string    `json:"product_id"`
+	UnitPrice int64     `json:"unit_price"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type HydratedWishlistItem struct {
+	Product    map[string]any `json:"product"`
+	UnitPrice  int64          `json:"unit_price"`
+	SavedPrice int64          `json:"saved_price"`
+	InStock    bool           `json:"in_stock"`
+	AddedAt    time.Time      `json:"added_at"`
+
+	// Unavailable marks a product that is unpublished or could not be
+	// loaded; it stays saved but cannot be moved to the cart.
+	Unavailable bool `json:"unavailable,omitempty"`
+}
+
+type WishlistResponse struct {
+	Items    []HydratedWishlistItem `json:"items"`
+	Count    int                    `json:"count"`
+	Currency string                 `json:"currency"`
+}
+
+// Merge policies for products in both the guest and the user cart.
+const (
+	MergeSum      = "sum"
+	MergeMax      = "max"
+	MergeKeepUser = "keep_user"
+)
+
+// Merge results for a guest cart line.
+const (
+	MergeAdded    = "added"
+	MergeCombined = "combined"
+	MergeKeptUser = "kept_user"
+	MergeDropped  = "dropped"
+)
+
+// MergeLine says what became of one guest cart line on sign-in. Clamped
+// is set when stock capped the merged quantity.
+type MergeLine struct {
+	ProductID     string `json:"product_id"`
+	GuestQuantity int    `json:"guest_quantity"`
+	UserQuantity  int    `json:"user_quantity"`
+	Quantity      int    `json:"quantity"`
+	Result        string `json:"result"`
+	Clamped       bool   `json:"clamped,omitempty"`
+}
+
+// MergeReport describes folding a guest session into a signed-in cart.
+type MergeReport struct {
+	Policy          string      `json:"policy"`
+	Lines           []MergeLine `json:"lines"`
+	WishlistAdded   int         `json:"wishlist_added"`
+	CouponCarried   bool        `json:"coupon_carried"`
+	DeliveryCarried bool        `json:"delivery_carried"`
+	MergedAt        time.Time   `json:"merged_at"`
+}
+
+// Empty reports whether the merge moved nothing into the user's cart.
+func (r *MergeReport) Empty() bool {
+	return len(r.Lines) == 0 && r.WishlistAdded == 0 && !r.CouponCarried && !r.DeliveryCarried
 }
@@ -0,0 +1,93 @@
+// Package notify hands cart events (abandoned-cart reminders, recoveries)
+// to a configurable channel: the log (default) or a webhook that owns
+// delivery by email, push or SMS.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+// Event is a cart event with its links resolved. Carts only know user IDs,
+// so receivers look up contact details themselves.
+type Event struct {
+	Type        string           `json:"type"`
+	CartKey     string           `json:"cart_key"`
+	UserID      string           `json:"user_id,omitempty"`
+	Stage       int              `json:"stage,omitempty"`
+	Items       []model.CartItem `json:"items"`
+	Subtotal    int64            `json:"subtotal"`
+	Currency    string           `json:"currency"`
+	RecoveryURL string           `json:"recovery_url,omitempty"`
+	OccurredAt  time.Time        `json:"occurred_at"`
+}
+
+type Sender interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// NewSenderFromEnv builds the sender selected by CART_NOTIFIER.
+func NewSenderFromEnv() (Sender, error) {
+	switch kind := os.Getenv("CART_NOTIFIER"); kind {
+	case "", "log":
+		return LogSender{}, nil
+	case "webhook":
+		url := os.Getenv("CART_WEBHOOK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("webhook notifier needs CART_WEBHOOK_URL")
+		}
+		return &WebhookSender{
+			url: url,
+			c:   &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
+
+// LogSender only logs; useful in development and as a safe default. It
+// leaves out the recovery URL, whose token works as a credential for the
+// cart.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, e Event) error {
+	log.Printf("%s: %s stage %d, %d lines", e.Type, e.CartKey, e.Stage, len(e.Items))
+	return nil
+}
+
+// WebhookSender POSTs each event as JSON. Any non-2xx response is a
+// failure.
+type WebhookSender struct {
+	url string
+	c   *http.Client
+}
+
+func (s *WebhookSender) Send(ctx context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package pricing
+
+import "regexp"
+
+// stateNames maps GST state codes to names.
+var stateNames = map[string]string{
+	"01": "Jammu and Kashmir", "02": "Himachal Pradesh", "03": "Punjab",
+	"04": "Chandigarh", "05": "Uttarakhand", "06": "Haryana", "07": "Delhi",
+	"08": "Rajasthan", "09": "Uttar Pradesh", "10": "Bihar", "11": "Sikkim",
+	"12": "Arunachal Pradesh", "13": "Nagaland", "14": "Manipur",
+	"15": "Mizoram", "16": "Tripura", "17": "Meghalaya", "18": "Assam",
+	"19": "West Bengal", "20": "Jharkhand", "21": "Odisha",
+	"22": "Chhattisgarh", "23": "Madhya Pradesh", "24": "Gujarat",
+	"26": "Dadra and Nagar Haveli and Daman and Diu", "27": "Maharashtra",
+	"29": "Karnataka", "30": "Goa", "31": "Lakshadweep", "32": "Kerala",
+	"33": "Tamil Nadu", "34": "Puducherry", "35": "Andaman and Nicobar Islands",
+	"36": "Telangana", "37": "Andhra Pradesh", "38": "Ladakh",
+}
+
+// pinStates maps pincode prefixes to GST state codes. Postal circles do
+// not follow state lines exactly, so three-digit prefixes override the
+// two-digit ones where a whole district belongs to another state, and full
+// pincodes override both for enclaves that share a district's prefix; the
+// result is an estimate until the address is confirmed at checkout.
+var pinStates = map[string]string{
+	"11": "07", "12": "06", "13": "06", "14": "03", "15": "03", "16": "03",
+	"17": "02", "18": "01", "19": "01",
+	"20": "09", "21": "09", "22": "09", "23": "09", "24": "09", "25": "09",
+	"26": "09", "27": "09", "28": "09",
+	"30": "08", "31": "08", "32": "08", "33": "08", "34": "08",
+	"36": "24", "37": "24", "38": "24", "39": "24",
+	"40": "27", "41": "27", "42": "27", "43": "27", "44": "27",
+	"45": "23", "46": "23", "47": "23", "48": "23", "49": "22",
+	"50": "36", "51": "37", "52": "37", "53": "37",
+	"56": "29", "57": "29", "58": "29", "59": "29",
+	"60": "33", "61": "33", "62": "33", "63": "33", "64": "33",
+	"67": "32", "68": "32", "69": "32",
+	"70": "19", "71": "19", "72": "19", "73": "19", "74": "19",
+	"75": "21", "76": "21", "77": "21", "78": "18", "79": "18",
+	"80": "10", "81": "10", "82": "10", "83": "20", "84": "10", "85": "10",
+
+	"160": "04", "194": "38",
+	"244": "05", "246": "05", "247": "05", "248": "05", "249": "05",
+	"262": "05", "263": "05",
+	"403": "30", "605": "34",
+	"673": "32", "682": "32", "737": "11", "744": "35",
+	"790": "12", "791": "12", "792": "12", "793": "17", "794": "17",
+	"795": "14", "796": "15", "797": "13", "798": "13", "799": "16",
+	"814": "20", "815": "20", "816": "20", "825": "20", "826": "20",
+	"827": "20", "828": "20", "829": "20", "831": "20", "832": "20",
+	"833": "20", "834": "20", "835": "20",
+
+	// Daman, Diu and Dadra and Nagar Haveli within Valsad and Junagadh.
+	"396193": "26", "396210": "26", "396215": "26", "396220": "26",
+	"396230": "26", "396235": "26", "396240": "26", "362520": "26",
+	// Yanam, Karaikal and Mahe within Andhra Pradesh, Tamil Nadu and Kerala.
+	"533464": "34",
+	"609602": "34", "609603": "34", "609604": "34", "609605": "34",
+	"609606": "34", "609607": "34", "609609": "34",
+	"673310": "34",
+}
+
+var pincodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+
+// ValidPincode reports whether pin looks like an Indian PIN code.
+func ValidPincode(pin string) bool {
+	return pincodePattern.MatchString(pin)
+}
+
+// StateForPincode returns the GST state code a pincode delivers to.
+func StateForPincode(pin string) (string, bool) {
+	if !ValidPincode(pin) {
+		return "", false
+	}
+	if s, ok := pinStates[pin]; ok {
+		return s, true
+	}
+	if s, ok := pinStates[pin[:3]]; ok {
+		return s, true
+	}
+	s, ok := pinStates[pin[:2]]
+	return s, ok
+}
+
+// StateName returns the name for a GST state code.
+func StateName(code string) string {
+	return stateNames[code]
+}
@@ -0,0 +1,32 @@
+package pricing
+
+import "testing"
+
+func TestStateForPincode(t *testing.T) {
+	tests := []struct {
+		pin, want string
+	}{
+		{"110001", "07"}, // Delhi
+		{"396001", "24"}, // Valsad, Gujarat
+		{"396210", "26"}, // Daman
+		{"396230", "26"}, // Silvassa
+		{"533001", "37"}, // Kakinada, Andhra Pradesh
+		{"533464", "34"}, // Yanam
+		{"609001", "33"}, // Mayiladuthurai, Tamil Nadu
+		{"609602", "34"}, // Karaikal
+		{"673001", "32"}, // Kozhikode, Kerala
+		{"673310", "34"}, // Mahe
+		{"605001", "34"}, // Puducherry
+		{"403001", "30"}, // Goa
+	}
+	for _, tt := range tests {
+		got, ok := StateForPincode(tt.pin)
+		if !ok || got != tt.want {
+			t.Errorf("StateForPincode(%q) = %q, %v; want %q", tt.pin, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := StateForPincode("012345"); ok {
+		t.Error("StateForPincode accepted a pincode starting with 0")
+	}
+}
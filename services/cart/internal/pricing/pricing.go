@@ -0,0 +1,161 @@
+// Package pricing works out the GST included in a cart and what delivery
+// will cost. Like promo it is pure: callers supply lines, rules and the
+// shopper's delivery options.
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+// Line is a cart line as pricing sees it. Prices are in paise and include
+// GST.
+type Line struct {
+	ProductID   string
+	HSNCode     string
+	UnitPrice   int64
+	Quantity    int
+	WeightGrams int
+}
+
+type Result struct {
+	Tax        model.TaxBreakdown
+	Charges    []model.ChargeLine
+	GrandTotal int64
+}
+
+// Price computes tax and charges for lines after promotions took discount
+// off them. GrandTotal is what the shopper pays.
+func Price(rules Rules, lines []Line, discount int64, opts model.DeliveryOptions) Result {
+	res := Result{
+		Tax:     tax(rules.Tax, lines, discount, opts.Pincode),
+		Charges: []model.ChargeLine{},
+	}
+
+	var subtotal int64
+	for _, l := range lines {
+		subtotal += l.UnitPrice * int64(l.Quantity)
+	}
+	total := subtotal - discount
+	res.GrandTotal = total
+
+	if len(lines) == 0 {
+		return res
+	}
+
+	ship := shipping(rules.Shipping, lines, total)
+	res.Charges = append(res.Charges, ship)
+	res.GrandTotal += ship.Amount
+
+	if opts.COD && rules.Shipping.CODSurcharge > 0 {
+		res.Charges = append(res.Charges, model.ChargeLine{
+			Type:   model.ChargeCODSurcharge,
+			Label:  "Cash on delivery",
+			Amount: rules.Shipping.CODSurcharge,
+		})
+		res.GrandTotal += rules.Shipping.CODSurcharge
+	}
+	return res
+}
+
+// tax backs the GST out of each line's discounted value. Discounts are
+// shared across lines in proportion to their value; the last line takes
+// the rounding remainder so shares add up exactly.
+func tax(cfg TaxConfig, lines []Line, discount int64, pincode string) model.TaxBreakdown {
+	out := model.TaxBreakdown{Inclusive: true, Lines: []model.TaxLine{}}
+
+	var subtotal int64
+	for _, l := range lines {
+		subtotal += l.UnitPrice * int64(l.Quantity)
+	}
+	if subtotal <= 0 {
+		return out
+	}
+	discount = min(max(discount, 0), subtotal)
+
+	remaining := discount
+	for i, l := range lines {
+		value := l.UnitPrice * int64(l.Quantity)
+		share := discount * value / subtotal
+		if i == len(lines)-1 {
+			share = remaining
+		}
+		remaining -= share
+		value -= share
+
+		hsn := l.HSNCode
+		if hsn == "" {
+			hsn = cfg.DefaultHSN
+		}
+		var unit int64
+		if l.Quantity > 0 {
+			unit = value / int64(l.Quantity)
+		}
+		rate := cfg.rate(hsn, unit)
+		t := includedTax(value, rate)
+
+		out.Lines = append(out.Lines, model.TaxLine{
+			ProductID:    l.ProductID,
+			HSNCode:      hsn,
+			Rate:         rate,
+			TaxableValue: value - t,
+			Tax:          t,
+		})
+		out.Total += t
+	}
+
+	state, ok := StateForPincode(pincode)
+	if !ok {
+		return out
+	}
+	out.State = StateName(state)
+	if state == cfg.OriginState {
+		cgst := out.Total / 2
+		out.SupplyType = model.SupplyIntraState
+		out.Components = []model.TaxComponent{
+			{Type: model.TaxCGST, Amount: cgst},
+			{Type: model.TaxSGST, Amount: out.Total - cgst},
+		}
+	} else {
+		out.SupplyType = model.SupplyInterState
+		out.Components = []model.TaxComponent{
+			{Type: model.TaxIGST, Amount: out.Total},
+		}
+	}
+	return out
+}
+
+// includedTax is the GST inside an inclusive amount at rate percent.
+func includedTax(amount int64, rate float64) int64 {
+	if amount <= 0 || rate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amount) * rate / (100 + rate)))
+}
+
+func shipping(cfg ShippingConfig, lines []Line, total int64) model.ChargeLine {
+	grams := 0
+	for _, l := range lines {
+		w := l.WeightGrams
+		if w <= 0 {
+			w = cfg.DefaultWeightGrams
+		}
+		grams += w * l.Quantity
+	}
+
+	line := model.ChargeLine{Type: model.ChargeShipping}
+	if cfg.FreeAbove > 0 && total >= cfg.FreeAbove {
+		line.Label = "Free delivery"
+		return line
+	}
+
+	line.Amount = cfg.BaseCharge
+	if extra := grams - cfg.BaseWeightGrams; extra > 0 && cfg.StepGrams > 0 {
+		steps := (extra + cfg.StepGrams - 1) / cfg.StepGrams
+		line.Amount += int64(steps) * cfg.StepCharge
+	}
+	line.Label = fmt.Sprintf("Delivery (%.1f kg)", float64(grams)/1000)
+	return line
+}
@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+func TestTax(t *testing.T) {
+	cfg := TaxConfig{
+		OriginState: "09",
+		DefaultHSN:  "5007",
+		DefaultRate: 18,
+		Rules: []TaxRule{
+			{HSNPrefix: "50", Slabs: []TaxSlab{{UpTo: 100000, Rate: 5}, {Rate: 12}}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		lines          []Line
+		discount       int64
+		pincode        string
+		wantLines      []model.TaxLine // ProductID and HSNCode are not compared
+		wantTotal      int64
+		wantSupply     string
+		wantComponents []model.TaxComponent
+	}{
+		{
+			name:      "no discount, no pincode",
+			lines:     []Line{{ProductID: "a", UnitPrice: 10500, Quantity: 2}},
+			wantLines: []model.TaxLine{{Rate: 5, TaxableValue: 20000, Tax: 1000}},
+			wantTotal: 1000,
+		},
+		{
+			// 4000 splits 3000/1000 by value; the odd total puts the extra
+			// paisa on SGST.
+			name: "proportional discount shares, intra-state",
+			lines: []Line{
+				{ProductID: "a", UnitPrice: 30000, Quantity: 1},
+				{ProductID: "b", UnitPrice: 10000, Quantity: 1},
+			},
+			discount: 4000,
+			pincode:  "221001", // Varanasi, same state as origin
+			wantLines: []model.TaxLine{
+				{Rate: 5, TaxableValue: 25714, Tax: 1286},
+				{Rate: 5, TaxableValue: 8571, Tax: 429},
+			},
+			wantTotal:  1715,
+			wantSupply: model.SupplyIntraState,
+			wantComponents: []model.TaxComponent{
+				{Type: model.TaxCGST, Amount: 857},
+				{Type: model.TaxSGST, Amount: 858},
+			},
+		},
+		{
+			// 1000 over three equal lines is 333, 333 and the last line
+			// takes the remaining 334.
+			name: "rounding remainder on the last line, inter-state",
+			lines: []Line{
+				{ProductID: "a", UnitPrice: 10000, Quantity: 1},
+				{ProductID: "b", UnitPrice: 10000, Quantity: 1},
+				{ProductID: "c", UnitPrice: 10000, Quantity: 1},
+			},
+			discount: 1000,
+			pincode:  "110001", // Delhi
+			wantLines: []model.TaxLine{
+				{Rate: 5, TaxableValue: 9207, Tax: 460},
+				{Rate: 5, TaxableValue: 9207, Tax: 460},
+				{Rate: 5, TaxableValue: 9206, Tax: 460},
+			},
+			wantTotal:      1380,
+			wantSupply:     model.SupplyInterState,
+			wantComponents: []model.TaxComponent{{Type: model.TaxIGST, Amount: 1380}},
+		},
+		{
+			name:      "slab above the threshold",
+			lines:     []Line{{ProductID: "a", UnitPrice: 112000, Quantity: 1}},
+			wantLines: []model.TaxLine{{Rate: 12, TaxableValue: 100000, Tax: 12000}},
+			wantTotal: 12000,
+		},
+		{
+			// The discounted unit price decides the slab.
+			name:      "discount moves a line to the lower slab",
+			lines:     []Line{{ProductID: "a", UnitPrice: 105000, Quantity: 1}},
+			discount:  10000,
+			wantLines: []model.TaxLine{{Rate: 5, TaxableValue: 90476, Tax: 4524}},
+			wantTotal: 4524,
+		},
+		{
+			name:      "HSN without a rule uses the default rate",
+			lines:     []Line{{ProductID: "a", HSNCode: "7113", UnitPrice: 11800, Quantity: 1}},
+			wantLines: []model.TaxLine{{Rate: 18, TaxableValue: 10000, Tax: 1800}},
+			wantTotal: 1800,
+		},
+		{
+			name:      "discount capped at the subtotal",
+			lines:     []Line{{ProductID: "a", UnitPrice: 10000, Quantity: 1}},
+			discount:  20000,
+			wantLines: []model.TaxLine{{Rate: 5, TaxableValue: 0, Tax: 0}},
+		},
+		{
+			name:      "unknown pincode leaves the split empty",
+			lines:     []Line{{ProductID: "a", UnitPrice: 10500, Quantity: 1}},
+			pincode:   "012345",
+			wantLines: []model.TaxLine{{Rate: 5, TaxableValue: 10000, Tax: 500}},
+			wantTotal: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tax(cfg, tt.lines, tt.discount, tt.pincode)
+
+			lines := make([]model.TaxLine, len(got.Lines))
+			for i, l := range got.Lines {
+				lines[i] = model.TaxLine{Rate: l.Rate, TaxableValue: l.TaxableValue, Tax: l.Tax}
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %+v; want %+v", lines, tt.wantLines)
+			}
+			if got.Total != tt.wantTotal {
+				t.Errorf("total = %d; want %d", got.Total, tt.wantTotal)
+			}
+			if got.SupplyType != tt.wantSupply {
+				t.Errorf("supply type = %q; want %q", got.SupplyType, tt.wantSupply)
+			}
+			if !reflect.DeepEqual(got.Components, tt.wantComponents) {
+				t.Errorf("components = %+v; want %+v", got.Components, tt.wantComponents)
+			}
+		})
+	}
+}
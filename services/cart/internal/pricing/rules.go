@@ -0,0 +1,142 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TaxSlab applies Rate (a percentage) to units priced at or below UpTo
+// paise after discounts. UpTo 0 means no upper bound.
+type TaxSlab struct {
+	UpTo int64   `json:"up_to"`
+	Rate float64 `json:"rate"`
+}
+
+// TaxRule gives the slabs for HSN codes starting with HSNPrefix. The
+// longest matching prefix wins.
+type TaxRule struct {
+	HSNPrefix string    `json:"hsn_prefix"`
+	Slabs     []TaxSlab `json:"slabs"`
+}
+
+type TaxConfig struct {
+	// OriginState is the GST state code of the dispatching warehouse.
+	OriginState string `json:"origin_state"`
+	// DefaultHSN is used for products without attributes.hsn_code.
+	DefaultHSN  string    `json:"default_hsn"`
+	DefaultRate float64   `json:"default_rate"`
+	Rules       []TaxRule `json:"rules"`
+}
+
+// ShippingConfig prices delivery by weight. Amounts are in paise.
+type ShippingConfig struct {
+	// FreeAbove waives the weight charge when the discounted total
+	// reaches it; 0 disables free delivery.
+	FreeAbove int64 `json:"free_above"`
+
+	BaseCharge      int64 `json:"base_charge"`
+	BaseWeightGrams int   `json:"base_weight_grams"`
+	StepGrams       int   `json:"step_grams"`
+	StepCharge      int64 `json:"step_charge"`
+
+	// DefaultWeightGrams is used for products without
+	// attributes.weight_grams.
+	DefaultWeightGrams int `json:"default_weight_grams"`
+
+	// CODSurcharge is added for cash on delivery, even when delivery
+	// is free.
+	CODSurcharge int64 `json:"cod_surcharge"`
+}
+
+type Rules struct {
+	Tax      TaxConfig
+	Shipping ShippingConfig
+}
+
+// DefaultRules follow the GST schedule for woven fabrics and apparel
+// (chapters 50-63: 5% up to ₹1000 a piece, 12% above) and a flat courier
+// tariff for a boutique shipping from Varanasi.
+func DefaultRules() Rules {
+	textile := []TaxSlab{{UpTo: 100000, Rate: 5}, {Rate: 12}}
+	var rules []TaxRule
+	for ch := 50; ch <= 63; ch++ {
+		rules = append(rules, TaxRule{HSNPrefix: fmt.Sprint(ch), Slabs: textile})
+	}
+
+	return Rules{
+		Tax: TaxConfig{
+			OriginState: "09",
+			DefaultHSN:  "5007",
+			DefaultRate: 12,
+			Rules:       rules,
+		},
+		Shipping: ShippingConfig{
+			FreeAbove:          99900,
+			BaseCharge:         7900,
+			BaseWeightGrams:    500,
+			StepGrams:          500,
+			StepCharge:         3000,
+			DefaultWeightGrams: 700,
+			CODSurcharge:       4900,
+		},
+	}
+}
+
+// RulesFromEnv starts from DefaultRules and overlays JSON from
+// CART_GST_RULES (a TaxConfig) and CART_SHIPPING_RULES (a ShippingConfig).
+// Fields left out keep their defaults.
+func RulesFromEnv() (Rules, error) {
+	r := DefaultRules()
+	if v := os.Getenv("CART_GST_RULES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &r.Tax); err != nil {
+			return r, fmt.Errorf("CART_GST_RULES: %w", err)
+		}
+	}
+	if v := os.Getenv("CART_SHIPPING_RULES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &r.Shipping); err != nil {
+			return r, fmt.Errorf("CART_SHIPPING_RULES: %w", err)
+		}
+	}
+	return r, r.validate()
+}
+
+func (r Rules) validate() error {
+	if _, ok := stateNames[r.Tax.OriginState]; !ok {
+		return fmt.Errorf("unknown origin state code %q", r.Tax.OriginState)
+	}
+	for _, rule := range r.Tax.Rules {
+		if strings.TrimSpace(rule.HSNPrefix) == "" || len(rule.Slabs) == 0 {
+			return fmt.Errorf("tax rule needs hsn_prefix and slabs")
+		}
+		last := rule.Slabs[len(rule.Slabs)-1]
+		if last.UpTo != 0 {
+			return fmt.Errorf("tax rule %s: last slab must have no up_to", rule.HSNPrefix)
+		}
+	}
+	if r.Shipping.StepCharge > 0 && r.Shipping.StepGrams <= 0 {
+		return fmt.Errorf("shipping step_grams must be > 0")
+	}
+	return nil
+}
+
+// rate returns the GST rate for one unit of hsn at unitPrice paise.
+func (c TaxConfig) rate(hsn string, unitPrice int64) float64 {
+	var best *TaxRule
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if strings.HasPrefix(hsn, rule.HSNPrefix) && (best == nil || len(rule.HSNPrefix) > len(best.HSNPrefix)) {
+			best = rule
+		}
+	}
+	if best == nil {
+		return c.DefaultRate
+	}
+	for _, s := range best.Slabs {
+		if s.UpTo == 0 || unitPrice <= s.UpTo {
+			return s.Rate
+		}
+	}
+	return c.DefaultRate
+}
@@ -0,0 +1,185 @@
+// Package promo decides which promotions apply to a cart and for how much.
+// It is pure: callers load promotions, usage counts and cart lines.
+package promo
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+// Line is a cart line as the engine sees it. Prices are in paise.
+type Line struct {
+	ProductID string
+	Category  string
+	UnitPrice int64
+	Quantity  int
+}
+
+// Usage is how often a promotion was redeemed overall and by this user.
+type Usage struct {
+	Global int
+	User   int
+}
+
+type Cart struct {
+	Lines  []Line
+	UserID string
+	Coupon string
+	Now    time.Time
+}
+
+type Result struct {
+	Discounts []model.DiscountLine
+	Total     int64
+	// CouponError says why the cart's coupon is not applied, if it isn't.
+	CouponError string
+}
+
+// Check returns the discount p would give the cart on its own, or why it
+// does not apply.
+func Check(p model.Promotion, u Usage, c Cart) (int64, string) {
+	switch {
+	case !p.Active:
+		return 0, "this offer is not active"
+	case p.StartsAt != nil && c.Now.Before(*p.StartsAt):
+		return 0, "this offer has not started yet"
+	case p.EndsAt != nil && !c.Now.Before(*p.EndsAt):
+		return 0, "this offer has expired"
+	case p.UsageLimit > 0 && u.Global >= p.UsageLimit:
+		return 0, "this offer has been fully redeemed"
+	case p.PerUserLimit > 0 && c.UserID == "":
+		return 0, "sign in to use this offer"
+	case p.PerUserLimit > 0 && u.User >= p.PerUserLimit:
+		return 0, "you have already used this offer"
+	}
+
+	var subtotal int64
+	var qty int
+	for _, l := range c.Lines {
+		if inScope(p, l) {
+			subtotal += l.UnitPrice * int64(l.Quantity)
+			qty += l.Quantity
+		}
+	}
+	switch {
+	case qty == 0:
+		return 0, "no items in your cart qualify for this offer"
+	case subtotal < p.MinSubtotal:
+		return 0, fmt.Sprintf("spend ₹%d more on qualifying items", (p.MinSubtotal-subtotal+99)/100)
+	case qty < p.MinQuantity:
+		return 0, fmt.Sprintf("add %d more qualifying items", p.MinQuantity-qty)
+	}
+
+	amount := p.Value
+	if p.Type == model.PromoPercent {
+		amount = subtotal * p.Value / 100
+		if p.MaxDiscount > 0 && amount > p.MaxDiscount {
+			amount = p.MaxDiscount
+		}
+	}
+	if amount > subtotal {
+		amount = subtotal
+	}
+	if amount <= 0 {
+		return 0, "no items in your cart qualify for this offer"
+	}
+	return amount, ""
+}
+
+func inScope(p model.Promotion, l Line) bool {
+	if len(p.Categories) == 0 && len(p.ProductIDs) == 0 {
+		return true
+	}
+	for _, c := range p.Categories {
+		if c == l.Category {
+			return true
+		}
+	}
+	for _, id := range p.ProductIDs {
+		if id == l.ProductID {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate applies automatic promotions and the cart's coupon. All
+// applicable stackable promotions combine; a non-stackable one applies alone,
+// and the engine picks whichever of the best non-stackable promotion or the
+// stackable set saves more. The total never exceeds the cart subtotal.
+func Evaluate(promos []model.Promotion, usage map[string]Usage, c Cart) Result {
+	var res Result
+
+	type candidate struct {
+		p      model.Promotion
+		amount int64
+	}
+	var stackable []candidate
+	var best *candidate
+	var stackTotal int64
+	couponFound := c.Coupon == ""
+
+	for _, p := range promos {
+		isCoupon := p.Code != ""
+		if isCoupon && p.Code != c.Coupon {
+			continue
+		}
+		amount, reason := Check(p, usage[p.ID], c)
+		if isCoupon {
+			couponFound = true
+			res.CouponError = reason
+		}
+		if reason != "" {
+			continue
+		}
+		cand := candidate{p, amount}
+		if p.Stackable {
+			stackable = append(stackable, cand)
+			stackTotal += amount
+		} else if best == nil || amount > best.amount {
+			best = &cand
+		}
+	}
+	if !couponFound {
+		res.CouponError = "this code is not valid"
+	}
+
+	chosen := stackable
+	if best != nil && best.amount > stackTotal {
+		chosen = []candidate{*best}
+	}
+	sort.SliceStable(chosen, func(i, j int) bool { return chosen[i].amount > chosen[j].amount })
+
+	var subtotal int64
+	for _, l := range c.Lines {
+		subtotal += l.UnitPrice * int64(l.Quantity)
+	}
+
+	couponApplied := false
+	for _, cand := range chosen {
+		amount := cand.amount
+		if res.Total+amount > subtotal {
+			amount = subtotal - res.Total
+		}
+		if amount <= 0 {
+			break
+		}
+		res.Discounts = append(res.Discounts, model.DiscountLine{
+			PromotionID: cand.p.ID,
+			Code:        cand.p.Code,
+			Description: cand.p.Description,
+			Amount:      amount,
+		})
+		res.Total += amount
+		if cand.p.Code != "" {
+			couponApplied = true
+		}
+	}
+	if c.Coupon != "" && res.CouponError == "" && !couponApplied {
+		res.CouponError = "this code does not combine with a better offer already applied"
+	}
+	return res
+}
@@ -0,0 +1,131 @@
+package promo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	percent := func(id string, value int64, stackable bool) model.Promotion {
+		return model.Promotion{ID: id, Description: id, Type: model.PromoPercent, Value: value, Stackable: stackable, Active: true}
+	}
+	fixed := func(id string, value int64, stackable bool) model.Promotion {
+		return model.Promotion{ID: id, Description: id, Type: model.PromoFixed, Value: value, Stackable: stackable, Active: true}
+	}
+	coupon := func(p model.Promotion, code string) model.Promotion {
+		p.Code = code
+		return p
+	}
+
+	// 1000 + 2 x 500 = 2000 rupees
+	lines := []Line{
+		{ProductID: "saree", Category: "sarees", UnitPrice: 100000, Quantity: 1},
+		{ProductID: "stole", Category: "stoles", UnitPrice: 50000, Quantity: 2},
+	}
+
+	tests := []struct {
+		name          string
+		promos        []model.Promotion
+		coupon        string
+		wantIDs       []string
+		wantAmounts   []int64
+		wantTotal     int64
+		wantCouponErr string
+	}{
+		{
+			name:        "stackable promotions combine, biggest first",
+			promos:      []model.Promotion{fixed("flat100", 10000, true), percent("ten", 10, true)},
+			wantIDs:     []string{"ten", "flat100"},
+			wantAmounts: []int64{20000, 10000},
+			wantTotal:   30000,
+		},
+		{
+			name:        "best non-stackable beats a smaller stack",
+			promos:      []model.Promotion{fixed("flat100", 10000, true), percent("ten", 10, true), percent("twenty", 20, false), fixed("flat50", 5000, false)},
+			wantIDs:     []string{"twenty"},
+			wantAmounts: []int64{40000},
+			wantTotal:   40000,
+		},
+		{
+			name:        "stack beats a smaller non-stackable",
+			promos:      []model.Promotion{fixed("flat100", 10000, true), percent("ten", 10, true), fixed("flat250", 25000, false)},
+			wantIDs:     []string{"ten", "flat100"},
+			wantAmounts: []int64{20000, 10000},
+			wantTotal:   30000,
+		},
+		{
+			name:        "coupon applies with the stack",
+			promos:      []model.Promotion{percent("ten", 10, true), coupon(fixed("welcome", 15000, true), "WELCOME")},
+			coupon:      "WELCOME",
+			wantIDs:     []string{"ten", "welcome"},
+			wantAmounts: []int64{20000, 15000},
+			wantTotal:   35000,
+		},
+		{
+			name:          "coupon loses to a better offer",
+			promos:        []model.Promotion{percent("twenty", 20, false), coupon(fixed("welcome", 15000, false), "WELCOME")},
+			coupon:        "WELCOME",
+			wantIDs:       []string{"twenty"},
+			wantAmounts:   []int64{40000},
+			wantTotal:     40000,
+			wantCouponErr: "this code does not combine with a better offer already applied",
+		},
+		{
+			name:          "unknown coupon",
+			promos:        []model.Promotion{coupon(fixed("welcome", 15000, true), "WELCOME")},
+			coupon:        "NOPE",
+			wantTotal:     0,
+			wantCouponErr: "this code is not valid",
+		},
+		{
+			name:          "coupon below its minimum",
+			promos:        []model.Promotion{coupon(model.Promotion{ID: "big", Type: model.PromoFixed, Value: 50000, MinSubtotal: 250000, Active: true}, "BIG")},
+			coupon:        "BIG",
+			wantCouponErr: "spend ₹500 more on qualifying items",
+		},
+		{
+			// 1500 + 1000 stack to more than the 2000 subtotal; the smaller
+			// one is cut down to what is left.
+			name:        "total capped at the subtotal",
+			promos:      []model.Promotion{fixed("big", 150000, true), fixed("bigger", 100000, true)},
+			wantIDs:     []string{"big", "bigger"},
+			wantAmounts: []int64{150000, 50000},
+			wantTotal:   200000,
+		},
+		{
+			name:        "percent capped by max discount and scoped to a category",
+			promos:      []model.Promotion{{ID: "sarees", Type: model.PromoPercent, Value: 50, MaxDiscount: 30000, Categories: []string{"sarees"}, Active: true}},
+			wantIDs:     []string{"sarees"},
+			wantAmounts: []int64{30000},
+			wantTotal:   30000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Cart{Lines: lines, UserID: "u1", Coupon: tt.coupon, Now: now}
+			res := Evaluate(tt.promos, nil, c)
+
+			var ids []string
+			var amounts []int64
+			for _, d := range res.Discounts {
+				ids = append(ids, d.PromotionID)
+				amounts = append(amounts, d.Amount)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) || !reflect.DeepEqual(amounts, tt.wantAmounts) {
+				t.Errorf("discounts = %v %v; want %v %v", ids, amounts, tt.wantIDs, tt.wantAmounts)
+			}
+			if res.Total != tt.wantTotal {
+				t.Errorf("total = %d; want %d", res.Total, tt.wantTotal)
+			}
+			if res.CouponError != tt.wantCouponErr {
+				t.Errorf("coupon error = %q; want %q", res.CouponError, tt.wantCouponErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// activityKey is a sorted set of cart keys changed since their last
+// snapshot, scored by when (unix milliseconds).
+const activityKey = "carts:activity"
+
+// clearActivityScript drops KEYS[1] member ARGV[1] only if it still has
+// score ARGV[2], so a change made during a snapshot is not lost.
+var clearActivityScript = redis.NewScript(`
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if score and tonumber(score) == tonumber(ARGV[2]) then
+  return redis.call('ZREM', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+// TouchCart marks a cart as changed now.
+func (r *RedisStore) TouchCart(ctx context.Context, key string) error {
+	return r.cli.ZAdd(ctx, activityKey, redis.Z{
+		Score:  float64(time.Now().UnixMilli()),
+		Member: key,
+	}).Err()
+}
+
+// ChangedCart is a cart awaiting a snapshot.
+type ChangedCart struct {
+	Key     string
+	Changed time.Time
+	score   float64
+}
+
+// ChangedCarts returns up to limit carts awaiting a snapshot, oldest change
+// first.
+func (r *RedisStore) ChangedCarts(ctx context.Context, limit int64) ([]ChangedCart, error) {
+	zs, err := r.cli.ZRangeWithScores(ctx, activityKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ChangedCart, 0, len(zs))
+	for _, z := range zs {
+		key, _ := z.Member.(string)
+		out = append(out, ChangedCart{
+			Key:     key,
+			Changed: time.UnixMilli(int64(z.Score)),
+			score:   z.Score,
+		})
+	}
+	return out, nil
+}
+
+// SnapshotDone clears a cart from the changed set unless it changed again.
+func (r *RedisStore) SnapshotDone(ctx context.Context, c ChangedCart) error {
+	return clearActivityScript.Run(ctx, r.cli, []string{activityKey},
+		c.Key, strconv.FormatFloat(c.score, 'f', -1, 64)).Err()
+}
+
+// RestoreItems adds snapshot lines to a cart, leaving lines already there
+// untouched. Hydration clamps them to current stock.
+func (r *RedisStore) RestoreItems(ctx context.Context, key string, items map[string]string) (int, error) {
+	var cmds []*redis.BoolCmd
+	_, err := r.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		for pid, v := range items {
+			cmds = append(cmds, p.HSetNX(ctx, key, pid, v))
+		}
+		p.PExpire(ctx, key, r.ttlFor(key))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, c := range cmds {
+		if c.Val() {
+			added++
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// deliveryKey holds a cart's delivery options; it shares the cart's TTL.
+func deliveryKey(key string) string { return "delivery:" + key }
+
+func (r *RedisStore) SetDelivery(ctx context.Context, key string, opts model.DeliveryOptions) error {
+	b, _ := json.Marshal(opts)
+	return r.cli.Set(ctx, deliveryKey(key), b, r.ttlFor(key)).Err()
+}
+
+func (r *RedisStore) GetDelivery(ctx context.Context, key string) (model.DeliveryOptions, error) {
+	var opts model.DeliveryOptions
+	b, err := r.cli.Get(ctx, deliveryKey(key)).Bytes()
+	if err == redis.Nil {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, err
+	}
+	return opts, json.Unmarshal(b, &opts)
+}
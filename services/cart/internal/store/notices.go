@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// noticesKey holds a cart's outstanding quantity and removal notices, one
+// field per type and product. Price notices are not stored: they are derived
+// from the snapshot on every read.
+func noticesKey(key string) string { return key + ":notices" }
+
+// AddNotice records a notice until the shopper acknowledges it. A repeated
+// quantity reduction keeps the quantity from before the first one.
+func (r *RedisStore) AddNotice(ctx context.Context, key string, n model.Notice) error {
+	nk := noticesKey(key)
+	field := n.Type + ":" + n.ProductID
+
+	if n.Type == model.NoticeQuantityReduced {
+		if prev, err := r.cli.HGet(ctx, nk, field).Result(); err == nil {
+			var old model.Notice
+			if json.Unmarshal([]byte(prev), &old) == nil && old.OldQuantity > n.OldQuantity {
+				n.OldQuantity = old.OldQuantity
+			}
+		}
+	}
+
+	b, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = r.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, nk, field, b)
+		p.PExpire(ctx, nk, r.ttlFor(key))
+		return nil
+	})
+	return err
+}
+
+func (r *RedisStore) GetNotices(ctx context.Context, key string) ([]model.Notice, error) {
+	res, err := r.cli.HGetAll(ctx, noticesKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Notice, 0, len(res))
+	for _, v := range res {
+		var n model.Notice
+		if json.Unmarshal([]byte(v), &n) == nil {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (r *RedisStore) ClearNotices(ctx context.Context, key string) error {
+	return r.cli.Del(ctx, noticesKey(key)).Err()
+}
+
+// SetUnitPrice replaces the price snapshot of an existing line.
+func (r *RedisStore) SetUnitPrice(ctx context.Context, key, productID string, price int64) (*model.CartItem, error) {
+	return r.runItemScript(ctx, setPriceScript, key,
+		productID, price, r.ttlFor(key).Milliseconds())
+}
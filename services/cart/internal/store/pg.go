@@ -0,0 +1,341 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrTokenNotFound covers unknown and expired recovery tokens alike.
+var ErrTokenNotFound = errors.New("recovery link is invalid or has expired")
+
+// PGStore keeps durable cart snapshots for abandoned-cart recovery, and
+// shared carts. Carts themselves stay in Redis.
+type PGStore struct {
+	db       *pgxpool.Pool
+	shareTTL time.Duration
+}
+
+// NewPGFromEnv connects to DATABASE_URL. Without one it returns nil and
+// snapshots and sharing are disabled. Share links last
+// CART_SHARE_TTL_DAYS (default 30).
+func NewPGFromEnv(ctx context.Context) (*PGStore, error) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		return nil, nil
+	}
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	shareDays := 30
+	if v := os.Getenv("CART_SHARE_TTL_DAYS"); v != "" {
+		fmt.Sscanf(v, "%d", &shareDays)
+	}
+	return &PGStore{db: pool, shareTTL: time.Hour * 24 * time.Duration(shareDays)}, nil
+}
+
+func (s *PGStore) Close() {
+	s.db.Close()
+}
+
+// UpsertSnapshot saves a cart's current state. A cart changed since the
+// last snapshot becomes active again and its reminders start over; an
+// empty one is marked emptied.
+func (s *PGStore) UpsertSnapshot(ctx context.Context, snap model.CartSnapshot) error {
+	items, err := json.Marshal(snap.Items)
+	if err != nil {
+		return err
+	}
+	status := model.SnapshotActive
+	if snap.ItemCount == 0 {
+		status = model.SnapshotEmptied
+	}
+
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO cart_snapshots (
+			cart_key, user_id, items, subtotal_cents, item_count,
+			last_activity_at, status
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (cart_key) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			items = EXCLUDED.items,
+			subtotal_cents = EXCLUDED.subtotal_cents,
+			item_count = EXCLUDED.item_count,
+			snapshotted_at = now(),
+			status = CASE
+				WHEN EXCLUDED.item_count = 0 THEN 'emptied'
+				WHEN EXCLUDED.last_activity_at > cart_snapshots.last_activity_at THEN 'active'
+				ELSE cart_snapshots.status
+			END,
+			reminders_sent = CASE
+				WHEN EXCLUDED.last_activity_at > cart_snapshots.last_activity_at THEN 0
+				ELSE cart_snapshots.reminders_sent
+			END,
+			abandoned_at = CASE
+				WHEN EXCLUDED.last_activity_at > cart_snapshots.last_activity_at THEN NULL
+				ELSE cart_snapshots.abandoned_at
+			END,
+			last_activity_at = GREATEST(EXCLUDED.last_activity_at, cart_snapshots.last_activity_at)
+	`,
+		snap.CartKey,
+		snap.UserID,
+		items,
+		snap.Subtotal,
+		snap.ItemCount,
+		snap.LastActivityAt,
+		status,
+	)
+	return err
+}
+
+const snapshotColumns = `
+	cart_key, user_id, items, subtotal_cents, item_count, last_activity_at,
+	status, reminders_sent, abandoned_at, recovered_at`
+
+func scanSnapshot(row pgx.Row) (*model.CartSnapshot, error) {
+	var snap model.CartSnapshot
+	var items []byte
+	if err := row.Scan(
+		&snap.CartKey,
+		&snap.UserID,
+		&items,
+		&snap.Subtotal,
+		&snap.ItemCount,
+		&snap.LastActivityAt,
+		&snap.Status,
+		&snap.RemindersSent,
+		&snap.AbandonedAt,
+		&snap.RecoveredAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(items, &snap.Items); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func collectSnapshots(rows pgx.Rows) ([]model.CartSnapshot, error) {
+	defer rows.Close()
+	out := []model.CartSnapshot{}
+	for rows.Next() {
+		snap, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *snap)
+	}
+	return out, rows.Err()
+}
+
+// DueForReminder returns non-empty carts idle past their next stage:
+// stage n (1-based) is due once the cart has been idle for stages[n-1].
+// Converted, emptied and recovered carts are never due.
+func (s *PGStore) DueForReminder(ctx context.Context, stages []time.Duration, limit int) ([]model.CartSnapshot, error) {
+	secs := make([]float64, len(stages))
+	for i, d := range stages {
+		secs[i] = d.Seconds()
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+snapshotColumns+`
+		FROM cart_snapshots
+		WHERE status IN ('active', 'abandoned')
+		  AND item_count > 0
+		  AND reminders_sent < cardinality($1::float8[])
+		  AND last_activity_at <= now() - make_interval(secs => ($1::float8[])[reminders_sent + 1])
+		ORDER BY last_activity_at
+		LIMIT $2
+	`, secs, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectSnapshots(rows)
+}
+
+// MarkAbandoned records that a cart reached stage. It reports false when
+// another worker got there first or the cart changed meanwhile.
+func (s *PGStore) MarkAbandoned(ctx context.Context, cartKey string, stage int) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE cart_snapshots
+		SET status = 'abandoned',
+		    reminders_sent = $2,
+		    abandoned_at = COALESCE(abandoned_at, now())
+		WHERE cart_key = $1 AND reminders_sent = $2 - 1
+		  AND status IN ('active', 'abandoned')
+	`, cartKey, stage)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (s *PGStore) ListAbandoned(ctx context.Context, page, limit int) ([]model.CartSnapshot, int, error) {
+	var total int
+	if err := s.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM cart_snapshots WHERE status = 'abandoned'`,
+	).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+snapshotColumns+`
+		FROM cart_snapshots
+		WHERE status = 'abandoned'
+		ORDER BY abandoned_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	snaps, err := collectSnapshots(rows)
+	return snaps, total, err
+}
+
+func (s *PGStore) CreateRecoveryToken(ctx context.Context, cartKey, tokenHash string, expires time.Time) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO cart_recovery_tokens (token_hash, cart_key, expires_at)
+		VALUES ($1, $2, $3)
+	`, tokenHash, cartKey, expires)
+	return err
+}
+
+// SnapshotForToken returns the snapshot a live recovery token points at.
+// Tokens may be used more than once until they expire, since reminder
+// links are often opened twice.
+func (s *PGStore) SnapshotForToken(ctx context.Context, tokenHash string) (*model.CartSnapshot, error) {
+	row := s.db.QueryRow(ctx, `
+		SELECT `+snapshotColumns+`
+		FROM cart_snapshots
+		WHERE cart_key = (
+			SELECT cart_key FROM cart_recovery_tokens
+			WHERE token_hash = $1 AND expires_at > now()
+		)
+	`, tokenHash)
+	snap, err := scanSnapshot(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTokenNotFound
+	}
+	return snap, err
+}
+
+func (s *PGStore) MarkRecovered(ctx context.Context, cartKey, tokenHash string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE cart_recovery_tokens SET used_at = COALESCE(used_at, now())
+		WHERE token_hash = $1
+	`, tokenHash); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE cart_snapshots
+		SET status = 'recovered', recovered_at = now()
+		WHERE cart_key = $1 AND status <> 'emptied'
+	`, cartKey); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkConverted records that a cart was checked out and paid for. The
+// snapshot is created if the worker has not copied the cart yet, and its
+// activity moves up to now, so only a change after payment makes it active
+// (and due for reminders) again.
+func (s *PGStore) MarkConverted(ctx context.Context, cartKey string, userID *string) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO cart_snapshots (cart_key, user_id, last_activity_at, status)
+		VALUES ($1, $2, now(), 'converted')
+		ON CONFLICT (cart_key) DO UPDATE SET
+			status = 'converted',
+			reminders_sent = 0,
+			abandoned_at = NULL,
+			last_activity_at = GREATEST(cart_snapshots.last_activity_at, now())
+	`, cartKey, userID)
+	return err
+}
+
+func (s *PGStore) RecordEvent(ctx context.Context, cartKey, eventType string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO cart_events (cart_key, type, payload) VALUES ($1, $2, $3)
+	`, cartKey, eventType, b)
+	return err
+}
+
+// UseTokenForOptOut spends a live recovery token's one unsubscribe and
+// returns the user who owns its cart. Used tokens, and tokens for guest
+// carts, are reported as ErrTokenNotFound.
+func (s *PGStore) UseTokenForOptOut(ctx context.Context, tokenHash string) (string, error) {
+	var userID string
+	err := s.db.QueryRow(ctx, `
+		UPDATE cart_recovery_tokens t
+		SET opt_out_used_at = now()
+		FROM cart_snapshots c
+		WHERE t.token_hash = $1 AND t.expires_at > now()
+		  AND t.opt_out_used_at IS NULL
+		  AND c.cart_key = t.cart_key AND c.user_id IS NOT NULL
+		RETURNING c.user_id
+	`, tokenHash).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTokenNotFound
+	}
+	return userID, err
+}
+
+func (s *PGStore) SetReminderOptOut(ctx context.Context, userID string, optOut bool) error {
+	var err error
+	if optOut {
+		_, err = s.db.Exec(ctx, `
+			INSERT INTO cart_reminder_optouts (user_id) VALUES ($1)
+			ON CONFLICT (user_id) DO NOTHING
+		`, userID)
+	} else {
+		_, err = s.db.Exec(ctx, `DELETE FROM cart_reminder_optouts WHERE user_id = $1`, userID)
+	}
+	return err
+}
+
+func (s *PGStore) IsOptedOut(ctx context.Context, userID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM cart_reminder_optouts WHERE user_id = $1)`,
+		userID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// NewRecoveryToken returns a random link token and the hash to store.
+func NewRecoveryToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRecoveryToken(token), nil
+}
+
+func HashRecoveryToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,266 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/promo"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrPromotionNotFound  = errors.New("promotion not found")
+	ErrPromotionExists    = errors.New("promotion already exists")
+	ErrCodeTaken          = errors.New("code is used by another promotion")
+	ErrPromotionExhausted = errors.New("promotion usage limit reached")
+)
+
+// Promotions are stored as JSON in one hash keyed by ID. Usage counts live
+// in promotionUsageKey (ID → orders) and promotionUserUsageKey(id) (user ID
+// → orders); redeemedKey(order) records what an order counted.
+const (
+	promotionsKey     = "promotions"
+	promotionUsageKey = "promotion:usage"
+)
+
+// redeemedTTL bounds how long an order's redemption is remembered for
+// idempotency and release.
+const redeemedTTL = 30 * 24 * time.Hour
+
+func promotionUserUsageKey(id string) string { return "promotion:usage:" + id }
+func redeemedKey(orderID string) string      { return "promotion:redeemed:" + orderID }
+
+// couponKey holds the code applied to a cart; it shares the cart's TTL.
+func couponKey(key string) string { return "coupon:" + key }
+
+func (r *RedisStore) ListPromotions(ctx context.Context) ([]model.Promotion, error) {
+	res, err := r.cli.HGetAll(ctx, promotionsKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Promotion, 0, len(res))
+	for _, v := range res {
+		var p model.Promotion
+		if json.Unmarshal([]byte(v), &p) == nil {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *RedisStore) GetPromotion(ctx context.Context, id string) (*model.Promotion, error) {
+	v, err := r.cli.HGet(ctx, promotionsKey, id).Result()
+	if err == redis.Nil {
+		return nil, ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p model.Promotion
+	if err := json.Unmarshal([]byte(v), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindPromotionByCode returns the promotion with a coupon code.
+func (r *RedisStore) FindPromotionByCode(ctx context.Context, code string) (*model.Promotion, error) {
+	promos, err := r.ListPromotions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range promos {
+		if p.Code != "" && p.Code == code {
+			return &p, nil
+		}
+	}
+	return nil, ErrPromotionNotFound
+}
+
+// SavePromotion writes a validated promotion. With create set it fails if
+// the ID exists; otherwise the ID must exist. Codes must be unique.
+func (r *RedisStore) SavePromotion(ctx context.Context, p *model.Promotion, create bool) error {
+	promos, err := r.ListPromotions(ctx)
+	if err != nil {
+		return err
+	}
+	exists := false
+	for _, other := range promos {
+		if other.ID == p.ID {
+			exists = true
+			continue
+		}
+		if p.Code != "" && other.Code == p.Code {
+			return ErrCodeTaken
+		}
+	}
+	if create && exists {
+		return ErrPromotionExists
+	}
+	if !create && !exists {
+		return ErrPromotionNotFound
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return r.cli.HSet(ctx, promotionsKey, p.ID, b).Err()
+}
+
+func (r *RedisStore) DeletePromotion(ctx context.Context, id string) error {
+	n, err := r.cli.HDel(ctx, promotionsKey, id).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+// PromotionUsage returns usage counts for every promotion, with per-user
+// counts for userID when it is set.
+func (r *RedisStore) PromotionUsage(ctx context.Context, promos []model.Promotion, userID string) (map[string]promo.Usage, error) {
+	out := map[string]promo.Usage{}
+	if len(promos) == 0 {
+		return out, nil
+	}
+
+	global, err := r.cli.HGetAll(ctx, promotionUsageKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	userCmds := map[string]*redis.StringCmd{}
+	if userID != "" {
+		_, err := r.cli.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, pr := range promos {
+				if pr.PerUserLimit > 0 {
+					userCmds[pr.ID] = p.HGet(ctx, promotionUserUsageKey(pr.ID), userID)
+				}
+			}
+			return nil
+		})
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+	}
+
+	for _, pr := range promos {
+		var u promo.Usage
+		u.Global, _ = strconv.Atoi(global[pr.ID])
+		if cmd, ok := userCmds[pr.ID]; ok {
+			u.User, _ = strconv.Atoi(cmd.Val())
+		}
+		out[pr.ID] = u
+	}
+	return out, nil
+}
+
+// RedeemPromotions counts one use of each promotion for an order as it is
+// placed, failing with ErrPromotionExhausted if any has reached its global
+// or per-user limit, and with ErrPromotionNotFound if one was deleted. The
+// check and the count are atomic, so concurrent checkouts cannot
+// oversubscribe a capped offer. It is idempotent per order and reports
+// false if the order was already redeemed or released.
+func (r *RedisStore) RedeemPromotions(ctx context.Context, orderID, userID string, promotionIDs []string) (bool, error) {
+	ids := dedupe(promotionIDs)
+	if len(ids) == 0 {
+		return false, nil
+	}
+	keys := []string{redeemedKey(orderID), promotionsKey, promotionUsageKey}
+	args := []any{userID, redeemedTTL.Milliseconds()}
+	for _, id := range ids {
+		keys = append(keys, promotionUserUsageKey(id))
+		args = append(args, id)
+	}
+
+	res, err := redeemScript.Run(ctx, r.cli, keys, args...).Slice()
+	if err != nil {
+		return false, err
+	}
+	if len(res) != 2 {
+		return false, fmt.Errorf("unexpected script reply %v", res)
+	}
+	status, _ := res[0].(int64)
+	payload, _ := res[1].(string)
+	switch status {
+	case scriptNotFound:
+		return false, fmt.Errorf("%w: %s", ErrPromotionNotFound, payload)
+	case scriptTooMany:
+		return false, fmt.Errorf("%w: %s", ErrPromotionExhausted, payload)
+	}
+	return payload == "", nil
+}
+
+// redemption is what redeemedKey holds for an order whose uses are
+// counted, so ReleasePromotions can give them back.
+type redemption struct {
+	UserID       string   `json:"user_id"`
+	PromotionIDs []string `json:"promotion_ids"`
+}
+
+// ReleasePromotions gives back the uses counted for an order that was
+// cancelled before payment. It reports false if there was nothing to
+// release; an order once released cannot be redeemed again.
+func (r *RedisStore) ReleasePromotions(ctx context.Context, orderID string) (bool, error) {
+	raw, err := r.cli.Get(ctx, redeemedKey(orderID)).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var red redemption
+	if json.Unmarshal([]byte(raw), &red) != nil || len(red.PromotionIDs) == 0 {
+		return false, nil
+	}
+
+	keys := []string{redeemedKey(orderID), promotionUsageKey}
+	args := []any{raw, red.UserID}
+	for _, id := range red.PromotionIDs {
+		keys = append(keys, promotionUserUsageKey(id))
+		args = append(args, id)
+	}
+	n, err := releaseScript.Run(ctx, r.cli, keys, args...).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (r *RedisStore) SetCoupon(ctx context.Context, key, code string) error {
+	return r.cli.Set(ctx, couponKey(key), code, r.ttlFor(key)).Err()
+}
+
+func (r *RedisStore) GetCoupon(ctx context.Context, key string) (string, error) {
+	code, err := r.cli.Get(ctx, couponKey(key)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return code, err
+}
+
+func (r *RedisStore) ClearCoupon(ctx context.Context, key string) error {
+	return r.cli.Del(ctx, couponKey(key)).Err()
+}
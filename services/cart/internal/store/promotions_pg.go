@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// PromotionBackup is the durable state of the promotions: their
+// definitions, how often each was counted overall and per user, and what
+// recent orders counted so they can still be released.
+type PromotionBackup struct {
+	Promotions []model.Promotion
+	Global     map[string]int
+	PerUser    map[string]map[string]int
+	Orders     map[string]redemption
+}
+
+// SavePromotion writes the durable copy of a promotion.
+func (s *PGStore) SavePromotion(ctx context.Context, p *model.Promotion) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO cart_promotions (id, definition) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET
+			definition = EXCLUDED.definition,
+			updated_at = now()
+	`, p.ID, b)
+	return err
+}
+
+func (s *PGStore) DeletePromotion(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM cart_promotions WHERE id = $1`, id)
+	return err
+}
+
+// RecordRedemption saves the uses counted for an order. Repeats are
+// ignored.
+func (s *PGStore) RecordRedemption(ctx context.Context, orderID, userID string, promotionIDs []string) error {
+	var uid *string
+	if userID != "" {
+		uid = &userID
+	}
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO cart_promotion_redemptions (order_id, promotion_id, user_id)
+		SELECT $1, unnest($2::text[]), $3
+		ON CONFLICT (order_id, promotion_id) DO NOTHING
+	`, orderID, promotionIDs, uid)
+	return err
+}
+
+// DeleteRedemption drops the uses counted for a cancelled order.
+func (s *PGStore) DeleteRedemption(ctx context.Context, orderID string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM cart_promotion_redemptions WHERE order_id = $1`, orderID)
+	return err
+}
+
+// LoadPromotionBackup reads the durable promotion state. Only orders
+// counted within redeemedTTL are listed, matching what Redis remembers.
+func (s *PGStore) LoadPromotionBackup(ctx context.Context) (*PromotionBackup, error) {
+	b := &PromotionBackup{
+		Global:  map[string]int{},
+		PerUser: map[string]map[string]int{},
+		Orders:  map[string]redemption{},
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT definition FROM cart_promotions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var p model.Promotion
+		if json.Unmarshal(raw, &p) == nil {
+			b.Promotions = append(b.Promotions, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(ctx, `
+		SELECT order_id, promotion_id, COALESCE(user_id, ''),
+		       created_at > now() - make_interval(secs => $1)
+		FROM cart_promotion_redemptions
+		ORDER BY order_id, promotion_id
+	`, redeemedTTL.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var orderID, promoID, userID string
+		var recent bool
+		if err := rows.Scan(&orderID, &promoID, &userID, &recent); err != nil {
+			return nil, err
+		}
+		b.Global[promoID]++
+		if userID != "" {
+			if b.PerUser[promoID] == nil {
+				b.PerUser[promoID] = map[string]int{}
+			}
+			b.PerUser[promoID][userID]++
+		}
+		if recent {
+			red := b.Orders[orderID]
+			red.UserID = userID
+			red.PromotionIDs = append(red.PromotionIDs, promoID)
+			b.Orders[orderID] = red
+		}
+	}
+	return b, rows.Err()
+}
+
+// RestorePromotions rebuilds the promotions, usage counts and order
+// markers from b, but only when Redis has no promotions at all, so a
+// running deployment's live counters are never overwritten. It reports
+// whether it restored anything.
+func (r *RedisStore) RestorePromotions(ctx context.Context, b *PromotionBackup) (bool, error) {
+	if len(b.Promotions) == 0 {
+		return false, nil
+	}
+	restored := false
+	err := r.cli.Watch(ctx, func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, promotionsKey).Result()
+		if err != nil || n > 0 {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Del(ctx, promotionUsageKey)
+			for _, pr := range b.Promotions {
+				def, _ := json.Marshal(pr)
+				p.HSet(ctx, promotionsKey, pr.ID, def)
+				p.Del(ctx, promotionUserUsageKey(pr.ID))
+			}
+			for id, n := range b.Global {
+				p.HSet(ctx, promotionUsageKey, id, n)
+			}
+			for id, users := range b.PerUser {
+				for uid, n := range users {
+					p.HSet(ctx, promotionUserUsageKey(id), uid, n)
+				}
+			}
+			for orderID, red := range b.Orders {
+				v, _ := json.Marshal(red)
+				p.SetNX(ctx, redeemedKey(orderID), v, redeemedTTL)
+			}
+			return nil
+		})
+		restored = err == nil
+		return err
+	}, promotionsKey)
+	return restored, err
+}
+
+// SyncPromotions reconciles the two copies at startup: Redis is rebuilt
+// from Postgres if it lost its promotions, and promotions that only exist
+// in Redis (created before the durable copy) are written to Postgres.
+func SyncPromotions(ctx context.Context, r *RedisStore, s *PGStore) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	backup, err := s.LoadPromotionBackup(ctx)
+	if err != nil {
+		return err
+	}
+	restored, err := r.RestorePromotions(ctx, backup)
+	if err != nil {
+		return err
+	}
+	if restored {
+		return nil
+	}
+
+	known := make(map[string]bool, len(backup.Promotions))
+	for _, p := range backup.Promotions {
+		known[p.ID] = true
+	}
+	live, err := r.ListPromotions(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range live {
+		if known[live[i].ID] {
+			continue
+		}
+		if err := s.SavePromotion(ctx, &live[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
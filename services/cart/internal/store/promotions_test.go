@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+func savePromo(t *testing.T, s *RedisStore, p model.Promotion) {
+	t.Helper()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := s.SavePromotion(context.Background(), &p, true); err != nil {
+		t.Fatalf("SavePromotion: %v", err)
+	}
+}
+
+func usage(t *testing.T, s *RedisStore, id, userID string) (global, user int) {
+	t.Helper()
+	p, err := s.GetPromotion(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetPromotion: %v", err)
+	}
+	u, err := s.PromotionUsage(context.Background(), []model.Promotion{*p}, userID)
+	if err != nil {
+		t.Fatalf("PromotionUsage: %v", err)
+	}
+	return u[id].Global, u[id].User
+}
+
+func TestRedeemPromotionsConcurrentRespectsUsageLimit(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	const n, limit = 40, 7
+	savePromo(t, s, model.Promotion{ID: "festive", Code: "FESTIVE10", Type: model.PromoPercent, Value: 10, UsageLimit: limit, Active: true})
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		ok, spent int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.RedeemPromotions(ctx, fmt.Sprintf("order-%d", i), fmt.Sprintf("user-%d", i), []string{"festive"})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrPromotionExhausted):
+				spent++
+			default:
+				t.Errorf("RedeemPromotions: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if ok != limit || spent != n-limit {
+		t.Fatalf("redeemed %d and refused %d, want %d and %d", ok, spent, limit, n-limit)
+	}
+	if global, _ := usage(t, s, "festive", ""); global != limit {
+		t.Fatalf("global usage = %d, want %d", global, limit)
+	}
+}
+
+func TestRedeemPromotionsPerUserLimitAndRelease(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	savePromo(t, s, model.Promotion{ID: "welcome", Code: "WELCOME", Type: model.PromoFixed, Value: 5000, PerUserLimit: 1, Active: true})
+
+	if redeemed, err := s.RedeemPromotions(ctx, "o1", "u1", []string{"welcome"}); err != nil || !redeemed {
+		t.Fatalf("first redeem = %v, %v", redeemed, err)
+	}
+	if redeemed, err := s.RedeemPromotions(ctx, "o1", "u1", []string{"welcome"}); err != nil || redeemed {
+		t.Fatalf("repeat redeem = %v, %v; want already redeemed", redeemed, err)
+	}
+	if _, err := s.RedeemPromotions(ctx, "o2", "u1", []string{"welcome"}); !errors.Is(err, ErrPromotionExhausted) {
+		t.Fatalf("second order = %v, want ErrPromotionExhausted", err)
+	}
+	if _, err := s.RedeemPromotions(ctx, "o3", "", []string{"welcome"}); !errors.Is(err, ErrPromotionExhausted) {
+		t.Fatalf("guest order = %v, want ErrPromotionExhausted", err)
+	}
+	if _, err := s.RedeemPromotions(ctx, "o4", "u1", []string{"gone"}); !errors.Is(err, ErrPromotionNotFound) {
+		t.Fatalf("deleted promotion = %v, want ErrPromotionNotFound", err)
+	}
+
+	// Cancelling o1 gives its use back, once.
+	if released, err := s.ReleasePromotions(ctx, "o1"); err != nil || !released {
+		t.Fatalf("release = %v, %v", released, err)
+	}
+	if released, err := s.ReleasePromotions(ctx, "o1"); err != nil || released {
+		t.Fatalf("repeat release = %v, %v; want nothing to release", released, err)
+	}
+	if global, user := usage(t, s, "welcome", "u1"); global != 0 || user != 0 {
+		t.Fatalf("usage after release = %d/%d, want 0/0", global, user)
+	}
+	if redeemed, err := s.RedeemPromotions(ctx, "o1", "u1", []string{"welcome"}); err != nil || redeemed {
+		t.Fatalf("redeem after release = %v, %v; want refused as already seen", redeemed, err)
+	}
+	if redeemed, err := s.RedeemPromotions(ctx, "o2", "u1", []string{"welcome"}); err != nil || !redeemed {
+		t.Fatalf("new order after release = %v, %v", redeemed, err)
+	}
+}
+
+func TestRestorePromotionsOnlyIntoEmptyRedis(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	backup := &PromotionBackup{
+		Promotions: []model.Promotion{{ID: "welcome", Code: "WELCOME", Type: model.PromoFixed, Value: 5000, PerUserLimit: 1, Active: true}},
+		Global:     map[string]int{"welcome": 2},
+		PerUser:    map[string]map[string]int{"welcome": {"u1": 1, "u2": 1}},
+		Orders:     map[string]redemption{"o2": {UserID: "u2", PromotionIDs: []string{"welcome"}}},
+	}
+
+	if restored, err := s.RestorePromotions(ctx, backup); err != nil || !restored {
+		t.Fatalf("RestorePromotions = %v, %v", restored, err)
+	}
+	if global, user := usage(t, s, "welcome", "u1"); global != 2 || user != 1 {
+		t.Fatalf("restored usage = %d/%d, want 2/1", global, user)
+	}
+	if _, err := s.RedeemPromotions(ctx, "o3", "u1", []string{"welcome"}); !errors.Is(err, ErrPromotionExhausted) {
+		t.Fatalf("redeem past restored limit = %v, want ErrPromotionExhausted", err)
+	}
+	if released, err := s.ReleasePromotions(ctx, "o2"); err != nil || !released {
+		t.Fatalf("release restored order = %v, %v", released, err)
+	}
+
+	// Live counters are left alone once Redis has promotions.
+	if restored, err := s.RestorePromotions(ctx, backup); err != nil || restored {
+		t.Fatalf("second RestorePromotions = %v, %v; want skipped", restored, err)
+	}
+	if global, _ := usage(t, s, "welcome", ""); global != 1 {
+		t.Fatalf("usage after skipped restore = %d, want 1", global)
+	}
+}
@@ -3,8 +3,10 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
@@ -55,21 +57,94 @@ func NewRedisFromEnv() (*RedisStore, error) {
 func userKey(userID string) string  { return "cart:user:" + userID }
 func guestKey(sessID string) string { return "cart:guest:" + sessID }
 
-// SetItem - upsert quantity (overwrites) using productID
-func (r *RedisStore) SetItem(ctx context.Context, key, productID string, item model.CartItem) error {
-	b, _ := json.Marshal(item)
-	if err := r.cli.HSet(ctx, key, productID, b).Err(); err != nil {
-		return err
-	}
-	// refresh TTL
+var (
+	ErrItemNotFound      = errors.New("item not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrOtherRegistry     = errors.New("line holds units for another registry")
+)
+
+// ttlFor is the idle lifetime of a cart key.
+func (r *RedisStore) ttlFor(key string) time.Duration {
 	if isUserKey(key) {
-		return r.cli.Expire(ctx, key, r.ttlUser).Err()
+		return r.ttlUser
+	}
+	return r.ttlGuest
+}
+
+// runItemScript runs a single-line script and decodes its reply. A nil item
+// with a nil error means the line was removed.
+func (r *RedisStore) runItemScript(ctx context.Context, script *redis.Script, key string, args ...any) (*model.CartItem, error) {
+	res, err := script.Run(ctx, r.cli, []string{key}, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+	return decodeItemReply(res)
+}
+
+// decodeItemReply decodes the {status, payload} reply of a line script.
+func decodeItemReply(res []any) (*model.CartItem, error) {
+	if len(res) != 2 {
+		return nil, fmt.Errorf("unexpected script reply %v", res)
+	}
+	status, _ := res[0].(int64)
+	payload, _ := res[1].(string)
+
+	switch status {
+	case scriptNotFound:
+		return nil, ErrItemNotFound
+	case scriptTooMany:
+		return nil, ErrInsufficientStock
+	case scriptOtherRegistry:
+		return nil, fmt.Errorf("%w %s", ErrOtherRegistry, payload)
+	}
+	if payload == "" {
+		return nil, nil
 	}
-	return r.cli.Expire(ctx, key, r.ttlGuest).Err()
+	var item model.CartItem
+	if err := json.Unmarshal([]byte(payload), &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// AddItem adds item.Quantity to the line for item.ProductID, creating it if
+// needed, and refreshes the cart TTL. The price snapshot in item replaces the
+// stored one. maxQty caps the resulting quantity (-1 for no cap); going over
+// returns ErrInsufficientStock and leaves the cart untouched. Registry units
+// in item add to the line's own for the same registry; a line already
+// holding units for a different one returns ErrOtherRegistry.
+func (r *RedisStore) AddItem(ctx context.Context, key string, item model.CartItem, maxQty int) (*model.CartItem, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	return r.runItemScript(ctx, addItemScript, key,
+		item.ProductID, string(b), maxQty, r.ttlFor(key).Milliseconds())
+}
+
+// SetQuantity overwrites the quantity of an existing line and refreshes the
+// cart TTL. It returns ErrItemNotFound if the line is absent.
+func (r *RedisStore) SetQuantity(ctx context.Context, key, productID string, qty int) (*model.CartItem, error) {
+	return r.runItemScript(ctx, setQuantityScript, key,
+		productID, qty, r.ttlFor(key).Milliseconds())
+}
+
+// ClampQuantity lowers a line to at most maxQty, removing it when maxQty is
+// zero or less. It returns the resulting line, nil if it was removed, or
+// ErrItemNotFound.
+func (r *RedisStore) ClampQuantity(ctx context.Context, key, productID string, maxQty int) (*model.CartItem, error) {
+	return r.runItemScript(ctx, clampQuantityScript, key,
+		productID, maxQty, r.ttlFor(key).Milliseconds())
 }
 
+// DeleteItem removes a line and refreshes the cart TTL in one transaction.
 func (r *RedisStore) DeleteItem(ctx context.Context, key, productID string) error {
-	return r.cli.HDel(ctx, key, productID).Err()
+	_, err := r.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HDel(ctx, key, productID)
+		p.PExpire(ctx, key, r.ttlFor(key))
+		return nil
+	})
+	return err
 }
 
 func (r *RedisStore) GetAll(ctx context.Context, key string) ([]model.CartItem, error) {
@@ -89,37 +164,83 @@ func (r *RedisStore) GetAll(ctx context.Context, key string) ([]model.CartItem,
 	return out, nil
 }
 
-func (r *RedisStore) Merge(ctx context.Context, targetKey, srcKey string) error {
-	// simple merge: for each item in src -> set into target (overwrite quantity-add)
-	src, err := r.cli.HGetAll(ctx, srcKey).Result()
+// Merge folds the guest cart, wishlist, coupon and delivery options at
+// srcKey into the user's at targetKey in one step. policy decides
+// quantities for products in both; limits caps each product's merged
+// quantity at what stock allows.
+func (r *RedisStore) Merge(ctx context.Context, targetKey, srcKey, policy string, limits map[string]int) (*model.MergeReport, error) {
+	keys := []string{
+		targetKey, srcKey, noticesKey(srcKey),
+		wishlistKey(targetKey), wishlistKey(srcKey),
+		couponKey(targetKey), couponKey(srcKey),
+		deliveryKey(targetKey), deliveryKey(srcKey),
+	}
+	if limits == nil {
+		limits = map[string]int{}
+	}
+	b, _ := json.Marshal(limits)
+
+	res, err := mergeScript.Run(ctx, r.cli, keys, r.ttlFor(targetKey).Milliseconds(), policy, b).Text()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for field, val := range src {
-		var it model.CartItem
-		if err := json.Unmarshal([]byte(val), &it); err != nil {
-			continue
-		}
-		// if exists in target, sum quantities
-		tgtVal, _ := r.cli.HGet(ctx, targetKey, field).Result()
-		if tgtVal != "" {
-			var existing model.CartItem
-			_ = json.Unmarshal([]byte(tgtVal), &existing)
-			it.Quantity = existing.Quantity + it.Quantity
-		}
-		b, _ := json.Marshal(it)
-		if err := r.cli.HSet(ctx, targetKey, field, b).Err(); err != nil {
-			return err
-		}
+
+	report := &model.MergeReport{}
+	if err := json.Unmarshal([]byte(res), report); err != nil {
+		return nil, err
+	}
+	if report.Lines == nil {
+		report.Lines = []model.MergeLine{}
+	}
+	report.Policy = policy
+	report.MergedAt = time.Now().UTC()
+	return report, nil
+}
+
+// HasGuestData reports whether a session has anything worth merging.
+func (r *RedisStore) HasGuestData(ctx context.Context, key string) (bool, error) {
+	n, err := r.cli.Exists(ctx, key, wishlistKey(key), couponKey(key), deliveryKey(key)).Result()
+	return n > 0, err
+}
+
+// mergeReportKey keeps the last merge report until the cart is next read.
+func mergeReportKey(key string) string { return "merge-report:" + key }
+
+// SaveMergeReport keeps report for the next read of the cart. Reports of
+// merges that moved nothing are dropped, so a concurrent request that
+// found the guest cart already merged cannot overwrite the real report.
+func (r *RedisStore) SaveMergeReport(ctx context.Context, key string, report *model.MergeReport) error {
+	if report.Empty() {
+		return nil
+	}
+	b, _ := json.Marshal(report)
+	return r.cli.Set(ctx, mergeReportKey(key), b, 24*time.Hour).Err()
+}
+
+// TakeMergeReport returns and clears the last merge report, or nil.
+func (r *RedisStore) TakeMergeReport(ctx context.Context, key string) (*model.MergeReport, error) {
+	b, err := r.cli.GetDel(ctx, mergeReportKey(key)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report model.MergeReport
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
 	}
-	// delete src key
-	return r.cli.Del(ctx, srcKey).Err()
+	return &report, nil
 }
 
+// isUserKey reports whether a cart key belongs to a signed-in user (see
+// api.resolveKey), which keeps its cart longer than a guest.
 func isUserKey(k string) bool {
-	return len(k) > 10 && k[:10] == "cart:user:"
+	return strings.HasPrefix(k, "user:")
 }
 
+// GetItem reads one cart line, or nil if the cart has none for productID.
+// Handlers always load the whole cart; this is kept for the store tests.
 func (s *RedisStore) GetItem(
 	ctx context.Context,
 	key string,
@@ -143,5 +264,5 @@ func (s *RedisStore) GetItem(
 }
 
 func (r *RedisStore) DeleteAll(ctx context.Context, key string) error {
-	return r.cli.Del(ctx, key).Err()
+	return r.cli.Del(ctx, key, noticesKey(key), couponKey(key), deliveryKey(key)).Err()
 }
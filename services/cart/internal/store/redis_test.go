@@ -0,0 +1,306 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T) *RedisStore {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cli := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { cli.Close() })
+	return &RedisStore{cli: cli, ttlUser: time.Hour, ttlGuest: time.Hour}
+}
+
+func line(pid string, qty int) model.CartItem {
+	return model.CartItem{ProductID: pid, Quantity: qty, UnitPrice: 1000, Currency: "INR"}
+}
+
+func quantity(t *testing.T, s *RedisStore, key, pid string) int {
+	t.Helper()
+	it, err := s.GetItem(context.Background(), key, pid)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if it == nil {
+		return 0
+	}
+	return it.Quantity
+}
+
+func TestAddItemConcurrent(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	const n = 64
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.AddItem(ctx, "user:u1", line("p1", 1), -1); err != nil {
+				t.Errorf("AddItem: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := quantity(t, s, "user:u1", "p1"); got != n {
+		t.Fatalf("quantity = %d, want %d", got, n)
+	}
+}
+
+func TestAddItemConcurrentRespectsStockCap(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	const n, maxQty = 64, 20
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		ok, full int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.AddItem(ctx, "user:u1", line("p1", 1), maxQty)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrInsufficientStock):
+				full++
+			default:
+				t.Errorf("AddItem: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := quantity(t, s, "user:u1", "p1"); got != maxQty {
+		t.Fatalf("quantity = %d, want %d", got, maxQty)
+	}
+	if ok != maxQty || full != n-maxQty {
+		t.Fatalf("accepted %d and rejected %d adds, want %d and %d", ok, full, maxQty, n-maxQty)
+	}
+}
+
+func TestMixedMutationsConcurrent(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	const n, maxQty = 90, 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			switch i % 3 {
+			case 0:
+				_, err = s.AddItem(ctx, "user:u1", line("p1", 3), maxQty)
+			case 1:
+				_, err = s.SetQuantity(ctx, "user:u1", "p1", i%maxQty+1)
+			case 2:
+				err = s.DeleteItem(ctx, "user:u1", "p1")
+			}
+			if err != nil && !errors.Is(err, ErrInsufficientStock) && !errors.Is(err, ErrItemNotFound) {
+				t.Errorf("mutation %d: %v", i, err)
+			}
+			if got := quantity(t, s, "user:u1", "p1"); got > maxQty {
+				t.Errorf("quantity %d exceeds cap %d", got, maxQty)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	items, err := s.GetAll(ctx, "user:u1")
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	for _, it := range items {
+		if it.ProductID != "p1" || it.Quantity < 1 || it.Quantity > maxQty {
+			t.Fatalf("corrupt line %+v", it)
+		}
+	}
+
+	// A final add from a known state lands exactly.
+	if err := s.DeleteItem(ctx, "user:u1", "p1"); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	if _, err := s.AddItem(ctx, "user:u1", line("p1", 4), maxQty); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if got := quantity(t, s, "user:u1", "p1"); got != 4 {
+		t.Fatalf("quantity = %d, want 4", got)
+	}
+}
+
+func TestClampQuantity(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.AddItem(ctx, "user:u1", line("p1", 5), -1); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	it, err := s.ClampQuantity(ctx, "user:u1", "p1", 2)
+	if err != nil || it == nil || it.Quantity != 2 {
+		t.Fatalf("ClampQuantity = %+v, %v; want quantity 2", it, err)
+	}
+	it, err = s.ClampQuantity(ctx, "user:u1", "p1", 0)
+	if err != nil || it != nil {
+		t.Fatalf("ClampQuantity to 0 = %+v, %v; want removed", it, err)
+	}
+	if _, err := s.ClampQuantity(ctx, "user:u1", "p1", 1); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("ClampQuantity on missing line = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestMergeConcurrent(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	const n = 16
+	user, guest := "user:u1", "guest:s1"
+
+	for _, it := range []model.CartItem{line("p1", 2), line("p2", 5)} {
+		if _, err := s.AddItem(ctx, guest, it, -1); err != nil {
+			t.Fatalf("AddItem: %v", err)
+		}
+	}
+	if _, err := s.AddItem(ctx, user, line("p1", 3), -1); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		merged int
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := s.Merge(ctx, user, guest, "sum", map[string]int{"p2": 4})
+			if err != nil {
+				t.Errorf("Merge: %v", err)
+				return
+			}
+			if len(report.Lines) > 0 {
+				mu.Lock()
+				merged++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if merged != 1 {
+		t.Fatalf("%d merges moved lines, want 1", merged)
+	}
+	if got := quantity(t, s, user, "p1"); got != 5 {
+		t.Fatalf("p1 quantity = %d, want 5", got)
+	}
+	if got := quantity(t, s, user, "p2"); got != 4 {
+		t.Fatalf("p2 quantity = %d, want 4 (clamped)", got)
+	}
+	if has, err := s.HasGuestData(ctx, guest); err != nil || has {
+		t.Fatalf("HasGuestData = %v, %v; want guest data consumed", has, err)
+	}
+}
+
+func TestMergeCarriesGuestDeliveryOptions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user, guest := "user:u1", "guest:s1"
+
+	if err := s.SetDelivery(ctx, guest, model.DeliveryOptions{Pincode: "560001", COD: true}); err != nil {
+		t.Fatalf("SetDelivery: %v", err)
+	}
+	if has, err := s.HasGuestData(ctx, guest); err != nil || !has {
+		t.Fatalf("HasGuestData = %v, %v; want delivery options to count", has, err)
+	}
+
+	report, err := s.Merge(ctx, user, guest, "sum", nil)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !report.DeliveryCarried || report.Empty() {
+		t.Fatalf("report = %+v, want delivery carried", report)
+	}
+	opts, err := s.GetDelivery(ctx, user)
+	if err != nil || opts.Pincode != "560001" || !opts.COD {
+		t.Fatalf("user delivery = %+v, %v", opts, err)
+	}
+}
+
+func TestSaveMergeReportKeepsNonEmptyReport(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user, guest := "user:u1", "guest:s1"
+
+	if _, err := s.AddItem(ctx, guest, line("p1", 2), -1); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	first, err := s.Merge(ctx, user, guest, "sum", nil)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	second, err := s.Merge(ctx, user, guest, "sum", nil)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !second.Empty() {
+		t.Fatalf("second merge = %+v, want empty", second)
+	}
+
+	for _, r := range []*model.MergeReport{first, second} {
+		if err := s.SaveMergeReport(ctx, user, r); err != nil {
+			t.Fatalf("SaveMergeReport: %v", err)
+		}
+	}
+	got, err := s.TakeMergeReport(ctx, user)
+	if err != nil || got == nil || len(got.Lines) != 1 {
+		t.Fatalf("TakeMergeReport = %+v, %v; want the first merge's report", got, err)
+	}
+}
+
+func TestAddItemKeepsRegistryUnitsApart(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	key := "user:u1"
+	gift := func(registry string, qty int) model.CartItem {
+		it := line("p1", qty)
+		it.RegistryID, it.RegistryQuantity = registry, qty
+		return it
+	}
+
+	if _, err := s.AddItem(ctx, key, line("p1", 2), -1); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	it, err := s.AddItem(ctx, key, gift("r1", 1), -1)
+	if err != nil || it.Quantity != 3 || it.RegistryID != "r1" || it.RegistryUnits() != 1 {
+		t.Fatalf("registry import = %+v, %v; want 3 units, 1 for r1", it, err)
+	}
+	if it, err = s.AddItem(ctx, key, line("p1", 1), -1); err != nil || it.Quantity != 4 || it.RegistryUnits() != 1 {
+		t.Fatalf("plain add = %+v, %v; want 4 units, still 1 for r1", it, err)
+	}
+	if it, err = s.AddItem(ctx, key, gift("r1", 2), -1); err != nil || it.RegistryUnits() != 3 {
+		t.Fatalf("second r1 import = %+v, %v; want 3 for r1", it, err)
+	}
+	if _, err := s.AddItem(ctx, key, gift("r2", 1), -1); !errors.Is(err, ErrOtherRegistry) {
+		t.Fatalf("r2 import = %v, want ErrOtherRegistry", err)
+	}
+	if it, err = s.SetQuantity(ctx, key, "p1", 2); err != nil || it.RegistryUnits() != 2 {
+		t.Fatalf("SetQuantity = %+v, %v; want registry units capped at 2", it, err)
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+)
+
+var (
+	ErrRuleNotFound = errors.New("cart rule not found")
+	ErrRuleExists   = errors.New("cart rule already exists")
+)
+
+// Cart rules are stored as JSON in one hash keyed by ID.
+const cartRulesKey = "cart:rules"
+
+func (r *RedisStore) ListCartRules(ctx context.Context) ([]model.CartRule, error) {
+	res, err := r.cli.HGetAll(ctx, cartRulesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.CartRule, 0, len(res))
+	for _, v := range res {
+		var rule model.CartRule
+		if json.Unmarshal([]byte(v), &rule) == nil {
+			out = append(out, rule)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *RedisStore) SaveCartRule(ctx context.Context, rule *model.CartRule, create bool) error {
+	exists, err := r.cli.HExists(ctx, cartRulesKey, rule.ID).Result()
+	if err != nil {
+		return err
+	}
+	if create && exists {
+		return ErrRuleExists
+	}
+	if !create && !exists {
+		return ErrRuleNotFound
+	}
+
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return r.cli.HSet(ctx, cartRulesKey, rule.ID, b).Err()
+}
+
+func (r *RedisStore) DeleteCartRule(ctx context.Context, id string) error {
+	n, err := r.cli.HDel(ctx, cartRulesKey, id).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRuleNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveCartRule writes the durable copy of a cart rule.
+func (s *PGStore) SaveCartRule(ctx context.Context, rule *model.CartRule) error {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO cart_rules (id, definition) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET
+			definition = EXCLUDED.definition,
+			updated_at = now()
+	`, rule.ID, b)
+	return err
+}
+
+func (s *PGStore) DeleteCartRule(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM cart_rules WHERE id = $1`, id)
+	return err
+}
+
+func (s *PGStore) ListCartRules(ctx context.Context) ([]model.CartRule, error) {
+	rows, err := s.db.Query(ctx, `SELECT definition FROM cart_rules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.CartRule
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var rule model.CartRule
+		if json.Unmarshal(raw, &rule) == nil {
+			out = append(out, rule)
+		}
+	}
+	return out, rows.Err()
+}
+
+// RestoreCartRules loads rules into Redis only when it has none, so live
+// edits are never overwritten. It reports whether it restored anything.
+func (r *RedisStore) RestoreCartRules(ctx context.Context, rules []model.CartRule) (bool, error) {
+	if len(rules) == 0 {
+		return false, nil
+	}
+	restored := false
+	err := r.cli.Watch(ctx, func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, cartRulesKey).Result()
+		if err != nil || n > 0 {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			for _, rule := range rules {
+				b, _ := json.Marshal(rule)
+				p.HSet(ctx, cartRulesKey, rule.ID, b)
+			}
+			return nil
+		})
+		restored = err == nil
+		return err
+	}, cartRulesKey)
+	return restored, err
+}
+
+// SyncCartRules reconciles the two copies at startup, like SyncPromotions.
+func SyncCartRules(ctx context.Context, r *RedisStore, s *PGStore) error {
+	saved, err := s.ListCartRules(ctx)
+	if err != nil {
+		return err
+	}
+	restored, err := r.RestoreCartRules(ctx, saved)
+	if err != nil || restored {
+		return err
+	}
+
+	known := make(map[string]bool, len(saved))
+	for _, rule := range saved {
+		known[rule.ID] = true
+	}
+	live, err := r.ListCartRules(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range live {
+		if known[live[i].ID] {
+			continue
+		}
+		if err := s.SaveCartRule(ctx, &live[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package store
+
+import "github.com/redis/go-redis/v9"
+
+// Cart mutations run as Lua scripts so a read-modify-write of a line (and
+// the TTL refresh) happens in one step: two concurrent adds both land, and a
+// merge cannot interleave with an add. Lines are stored as CartItem JSON;
+// undecodable lines are treated as absent rather than aborting a script
+// half way, since Redis does not roll scripts back.
+//
+// Scripts reply {status, payload}: status 0 is success with the line JSON
+// (empty when the line was removed), 1 means the line is missing, 2 means
+// the quantity would exceed the limit, with the requested quantity as
+// payload, and 3 means the line already holds units for another gift
+// registry, named in the payload.
+const (
+	scriptOK            = 0
+	scriptNotFound      = 1
+	scriptTooMany       = 2
+	scriptOtherRegistry = 3
+)
+
+// lineLua is shared by the scripts that change a line's quantity. Only
+// registry_quantity of a line's units count towards registry_id; lines
+// saved before that field existed count whole.
+const lineLua = `
+local function registryQty(line)
+  if line.registry_id == nil then
+    return 0
+  end
+  return line.registry_quantity or line.quantity
+end
+
+-- addLine adds existing (may be nil) into item. Registry units add up for
+-- the same registry; it returns false if the two name different ones.
+local function addLine(item, existing)
+  if not existing then
+    return true
+  end
+  if item.registry_id == nil then
+    if existing.registry_id ~= nil then
+      item.registry_id = existing.registry_id
+      item.registry_quantity = registryQty(existing)
+    end
+  elseif existing.registry_id ~= nil and existing.registry_id ~= item.registry_id then
+    return false
+  else
+    item.registry_quantity = (item.registry_quantity or 0) + registryQty(existing)
+  end
+  item.quantity = item.quantity + existing.quantity
+  return true
+end
+
+-- capRegistry keeps a line's registry units within its quantity.
+local function capRegistry(line)
+  if line.registry_id ~= nil then
+    line.registry_quantity = math.min(registryQty(line), line.quantity)
+  end
+end
+`
+
+// addItemScript adds ARGV[2] (a CartItem) to the line for ARGV[1], summing
+// quantities and keeping the line's registry units apart from the rest.
+// ARGV[3] is the maximum resulting quantity (-1 for no limit) and ARGV[4]
+// the TTL in milliseconds.
+var addItemScript = redis.NewScript(lineLua + `
+local item = cjson.decode(ARGV[2])
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur then
+  local ok, existing = pcall(cjson.decode, cur)
+  if ok and not addLine(item, existing) then
+    return {3, existing.registry_id}
+  end
+end
+local max = tonumber(ARGV[3])
+if max >= 0 and item.quantity > max then
+  return {2, tostring(item.quantity)}
+end
+local out = cjson.encode(item)
+redis.call('HSET', KEYS[1], ARGV[1], out)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {0, out}
+`)
+
+// setQuantityScript sets the quantity of an existing line. ARGV[2] is the
+// quantity and ARGV[3] the TTL in milliseconds.
+var setQuantityScript = redis.NewScript(lineLua + `
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if not cur then
+  return {1, ''}
+end
+local ok, item = pcall(cjson.decode, cur)
+if not ok then
+  return {1, ''}
+end
+item.quantity = tonumber(ARGV[2])
+capRegistry(item)
+local out = cjson.encode(item)
+redis.call('HSET', KEYS[1], ARGV[1], out)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {0, out}
+`)
+
+// setPriceScript replaces the unit_price snapshot of an existing line.
+// ARGV[2] is the price and ARGV[3] the TTL in milliseconds.
+var setPriceScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if not cur then
+  return {1, ''}
+end
+local ok, item = pcall(cjson.decode, cur)
+if not ok then
+  return {1, ''}
+end
+item.unit_price = tonumber(ARGV[2])
+local out = cjson.encode(item)
+redis.call('HSET', KEYS[1], ARGV[1], out)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {0, out}
+`)
+
+// clampQuantityScript lowers a line to at most ARGV[2], removing it when the
+// limit is zero or less. ARGV[3] is the TTL in milliseconds.
+var clampQuantityScript = redis.NewScript(lineLua + `
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if not cur then
+  return {1, ''}
+end
+local max = tonumber(ARGV[2])
+local ok, item = pcall(cjson.decode, cur)
+if ok and item.quantity <= max then
+  return {0, cur}
+end
+if not ok or max <= 0 then
+  redis.call('HDEL', KEYS[1], ARGV[1])
+  return {0, ''}
+end
+item.quantity = max
+capRegistry(item)
+local out = cjson.encode(item)
+redis.call('HSET', KEYS[1], ARGV[1], out)
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {0, out}
+`)
+
+// mergeScript folds the guest cart in KEYS[2] into the user cart in KEYS[1].
+// ARGV[2] is the conflict policy for products in both (sum, max or
+// keep_user) and ARGV[3] a JSON object of product ID to the most stock
+// allows; guest quantities are clamped to it and lines clamped to nothing
+// are dropped. A guest line's registry units only carry over when the user
+// line has none for another registry. The wishlist in KEYS[5] folds into KEYS[4], keeping the
+// earlier entry for products saved in both, and the coupon (KEYS[7] to
+// KEYS[6]) and delivery options (KEYS[9] to KEYS[8]) carry over unless the
+// user already has them. The guest keys and notices in KEYS[3] are then
+// deleted. ARGV[1] is the TTL of the user keys in milliseconds. It replies
+// with a JSON report of what happened to each guest line.
+var mergeScript = redis.NewScript(lineLua + `
+local policy = ARGV[2]
+local lok, limits = pcall(cjson.decode, ARGV[3])
+if not lok or type(limits) ~= 'table' then
+  limits = {}
+end
+
+local lines = {}
+local src = redis.call('HGETALL', KEYS[2])
+for i = 1, #src, 2 do
+  local ok, item = pcall(cjson.decode, src[i + 1])
+  if ok then
+    local pid = src[i]
+    local line = {product_id = pid, guest_quantity = item.quantity, user_quantity = 0}
+    local existing
+    local cur = redis.call('HGET', KEYS[1], pid)
+    if cur then
+      local cok, e = pcall(cjson.decode, cur)
+      if cok then
+        existing = e
+        line.user_quantity = e.quantity
+      end
+    end
+
+    if existing and policy == 'keep_user' then
+      line.quantity = existing.quantity
+      line.result = 'kept_user'
+    else
+      local qty = item.quantity
+      local out = item
+      if existing then
+        out = existing
+        if out.registry_id == nil then
+          if item.registry_id ~= nil then
+            out.registry_id = item.registry_id
+            out.registry_quantity = registryQty(item)
+          end
+        elseif out.registry_id == item.registry_id then
+          out.registry_quantity = registryQty(existing) + registryQty(item)
+        end
+        if policy == 'max' then
+          qty = math.max(item.quantity, existing.quantity)
+        else
+          qty = item.quantity + existing.quantity
+        end
+      end
+      local limit = limits[pid]
+      if limit and qty > limit then
+        qty = math.max(limit, 0)
+        line.clamped = true
+      end
+      line.quantity = qty
+
+      if qty <= 0 then
+        redis.call('HDEL', KEYS[1], pid)
+        line.result = 'dropped'
+      else
+        out.quantity = qty
+        capRegistry(out)
+        redis.call('HSET', KEYS[1], pid, cjson.encode(out))
+        if existing then
+          line.result = 'combined'
+        else
+          line.result = 'added'
+        end
+      end
+    end
+    table.insert(lines, line)
+  end
+end
+
+local added = 0
+local saved = redis.call('HGETALL', KEYS[5])
+for i = 1, #saved, 2 do
+  added = added + redis.call('HSETNX', KEYS[4], saved[i], saved[i + 1])
+end
+
+local function carry(from, to)
+  local v = redis.call('GET', from)
+  if v and redis.call('SET', to, v, 'NX') then
+    return true
+  end
+  return false
+end
+local couponCarried = carry(KEYS[7], KEYS[6])
+local deliveryCarried = carry(KEYS[9], KEYS[8])
+
+redis.call('DEL', KEYS[2], KEYS[3], KEYS[5], KEYS[7], KEYS[9])
+for _, k in ipairs({KEYS[1], KEYS[4], KEYS[6], KEYS[8]}) do
+  if redis.call('EXISTS', k) == 1 then
+    redis.call('PEXPIRE', k, ARGV[1])
+  end
+end
+
+local report = {wishlist_added = added, coupon_carried = couponCarried, delivery_carried = deliveryCarried}
+if #lines > 0 then
+  report.lines = lines
+end
+return cjson.encode(report)
+`)
+
+// moveToCartScript moves ARGV[1] from the wishlist in KEYS[2] into the cart
+// in KEYS[1] as ARGV[2] (a CartItem), adding to any quantity already there
+// and keeping its registry units. ARGV[3] caps the resulting quantity (-1 for no cap) and ARGV[4] is the TTL
+// in milliseconds.
+var moveToCartScript = redis.NewScript(lineLua + `
+if redis.call('HEXISTS', KEYS[2], ARGV[1]) == 0 then
+  return {1, ''}
+end
+local item = cjson.decode(ARGV[2])
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur then
+  local ok, existing = pcall(cjson.decode, cur)
+  if ok and not addLine(item, existing) then
+    return {3, existing.registry_id}
+  end
+end
+local max = tonumber(ARGV[3])
+if max >= 0 and item.quantity > max then
+  return {2, tostring(item.quantity)}
+end
+local out = cjson.encode(item)
+redis.call('HSET', KEYS[1], ARGV[1], out)
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+return {0, out}
+`)
+
+// moveToWishlistScript moves the cart line ARGV[1] from KEYS[1] to the
+// wishlist in KEYS[2], saving it at its snapshot price with added_at
+// ARGV[2] unless it is already saved. ARGV[3] is the TTL in milliseconds.
+var moveToWishlistScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if not cur then
+  return {1, ''}
+end
+local ok, line = pcall(cjson.decode, cur)
+local price = 0
+if ok and line.unit_price then
+  price = line.unit_price
+end
+local saved = cjson.encode({product_id = ARGV[1], unit_price = price, added_at = ARGV[2]})
+redis.call('HSETNX', KEYS[2], ARGV[1], saved)
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return {0, ''}
+`)
+
+// redeemScript counts one use of each promotion in ARGV[3..] for the order
+// marked by KEYS[1], if every one is within its limits. KEYS[2] is the
+// promotions hash, KEYS[3] the global usage hash and KEYS[i+1] the per-user
+// usage hash of ARGV[i]. ARGV[1] is the user ID (may be empty) and ARGV[2]
+// the TTL of the marker in milliseconds. It replies {1, id} if a promotion
+// no longer exists, {2, id} if one is used up, {0, 'already'} if the order
+// was seen before and {0} with an empty payload once counted.
+var redeemScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+  return {0, 'already'}
+end
+local user = ARGV[1]
+for i = 3, #ARGV do
+  local raw = redis.call('HGET', KEYS[2], ARGV[i])
+  if not raw then
+    return {1, ARGV[i]}
+  end
+  local ok, p = pcall(cjson.decode, raw)
+  if ok then
+    local limit = tonumber(p.usage_limit) or 0
+    if limit > 0 and (tonumber(redis.call('HGET', KEYS[3], ARGV[i])) or 0) >= limit then
+      return {2, ARGV[i]}
+    end
+    local perUser = tonumber(p.per_user_limit) or 0
+    if perUser > 0 then
+      if user == '' or (tonumber(redis.call('HGET', KEYS[i + 1], user)) or 0) >= perUser then
+        return {2, ARGV[i]}
+      end
+    end
+  end
+end
+local ids = {}
+for i = 3, #ARGV do
+  redis.call('HINCRBY', KEYS[3], ARGV[i], 1)
+  if user ~= '' then
+    redis.call('HINCRBY', KEYS[i + 1], user, 1)
+  end
+  table.insert(ids, ARGV[i])
+end
+redis.call('SET', KEYS[1], cjson.encode({user_id = user, promotion_ids = ids}), 'PX', ARGV[2])
+return {0, ''}
+`)
+
+// releaseScript gives back the uses an order counted, if the marker in
+// KEYS[1] still holds ARGV[1]. KEYS[2] is the global usage hash and KEYS[i]
+// the per-user usage hash of ARGV[i] for i >= 3; ARGV[2] is the user ID. The
+// marker is kept as released so the order cannot be redeemed again. It
+// replies 1, or 0 if another call released it first.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+  return 0
+end
+for i = 3, #ARGV do
+  redis.call('HINCRBY', KEYS[2], ARGV[i], '-1')
+  if ARGV[2] ~= '' then
+    redis.call('HINCRBY', KEYS[i], ARGV[2], '-1')
+  end
+end
+redis.call('SET', KEYS[1], 'released', 'KEEPTTL')
+return 1
+`)
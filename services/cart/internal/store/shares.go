@@ -0,0 +1,289 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrShareNotFound covers unknown and expired share links alike.
+var ErrShareNotFound = errors.New("shared cart not found or has expired")
+
+// newShareID returns a random ID for a share link.
+func newShareID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateShare saves share and its lines under a new ID, filling in the ID
+// and timestamps. Snapshots expire after the configured share TTL;
+// registries stay until closed.
+func (s *PGStore) CreateShare(ctx context.Context, share *model.SharedCart) error {
+	id, err := newShareID()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var ttlSecs *float64
+	if share.Kind == model.ShareSnapshot {
+		secs := s.shareTTL.Seconds()
+		ttlSecs = &secs
+	}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO cart_shares (
+			id, kind, owner_key, owner_id, title, message, event_date, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, now() + make_interval(secs => $8))
+		RETURNING created_at, updated_at, expires_at
+	`,
+		id,
+		share.Kind,
+		share.OwnerKey,
+		share.OwnerID,
+		share.Title,
+		share.Message,
+		share.EventDate,
+		ttlSecs,
+	).Scan(&share.CreatedAt, &share.UpdatedAt, &share.ExpiresAt); err != nil {
+		return err
+	}
+
+	for i, it := range share.Items {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO cart_share_items (share_id, product_id, quantity, unit_price_cents, position)
+			VALUES ($1, $2, $3, $4, $5)
+		`, id, it.ProductID, it.Quantity, it.UnitPrice, i); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	share.ID = id
+	return nil
+}
+
+const shareColumns = `
+	id, kind, owner_key, owner_id, title, message, event_date,
+	created_at, updated_at, expires_at, closed_at`
+
+func scanShare(row pgx.Row) (*model.SharedCart, error) {
+	var sh model.SharedCart
+	if err := row.Scan(
+		&sh.ID,
+		&sh.Kind,
+		&sh.OwnerKey,
+		&sh.OwnerID,
+		&sh.Title,
+		&sh.Message,
+		&sh.EventDate,
+		&sh.CreatedAt,
+		&sh.UpdatedAt,
+		&sh.ExpiresAt,
+		&sh.ClosedAt,
+	); err != nil {
+		return nil, err
+	}
+	sh.Items = []model.SharedItem{}
+	return &sh, nil
+}
+
+// GetShare returns a live share with its lines in the order they were
+// shared. Closed shares are still returned.
+func (s *PGStore) GetShare(ctx context.Context, id string) (*model.SharedCart, error) {
+	row := s.db.QueryRow(ctx, `
+		SELECT `+shareColumns+`
+		FROM cart_shares
+		WHERE id = $1 AND (expires_at IS NULL OR expires_at > now())
+	`, id)
+	sh, err := scanShare(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadShareItems(ctx, map[string]*model.SharedCart{sh.ID: sh}); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// ListShares returns the live shares made from a cart, newest first.
+func (s *PGStore) ListShares(ctx context.Context, ownerKey string) ([]model.SharedCart, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT `+shareColumns+`
+		FROM cart_shares
+		WHERE owner_key = $1 AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY created_at DESC
+		LIMIT 100
+	`, ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []*model.SharedCart
+	byID := map[string]*model.SharedCart{}
+	for rows.Next() {
+		sh, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, sh)
+		byID[sh.ID] = sh
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.loadShareItems(ctx, byID); err != nil {
+		return nil, err
+	}
+
+	out := make([]model.SharedCart, 0, len(shares))
+	for _, sh := range shares {
+		out = append(out, *sh)
+	}
+	return out, nil
+}
+
+// loadShareItems fills in the lines of the given shares in one query.
+func (s *PGStore) loadShareItems(ctx context.Context, shares map[string]*model.SharedCart) error {
+	if len(shares) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(shares))
+	for id := range shares {
+		ids = append(ids, id)
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT share_id, product_id, quantity, unit_price_cents, purchased
+		FROM cart_share_items
+		WHERE share_id = ANY($1)
+		ORDER BY share_id, position, product_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shareID string
+		var it model.SharedItem
+		if err := rows.Scan(&shareID, &it.ProductID, &it.Quantity, &it.UnitPrice, &it.Purchased); err != nil {
+			return err
+		}
+		if sh := shares[shareID]; sh != nil {
+			sh.Items = append(sh.Items, it)
+		}
+	}
+	return rows.Err()
+}
+
+// SetShareItem sets how many of a product a registry asks for, adding the
+// line at the end if it is new. A quantity of zero removes the line; what
+// was already bought for it is forgotten with it.
+func (s *PGStore) SetShareItem(ctx context.Context, id, productID string, qty int, unitPrice int64) error {
+	var err error
+	if qty <= 0 {
+		_, err = s.db.Exec(ctx, `
+			DELETE FROM cart_share_items WHERE share_id = $1 AND product_id = $2
+		`, id, productID)
+	} else {
+		_, err = s.db.Exec(ctx, `
+			INSERT INTO cart_share_items (share_id, product_id, quantity, unit_price_cents, position)
+			VALUES ($1, $2, $3, $4, (
+				SELECT COALESCE(MAX(position) + 1, 0) FROM cart_share_items WHERE share_id = $1
+			))
+			ON CONFLICT (share_id, product_id) DO UPDATE SET
+				quantity = EXCLUDED.quantity,
+				unit_price_cents = EXCLUDED.unit_price_cents
+		`, id, productID, qty, unitPrice)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `UPDATE cart_shares SET updated_at = now() WHERE id = $1`, id)
+	return err
+}
+
+func (s *PGStore) CloseShare(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE cart_shares SET closed_at = COALESCE(closed_at, now()), updated_at = now()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// RecordRegistryPurchases adds a paid order's registry lines to what each
+// registry has had bought. Each line counts at most what the registry still
+// wanted. Lines already recorded for the order, and lines no longer on a
+// registry, are ignored, so repeat calls are safe.
+func (s *PGStore) RecordRegistryPurchases(ctx context.Context, orderID string, buyerID *string, items []model.RegistryPurchase) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	recorded := 0
+	for _, it := range items {
+		var wanted int
+		err := tx.QueryRow(ctx, `
+			SELECT GREATEST(i.quantity - i.purchased, 0)
+			FROM cart_share_items i
+			JOIN cart_shares sh ON sh.id = i.share_id
+			WHERE i.share_id = $1 AND i.product_id = $2 AND sh.kind = 'registry'
+			FOR UPDATE OF i
+		`, it.RegistryID, it.ProductID).Scan(&wanted)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		qty := min(it.Quantity, wanted)
+		if qty <= 0 {
+			continue
+		}
+
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO cart_registry_purchases (order_id, share_id, product_id, quantity, buyer_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (order_id, share_id, product_id) DO NOTHING
+		`, orderID, it.RegistryID, it.ProductID, qty, buyerID)
+		if err != nil {
+			return 0, err
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE cart_share_items SET purchased = purchased + $3
+			WHERE share_id = $1 AND product_id = $2
+		`, it.RegistryID, it.ProductID, qty); err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE cart_shares SET updated_at = now() WHERE id = $1
+		`, it.RegistryID); err != nil {
+			return 0, err
+		}
+		recorded++
+	}
+	return recorded, tx.Commit(ctx)
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// wishlistKey is the wishlist belonging to a cart key. It shares the cart's
+// TTL.
+func wishlistKey(key string) string { return "wishlist:" + key }
+
+// AddWishlist saves a product. Saving it again keeps the original entry and
+// reports false.
+func (r *RedisStore) AddWishlist(ctx context.Context, key string, item model.WishlistItem) (bool, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return false, err
+	}
+	wk := wishlistKey(key)
+	var added *redis.BoolCmd
+	_, err = r.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		added = p.HSetNX(ctx, wk, item.ProductID, b)
+		p.PExpire(ctx, wk, r.ttlFor(key))
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return added.Val(), nil
+}
+
+func (r *RedisStore) RemoveWishlist(ctx context.Context, key, productID string) error {
+	n, err := r.cli.HDel(ctx, wishlistKey(key), productID).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+func (r *RedisStore) GetWishlist(ctx context.Context, key string) ([]model.WishlistItem, error) {
+	res, err := r.cli.HGetAll(ctx, wishlistKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.WishlistItem, 0, len(res))
+	for _, v := range res {
+		var it model.WishlistItem
+		if json.Unmarshal([]byte(v), &it) == nil {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+// MoveToCart moves a saved product into the cart as item, adding to any
+// quantity already there. maxQty caps the resulting quantity (-1 for no
+// cap). It returns ErrItemNotFound if the product is not saved and
+// ErrInsufficientStock if the cap would be exceeded; either way nothing
+// changes.
+func (r *RedisStore) MoveToCart(ctx context.Context, key string, item model.CartItem, maxQty int) (*model.CartItem, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	res, err := moveToCartScript.Run(ctx, r.cli, []string{key, wishlistKey(key)},
+		item.ProductID, string(b), maxQty, r.ttlFor(key).Milliseconds()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	return decodeItemReply(res)
+}
+
+// MoveToWishlist moves a cart line to the wishlist at its snapshot price.
+// It returns ErrItemNotFound if the line is not in the cart.
+func (r *RedisStore) MoveToWishlist(ctx context.Context, key, productID string) error {
+	res, err := moveToWishlistScript.Run(ctx, r.cli, []string{key, wishlistKey(key)},
+		productID, time.Now().UTC().Format(time.RFC3339Nano), r.ttlFor(key).Milliseconds()).Slice()
+	if err != nil {
+		return err
+	}
+	_, err = decodeItemReply(res)
+	return err
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/notify"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+)
+
+// abandonBatch bounds how many carts one tick flags.
+const abandonBatch = 200
+
+type AbandonConfig struct {
+	// Stages are idle times after which a cart counts as abandoned again,
+	// one reminder each, e.g. 1h, 24h, 72h.
+	Stages []time.Duration
+	// TokenTTL is how long a recovery link works.
+	TokenTTL time.Duration
+	// RecoveryURL is the storefront page that redeems ?token=.
+	RecoveryURL string
+	Interval    time.Duration
+}
+
+// AbandonConfigFromEnv reads CART_ABANDON_AFTER (comma-separated
+// durations), CART_RECOVERY_TOKEN_TTL, CART_RECOVERY_URL and
+// CART_ABANDON_INTERVAL.
+func AbandonConfigFromEnv() (AbandonConfig, error) {
+	cfg := AbandonConfig{
+		Stages:      []time.Duration{time.Hour, 24 * time.Hour, 72 * time.Hour},
+		TokenTTL:    7 * 24 * time.Hour,
+		RecoveryURL: "http://localhost:3000/cart/recover",
+		Interval:    5 * time.Minute,
+	}
+
+	if v := os.Getenv("CART_ABANDON_AFTER"); v != "" {
+		cfg.Stages = nil
+		for _, part := range strings.Split(v, ",") {
+			d, err := time.ParseDuration(strings.TrimSpace(part))
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("CART_ABANDON_AFTER: bad duration %q", part)
+			}
+			if n := len(cfg.Stages); n > 0 && d <= cfg.Stages[n-1] {
+				return cfg, fmt.Errorf("CART_ABANDON_AFTER: stages must increase")
+			}
+			cfg.Stages = append(cfg.Stages, d)
+		}
+	}
+	for env, dst := range map[string]*time.Duration{
+		"CART_RECOVERY_TOKEN_TTL": &cfg.TokenTTL,
+		"CART_ABANDON_INTERVAL":   &cfg.Interval,
+	} {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("%s: bad duration %q", env, v)
+			}
+			*dst = d
+		}
+	}
+	if v := os.Getenv("CART_RECOVERY_URL"); v != "" {
+		cfg.RecoveryURL = v
+	}
+	return cfg, nil
+}
+
+// AbandonWorker flags carts idle past each configured stage, records a
+// cart.abandoned event and, for signed-in shoppers who have not opted out,
+// sends a reminder with a recovery link. Guest carts are flagged for
+// reporting but have nobody to remind.
+type AbandonWorker struct {
+	pg     *store.PGStore
+	sender notify.Sender
+	cfg    AbandonConfig
+}
+
+func NewAbandonWorker(pg *store.PGStore, sender notify.Sender, cfg AbandonConfig) *AbandonWorker {
+	return &AbandonWorker{
+		pg:     pg,
+		sender: sender,
+		cfg:    cfg,
+	}
+}
+
+func (w *AbandonWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.detect(ctx)
+		}
+	}
+}
+
+func (w *AbandonWorker) detect(ctx context.Context) {
+	due, err := w.pg.DueForReminder(ctx, w.cfg.Stages, abandonBatch)
+	if err != nil {
+		log.Println("abandoned carts:", err)
+		return
+	}
+
+	for _, snap := range due {
+		stage := snap.RemindersSent + 1
+		ok, err := w.pg.MarkAbandoned(ctx, snap.CartKey, stage)
+		if err != nil {
+			log.Println("abandoned carts:", snap.CartKey, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		e := notify.Event{
+			Type:       model.EventCartAbandoned,
+			CartKey:    snap.CartKey,
+			Stage:      stage,
+			Items:      snap.Items,
+			Subtotal:   snap.Subtotal,
+			Currency:   "INR",
+			OccurredAt: time.Now().UTC(),
+		}
+		if snap.UserID != nil {
+			e.UserID = *snap.UserID
+		}
+
+		if err := w.pg.RecordEvent(ctx, snap.CartKey, e.Type, map[string]any{"stage": stage}); err != nil {
+			log.Println("abandoned carts:", snap.CartKey, err)
+		}
+		if e.UserID == "" {
+			continue
+		}
+		if optedOut, err := w.pg.IsOptedOut(ctx, e.UserID); err != nil || optedOut {
+			if err != nil {
+				log.Println("abandoned carts:", snap.CartKey, err)
+			}
+			continue
+		}
+
+		token, hash, err := store.NewRecoveryToken()
+		if err == nil {
+			err = w.pg.CreateRecoveryToken(ctx, snap.CartKey, hash, time.Now().Add(w.cfg.TokenTTL))
+		}
+		if err != nil {
+			log.Println("abandoned carts:", snap.CartKey, err)
+			continue
+		}
+		e.RecoveryURL = w.cfg.RecoveryURL + "?token=" + url.QueryEscape(token)
+
+		if err := w.sender.Send(ctx, e); err != nil {
+			log.Println("abandoned cart reminder:", snap.CartKey, err)
+		}
+	}
+}
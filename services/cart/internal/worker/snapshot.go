@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-cart/internal/model"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+)
+
+// snapshotBatch bounds how many changed carts one tick copies.
+const snapshotBatch = 500
+
+// SnapshotWorker copies carts changed since the last tick from Redis to
+// Postgres, so they outlive their Redis TTL.
+type SnapshotWorker struct {
+	redis    *store.RedisStore
+	pg       *store.PGStore
+	interval time.Duration
+}
+
+func NewSnapshotWorker(r *store.RedisStore, pg *store.PGStore, interval time.Duration) *SnapshotWorker {
+	return &SnapshotWorker{
+		redis:    r,
+		pg:       pg,
+		interval: interval,
+	}
+}
+
+func (w *SnapshotWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.snapshot(ctx)
+		}
+	}
+}
+
+func (w *SnapshotWorker) snapshot(ctx context.Context) {
+	changed, err := w.redis.ChangedCarts(ctx, snapshotBatch)
+	if err != nil {
+		log.Println("cart snapshot:", err)
+		return
+	}
+
+	saved := 0
+	for _, c := range changed {
+		items, err := w.redis.GetAll(ctx, c.Key)
+		if err != nil {
+			log.Println("cart snapshot:", c.Key, err)
+			continue
+		}
+
+		snap := model.CartSnapshot{
+			CartKey:        c.Key,
+			Items:          make([]model.CartItem, 0, len(items)),
+			LastActivityAt: c.Changed,
+		}
+		if uid, ok := strings.CutPrefix(c.Key, "user:"); ok {
+			snap.UserID = &uid
+		}
+		for _, it := range items {
+			snap.Items = append(snap.Items, it)
+			snap.Subtotal += it.UnitPrice * int64(it.Quantity)
+			snap.ItemCount += it.Quantity
+		}
+
+		if err := w.pg.UpsertSnapshot(ctx, snap); err != nil {
+			log.Println("cart snapshot:", c.Key, err)
+			continue
+		}
+		if err := w.redis.SnapshotDone(ctx, c); err != nil {
+			log.Println("cart snapshot:", c.Key, err)
+		}
+		saved++
+	}
+	if saved > 0 {
+		log.Printf("cart snapshots saved: %d", saved)
+	}
+}
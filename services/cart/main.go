@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/devmanishoffl/sabhyatam-cart/internal/api"
 	"github.com/devmanishoffl/sabhyatam-cart/internal/client"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/notify"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/pricing"
 	"github.com/devmanishoffl/sabhyatam-cart/internal/store"
+	"github.com/devmanishoffl/sabhyatam-cart/internal/worker"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -27,7 +32,51 @@ func main() {
 		log.Fatal("redis:", err)
 	}
 	pc := client.NewProductClientFromEnv()
-	h := api.NewHandler(rs, pc)
+	rules, err := pricing.RulesFromEnv()
+	if err != nil {
+		log.Fatal("pricing rules:", err)
+	}
+	mergePolicy, err := api.ParseMergePolicy(os.Getenv("CART_MERGE_POLICY"))
+	if err != nil {
+		log.Fatal("merge policy:", err)
+	}
+	sender, err := notify.NewSenderFromEnv()
+	if err != nil {
+		log.Fatal("notifier:", err)
+	}
+
+	ctx := context.Background()
+
+	// Postgres is optional: without DATABASE_URL carts live in Redis only,
+	// abandoned-cart recovery is off and promotions and cart rules have no
+	// durable copy.
+	pg, err := store.NewPGFromEnv(ctx)
+	if err != nil {
+		log.Fatal("postgres:", err)
+	}
+	if pg != nil {
+		if err := store.SyncPromotions(ctx, rs, pg); err != nil {
+			log.Fatal("promotions:", err)
+		}
+		if err := store.SyncCartRules(ctx, rs, pg); err != nil {
+			log.Fatal("cart rules:", err)
+		}
+
+		snapEvery := time.Minute
+		if v := os.Getenv("CART_SNAPSHOT_INTERVAL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				snapEvery = d
+			}
+		}
+		abandonCfg, err := worker.AbandonConfigFromEnv()
+		if err != nil {
+			log.Fatal("abandoned carts:", err)
+		}
+		go worker.NewSnapshotWorker(rs, pg, snapEvery).Run(ctx)
+		go worker.NewAbandonWorker(pg, sender, abandonCfg).Run(ctx)
+	}
+
+	h := api.NewHandler(rs, pg, pc, client.NewAuthClientFromEnv(), client.NewOrdersClientFromEnv(), sender, rules, mergePolicy)
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
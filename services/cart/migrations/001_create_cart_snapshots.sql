@@ -0,0 +1,47 @@
+-- Durable copies of Redis carts, for abandoned-cart recovery.
+CREATE TABLE IF NOT EXISTS cart_snapshots (
+  cart_key TEXT PRIMARY KEY,
+  user_id TEXT,
+  items JSONB NOT NULL DEFAULT '[]'::jsonb,
+  subtotal_cents BIGINT NOT NULL DEFAULT 0,
+  item_count INT NOT NULL DEFAULT 0,
+  last_activity_at TIMESTAMPTZ NOT NULL,
+  status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'abandoned', 'recovered', 'emptied')),
+  reminders_sent INT NOT NULL DEFAULT 0,
+  abandoned_at TIMESTAMPTZ,
+  recovered_at TIMESTAMPTZ,
+  snapshotted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_snapshots_due
+  ON cart_snapshots (last_activity_at)
+  WHERE status IN ('active', 'abandoned') AND item_count > 0;
+
+CREATE INDEX IF NOT EXISTS idx_cart_snapshots_user ON cart_snapshots (user_id);
+
+-- Only a hash of each token is kept; the token itself is in the link.
+CREATE TABLE IF NOT EXISTS cart_recovery_tokens (
+  token_hash TEXT PRIMARY KEY,
+  cart_key TEXT NOT NULL REFERENCES cart_snapshots(cart_key) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_recovery_tokens_cart ON cart_recovery_tokens (cart_key);
+
+CREATE TABLE IF NOT EXISTS cart_events (
+  id BIGSERIAL PRIMARY KEY,
+  cart_key TEXT NOT NULL,
+  type TEXT NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_events_cart ON cart_events (cart_key, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS cart_reminder_optouts (
+  user_id TEXT PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
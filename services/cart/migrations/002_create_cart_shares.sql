@@ -0,0 +1,38 @@
+-- Carts shared at a link: read-only snapshots and gift registries.
+CREATE TABLE IF NOT EXISTS cart_shares (
+  id TEXT PRIMARY KEY,
+  kind TEXT NOT NULL CHECK (kind IN ('snapshot', 'registry')),
+  owner_key TEXT NOT NULL,
+  owner_id TEXT,
+  title TEXT NOT NULL DEFAULT '',
+  message TEXT NOT NULL DEFAULT '',
+  event_date DATE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ,
+  closed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_shares_owner ON cart_shares (owner_key, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS cart_share_items (
+  share_id TEXT NOT NULL REFERENCES cart_shares(id) ON DELETE CASCADE,
+  product_id TEXT NOT NULL,
+  quantity INT NOT NULL CHECK (quantity > 0),
+  unit_price_cents BIGINT NOT NULL DEFAULT 0,
+  purchased INT NOT NULL DEFAULT 0,
+  position INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (share_id, product_id)
+);
+
+-- One row per registry line per paid order, so repeat reports from the
+-- orders service are not counted twice.
+CREATE TABLE IF NOT EXISTS cart_registry_purchases (
+  order_id TEXT NOT NULL,
+  share_id TEXT NOT NULL REFERENCES cart_shares(id) ON DELETE CASCADE,
+  product_id TEXT NOT NULL,
+  quantity INT NOT NULL CHECK (quantity > 0),
+  buyer_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (order_id, share_id, product_id)
+);
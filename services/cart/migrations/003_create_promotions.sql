@@ -0,0 +1,21 @@
+-- Durable copies of promotions and their redemptions. Redis serves carts
+-- and enforces limits; these rebuild it if Redis loses them.
+CREATE TABLE IF NOT EXISTS cart_promotions (
+  id TEXT PRIMARY KEY,
+  definition JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One row per promotion counted for an order; rows are deleted when an
+-- unpaid order is cancelled and its uses are given back.
+CREATE TABLE IF NOT EXISTS cart_promotion_redemptions (
+  order_id TEXT NOT NULL,
+  promotion_id TEXT NOT NULL,
+  user_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (order_id, promotion_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cart_promotion_redemptions_promo
+  ON cart_promotion_redemptions (promotion_id, user_id);
@@ -0,0 +1,7 @@
+-- Durable copies of cart rules; Redis serves them to carts.
+CREATE TABLE IF NOT EXISTS cart_rules (
+  id TEXT PRIMARY KEY,
+  definition JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+-- A reminder link may unsubscribe its cart's owner once.
+ALTER TABLE cart_recovery_tokens
+ADD COLUMN IF NOT EXISTS opt_out_used_at TIMESTAMPTZ;
@@ -0,0 +1,8 @@
+-- A cart whose order was paid is converted: no more reminders for it
+-- until it changes again.
+ALTER TABLE cart_snapshots
+DROP CONSTRAINT IF EXISTS cart_snapshots_status_check;
+
+ALTER TABLE cart_snapshots
+ADD CONSTRAINT cart_snapshots_status_check
+  CHECK (status IN ('active', 'abandoned', 'recovered', 'emptied', 'converted'));
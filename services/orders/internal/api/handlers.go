@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"os"
@@ -10,6 +12,7 @@ import (
 	"github.com/devmanishoffl/sabhyatam-orders/internal/client"
 	"github.com/devmanishoffl/sabhyatam-orders/internal/model"
 	"github.com/devmanishoffl/sabhyatam-orders/internal/store"
+	"github.com/devmanishoffl/sabhyatam-orders/internal/worker"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -37,6 +40,13 @@ func isValidUUID(id string) bool {
 	return err == nil
 }
 
+// isInternal reports whether r carries the shared INTERNAL_SERVICE_KEY. An
+// unset key never matches.
+func isInternal(r *http.Request) bool {
+	key := os.Getenv("INTERNAL_SERVICE_KEY")
+	return key != "" && r.Header.Get("X-INTERNAL-KEY") == key
+}
+
 // PrepareOrder reserves stock and creates a pending order from the user's cart
 func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -51,13 +61,15 @@ func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var (
-		cart *client.CartResponse
-		err  error
+		cart    *client.CartResponse
+		cartKey string // which cart the order comes from, as the cart service keys it
+		err     error
 	)
 
 	// 1. Logic: Try User Cart First
 	if userID != "" {
 		cart, err = h.cartClient.GetCartForUser(ctx, userID)
+		cartKey = "user:" + userID
 		// 2. Logic: If User Cart is empty, TRY SESSION CART
 		if err != nil || cart == nil || len(cart.Items) == 0 {
 			if sessionID != "" {
@@ -65,6 +77,7 @@ func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 				sessionCart, sErr := h.cartClient.GetCartForSession(ctx, sessionID)
 				if sErr == nil && sessionCart != nil && len(sessionCart.Items) > 0 {
 					cart = sessionCart
+					cartKey = "session:" + sessionID
 					err = nil
 				}
 			}
@@ -72,6 +85,7 @@ func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 	} else {
 		// 3. Logic: No User ID, just check Session
 		cart, err = h.cartClient.GetCartForSession(ctx, sessionID)
+		cartKey = "session:" + sessionID
 	}
 
 	if err != nil {
@@ -84,8 +98,27 @@ func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if cart.RequiresAcknowledgement {
+		http.Error(w, "cart has changed; review and acknowledge it before checkout", http.StatusConflict)
+		return
+	}
+
+	if len(cart.Violations) > 0 {
+		v := cart.Violations[0]
+		http.Error(w, v.Message+" ("+v.Code+")", http.StatusUnprocessableEntity)
+		return
+	}
+
+	for _, it := range cart.Items {
+		if it.Unavailable {
+			http.Error(w, "some cart items are temporarily unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
 	var (
 		orderItems []model.OrderItem
+		promotions []model.OrderPromotion
 		totalCents int64
 	)
 
@@ -97,29 +130,99 @@ func (h *Handler) PrepareOrder(w http.ResponseWriter, r *http.Request) {
 			ProductID:  it.Product.ID,
 			Quantity:   it.Quantity,
 			PriceCents: it.UnitPrice,
+			RegistryID:       it.RegistryID,
+			RegistryQuantity: it.RegistryQuantity,
 		})
 		totalCents += it.UnitPrice * int64(it.Quantity)
 	}
 
+	// Discounts were computed by the cart against the same lines.
+	for _, d := range cart.Discounts {
+		if d.Amount <= 0 {
+			continue
+		}
+		promotions = append(promotions, model.OrderPromotion{
+			PromotionID: d.PromotionID,
+			Code:        d.Code,
+			Description: d.Description,
+			AmountCents: d.Amount,
+		})
+		totalCents -= d.Amount
+	}
+
 	if totalCents <= 0 {
 		http.Error(w, "invalid order total", http.StatusBadRequest)
 		return
 	}
 
+	// Delivery and COD charges come on top; GST is already in the prices.
+	charges := model.OrderCharges{TaxCents: cart.Tax.Total}
+	for _, c := range cart.Charges {
+		charges.ShippingCents += c.Amount
+	}
+	totalCents += charges.ShippingCents
+
+	// Re-check availability in one lookup so a stale cart fails here rather
+	// than after the draft order exists.
+	ids := make([]string, 0, len(orderItems))
+	for _, it := range orderItems {
+		ids = append(ids, it.ProductID)
+	}
+	products, err := h.pclient.GetProducts(ctx, ids)
+	if err != nil {
+		http.Error(w, "failed to verify products: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	for _, it := range orderItems {
+		p, ok := products[it.ProductID]
+		if !ok || !p.Published {
+			http.Error(w, "product no longer available: "+it.ProductID, http.StatusConflict)
+			return
+		}
+		if p.Stock < it.Quantity {
+			http.Error(w, "insufficient stock for "+p.Title, http.StatusConflict)
+			return
+		}
+	}
+
 	var uid *string
 	if userID != "" {
 		uid = &userID
 	}
 
-	orderID, err := h.store.CreateDraftOrder(ctx, uid, orderItems, totalCents)
+	orderID, err := h.store.CreateDraftOrder(ctx, uid, cartKey, orderItems, totalCents, promotions, charges)
 	if err != nil {
 		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Count the promotions against their limits before holding stock; the
+	// cart checks and counts atomically, so a capped offer cannot be
+	// oversubscribed by concurrent checkouts.
+	if len(promotions) > 0 {
+		ids := make([]string, 0, len(promotions))
+		for _, p := range promotions {
+			ids = append(ids, p.PromotionID)
+		}
+		if err := h.cartClient.RedeemPromotions(ctx, orderID, uid, ids); err != nil {
+			_ = h.store.UpdateOrderStatus(ctx, orderID, "cancelled")
+			if errors.Is(err, client.ErrPromotionUnavailable) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, "failed to apply offers: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		if err := h.store.MarkPromotionsRedeemed(ctx, orderID); err != nil {
+			log.Println("mark promotions redeemed:", err)
+		}
+	}
+
 	// 3. Reserve Stock
 	for _, it := range orderItems {
 		if err := h.pclient.ReserveStock(ctx, it.ProductID, it.Quantity); err != nil {
+			_ = h.store.UpdateOrderStatus(ctx, orderID, "cancelled")
+			h.releasePromotions(ctx, orderID)
 			http.Error(w, "stock reservation failed", http.StatusConflict)
 			return
 		}
@@ -200,6 +303,8 @@ func (h *Handler) MarkOrderPaid(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if order.Status == string(model.StatusPaid) {
+		h.recordRegistryPurchases(ctx, order)
+		h.markCartConverted(ctx, order.ID)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -216,6 +321,8 @@ func (h *Handler) MarkOrderPaid(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to update order", http.StatusInternalServerError)
 		return
 	}
+	h.recordRegistryPurchases(ctx, order)
+	h.markCartConverted(ctx, order.ID)
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -251,6 +358,7 @@ func (h *Handler) ReleaseOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	_ = h.store.UpdateOrderStatus(ctx, orderID, "cancelled")
+	h.releasePromotions(ctx, orderID)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -316,10 +424,42 @@ func (h *Handler) ConfirmOrder(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to update order", http.StatusInternalServerError)
 		return
 	}
+	h.recordRegistryPurchases(ctx, order)
+	h.markCartConverted(ctx, order.ID)
 
 	_ = json.NewEncoder(w).Encode(map[string]string{"status": "paid"})
 }
 
+// releasePromotions gives back the promotion uses of a cancelled order.
+// Failures are logged; the reconcile worker retries them.
+func (h *Handler) releasePromotions(ctx context.Context, orderID string) {
+	if err := h.cartClient.ReleasePromotions(ctx, orderID); err != nil {
+		log.Println("release promotions for order", orderID+":", err)
+		return
+	}
+	if err := h.store.MarkPromotionsReleased(ctx, orderID); err != nil {
+		log.Println("mark promotions released:", err)
+	}
+}
+
+// recordRegistryPurchases reports a paid order's gift registry lines to
+// the cart service so the registries show them as bought. Failures are
+// logged; the reconcile worker retries them.
+func (h *Handler) recordRegistryPurchases(ctx context.Context, order *model.Order) {
+	if err := worker.RecordRegistryPurchases(ctx, h.store, h.cartClient, order); err != nil {
+		log.Println("record registry purchases for order", order.ID+":", err)
+	}
+}
+
+// markCartConverted tells the cart service a paid order's cart was checked
+// out, so it sends no abandonment reminders for it. Failures are logged;
+// the reconcile worker retries them.
+func (h *Handler) markCartConverted(ctx context.Context, orderID string) {
+	if err := worker.MarkCartConverted(ctx, h.store, h.cartClient, orderID); err != nil {
+		log.Println("mark cart converted for order", orderID+":", err)
+	}
+}
+
 func (h *Handler) CreateOrderFromCart(w http.ResponseWriter, r *http.Request) {
 	h.PrepareOrder(w, r)
 }
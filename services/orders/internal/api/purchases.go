@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// maxPurchaseLookup bounds the product IDs in one purchased-quantities call.
+const maxPurchaseLookup = 100
+
+// PurchasedQuantities tells the cart service how many of each product
+// X-USER-ID has ordered, for per-customer purchase limits:
+// ?product_ids=a,b → {"quantities": {"a": 1}}. Internal only.
+func (h *Handler) PurchasedQuantities(w http.ResponseWriter, r *http.Request) {
+	if !isInternal(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := r.Header.Get("X-USER-ID")
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("product_ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			if !isValidUUID(id) {
+				http.Error(w, "invalid product id", http.StatusBadRequest)
+				return
+			}
+			ids = append(ids, id)
+		}
+	}
+	if userID == "" || len(ids) == 0 || len(ids) > maxPurchaseLookup {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	quantities, err := h.store.PurchasedQuantities(r.Context(), userID, ids)
+	if err != nil {
+		http.Error(w, "error checking purchases", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"quantities": quantities,
+	})
+}
@@ -29,3 +29,31 @@ func (h *Handler) CheckReviewEligibility(w http.ResponseWriter, r *http.Request)
 		"eligible": ok,
 	})
 }
+
+// CheckProductPurchase tells the reviews service whether X-USER-ID bought a
+// product, so it can mark Q&A answers as coming from a verified buyer.
+// Internal only: it reveals purchase history.
+func (h *Handler) CheckProductPurchase(w http.ResponseWriter, r *http.Request) {
+	if !isInternal(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	productID := chi.URLParam(r, "productID")
+	userID := r.Header.Get("X-USER-ID")
+
+	if !isValidUUID(productID) || userID == "" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	ok, err := h.store.HasPurchasedProduct(r.Context(), userID, productID)
+	if err != nil {
+		http.Error(w, "error checking purchase", http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]bool{
+		"purchased": ok,
+	})
+}
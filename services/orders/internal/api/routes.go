@@ -22,6 +22,11 @@ func RegisterRoutes(r *chi.Mux, h *Handler) {
 			"/internal/order-items/{orderItemID}/review-eligibility",
 			h.CheckReviewEligibility,
 		)
+		r.Get(
+			"/internal/products/{productID}/purchase-check",
+			h.CheckProductPurchase,
+		)
+		r.Get("/internal/purchased-quantities", h.PurchasedQuantities)
 		// 2. Single Order Routes
 		// IMPORTANT: We use {id} here because handlers.go uses chi.URLParam(r, "id")
 		r.Route("/{id}", func(r chi.Router) {
@@ -1,17 +1,40 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
 type CartResponse struct {
 	Items    []CartItem `json:"items"`
 	Subtotal int64      `json:"subtotal"`
+
+	Discounts     []CartDiscount `json:"discounts"`
+	DiscountTotal int64          `json:"discount_total"`
+	Total         int64          `json:"total"`
+
+	// Tax is included in Total; Charges are added on top of it.
+	Tax struct {
+		Total int64 `json:"total"`
+	} `json:"tax"`
+	Charges    []CartCharge `json:"charges"`
+	GrandTotal int64        `json:"grand_total"`
+
+	// Violations are purchase rules the cart breaks; checkout is refused
+	// while there are any.
+	Violations []CartViolation `json:"violations"`
+
+	// RequiresAcknowledgement is set while the cart has price, stock or
+	// availability notices the shopper has not accepted yet.
+	RequiresAcknowledgement bool `json:"requires_acknowledgement"`
 }
 
 type CartItem struct {
@@ -27,6 +50,32 @@ type CartItem struct {
 	UnitPrice int64 `json:"unit_price"`
 	Quantity  int   `json:"quantity"`
 	LineTotal int64 `json:"line_total"`
+
+	// Unavailable lines could not be hydrated by the cart service.
+	Unavailable bool `json:"unavailable"`
+
+	// RegistryID is set on lines bought from a gift registry, and
+	// RegistryQuantity is how many of the line's units are for it.
+	RegistryID       string `json:"registry_id"`
+	RegistryQuantity int    `json:"registry_quantity"`
+}
+
+type CartDiscount struct {
+	PromotionID string `json:"promotion_id"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
+
+type CartCharge struct {
+	Type   string `json:"type"`
+	Label  string `json:"label"`
+	Amount int64  `json:"amount"`
+}
+
+type CartViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type CartClient struct {
@@ -44,7 +93,9 @@ func NewCartClient() *CartClient {
 
 func (c *CartClient) GetCartForUser(ctx context.Context, userID string) (*CartResponse, error) {
 	req, _ := http.NewRequestWithContext(ctx, "GET", c.base+"/v1/cart/", nil)
+	// The cart only trusts X-USER-ID alongside the internal key.
 	req.Header.Set("X-USER-ID", userID)
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
 
 	resp, err := c.c.Do(req)
 	if err != nil {
@@ -85,3 +136,115 @@ func (c *CartClient) GetCartForSession(ctx context.Context, sessionID string) (*
 	}
 	return &out, nil
 }
+
+// ErrPromotionUnavailable means an order's promotion is used up or gone,
+// so the order must not be placed at that price.
+var ErrPromotionUnavailable = errors.New("offer is no longer available")
+
+// RedeemPromotions counts one use of each promotion for an order being
+// placed. The cart service ignores repeat calls for the same order.
+func (c *CartClient) RedeemPromotions(ctx context.Context, orderID string, userID *string, promotionIDs []string) error {
+	body := map[string]any{
+		"order_id":      orderID,
+		"promotion_ids": promotionIDs,
+	}
+	if userID != nil {
+		body["user_id"] = *userID
+	}
+	b, _ := json.Marshal(body)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.base+"/internal/promotions/redeem", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w (%s)", ErrPromotionUnavailable, strings.TrimSpace(string(msg)))
+	}
+	return fmt.Errorf("cart returned %d", resp.StatusCode)
+}
+
+// ReleasePromotions gives back the uses counted for an order cancelled
+// before payment. The cart service ignores repeat calls.
+func (c *CartClient) ReleasePromotions(ctx context.Context, orderID string) error {
+	b, _ := json.Marshal(map[string]string{"order_id": orderID})
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.base+"/internal/promotions/release", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cart returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+type RegistryPurchase struct {
+	RegistryID string `json:"registry_id"`
+	ProductID  string `json:"product_id"`
+	Quantity   int    `json:"quantity"`
+}
+
+// RecordRegistryPurchases reports a paid order's gift registry lines. The
+// cart service ignores repeat calls for the same order.
+func (c *CartClient) RecordRegistryPurchases(ctx context.Context, orderID string, userID *string, items []RegistryPurchase) error {
+	body := map[string]any{
+		"order_id": orderID,
+		"items":    items,
+	}
+	if userID != nil {
+		body["user_id"] = *userID
+	}
+	b, _ := json.Marshal(body)
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.base+"/internal/registries/purchases", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cart returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// MarkCartConverted tells the cart service that the cart an order was
+// prepared from has been paid for. Repeat calls are harmless.
+func (c *CartClient) MarkCartConverted(ctx context.Context, orderID, cartKey string) error {
+	b, _ := json.Marshal(map[string]string{"order_id": orderID, "cart_key": cartKey})
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.base+"/internal/carts/converted", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cart returned %d", resp.StatusCode)
+	}
+	return nil
+}
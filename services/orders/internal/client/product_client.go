@@ -36,6 +36,54 @@ func NewProductClient() *ProductClient {
 	}
 }
 
+/* -------------------- LOOKUP APIs -------------------- */
+
+// ProductInfo is the subset of a product the orders service checks before
+// placing an order.
+type ProductInfo struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Price     int    `json:"price"`
+	Stock     int    `json:"stock"`
+	Published bool   `json:"published"`
+}
+
+// GetProducts looks up many products in one call to POST /v1/products/batch.
+// Unknown or deleted IDs are absent from the returned map.
+func (p *ProductClient) GetProducts(ctx context.Context, productIDs []string) (map[string]ProductInfo, error) {
+	out := map[string]ProductInfo{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+
+	b, _ := json.Marshal(map[string][]string{"ids": productIDs})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, p.base+"/v1/products/batch", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	// unpublished products are only listed for internal callers
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := p.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("product batch lookup failed: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Products map[string]ProductInfo `json:"products"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Products != nil {
+		out = body.Products
+	}
+	return out, nil
+}
+
 /* -------------------- STOCK APIs -------------------- */
 
 func (p *ProductClient) ReserveStock(ctx context.Context, productID string, quantity int) error {
@@ -29,4 +29,24 @@ type OrderItem struct {
 	ProductID string `json:"product_id"`
 	Quantity   int   `json:"quantity"`
 	PriceCents int64 `json:"price_cents"`
+
+	// RegistryID is the gift registry the line was bought from, if any,
+	// and RegistryQuantity how many of its units were bought for it.
+	RegistryID       string `json:"registry_id,omitempty"`
+	RegistryQuantity int    `json:"registry_quantity,omitempty"`
+}
+
+// OrderPromotion is a discount the cart applied when the order was prepared.
+type OrderPromotion struct {
+	PromotionID string `json:"promotion_id"`
+	Code        string `json:"code,omitempty"`
+	Description string `json:"description"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// OrderCharges are the cart's delivery charges and the GST included in the
+// order total, in paise.
+type OrderCharges struct {
+	ShippingCents int64 `json:"shipping_cents"`
+	TaxCents      int64 `json:"tax_cents"`
 }
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devmanishoffl/sabhyatam-orders/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetUnconvertedCartKey returns the cart a paid order was prepared from,
+// or "" when the cart service already knows or the order predates cart
+// keys.
+func (s *PGStore) GetUnconvertedCartKey(ctx context.Context, orderID string) (string, error) {
+	var key string
+	err := s.db.QueryRow(ctx, `
+		SELECT cart_key FROM orders
+		WHERE id = $1 AND cart_key IS NOT NULL AND cart_converted_at IS NULL
+	`, orderID).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return key, err
+}
+
+func (s *PGStore) MarkCartConverted(ctx context.Context, orderID string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE orders SET cart_converted_at = now()
+		WHERE id = $1 AND cart_converted_at IS NULL
+	`, orderID)
+	return err
+}
+
+// ListUnconvertedCartOrders returns paid orders whose cart the cart service
+// has not been told about yet, oldest first.
+func (s *PGStore) ListUnconvertedCartOrders(ctx context.Context, limit int) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT id
+		FROM orders
+		WHERE status = ANY($1::text[])
+		  AND cart_key IS NOT NULL AND cart_converted_at IS NULL
+		ORDER BY updated_at
+		LIMIT $2
+	`, []string{string(model.StatusPaid), string(model.StatusProc)}, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
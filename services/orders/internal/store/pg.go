@@ -42,8 +42,11 @@ func (s *PGStore) Close() {
 func (s *PGStore) CreateDraftOrder(
 	ctx context.Context,
 	userID *string,
+	cartKey string,
 	items []model.OrderItem,
 	totalCents int64,
+	promotions []model.OrderPromotion,
+	charges model.OrderCharges,
 ) (string, error) {
 
 	tx, err := s.db.Begin(ctx)
@@ -61,14 +64,21 @@ func (s *PGStore) CreateDraftOrder(
 	var orderID string
 	// 1. Insert Order
 	err = tx.QueryRow(ctx, `
-		INSERT INTO orders (user_id, status, currency, total_amount_cents)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO orders (
+			user_id, status, currency, total_amount_cents,
+			discount_cents, shipping_cents, tax_cents, cart_key
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
 		RETURNING id
 	`,
 		userID,
 		model.StatusPending,
 		"INR",
 		totalCents,
+		discountCents(promotions),
+		charges.ShippingCents,
+		charges.TaxCents,
+		cartKey,
 	).Scan(&orderID)
 
 	if err != nil {
@@ -83,14 +93,42 @@ func (s *PGStore) CreateDraftOrder(
 				order_id,
 				product_id,
 				quantity,
-				price_cents
+				price_cents,
+				registry_id,
+				registry_quantity
 			)
-			VALUES ($1, $2, $3, $4)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, 0))
 		`,
 			orderID,
 			it.ProductID,
 			it.Quantity,
 			it.PriceCents,
+			it.RegistryID,
+			it.RegistryQuantity,
+		)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// 3. Record applied promotions
+	for _, p := range promotions {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO order_promotions (
+				order_id,
+				promotion_id,
+				code,
+				description,
+				amount_cents
+			)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		`,
+			orderID,
+			p.PromotionID,
+			p.Code,
+			p.Description,
+			p.AmountCents,
 		)
 
 		if err != nil {
@@ -445,3 +483,50 @@ func (s *PGStore) IsOrderItemReviewEligible(ctx context.Context, id string) (boo
 
 	return Fulfillment_Status == "delivered", nil
 }
+
+// HasPurchasedProduct reports whether the user has a paid order containing
+// the product.
+func (s *PGStore) HasPurchasedProduct(ctx context.Context, userID, productID string) (bool, error) {
+	var purchased bool
+	err := s.db.QueryRow(ctx, `
+        select exists (
+            select 1
+            from order_items oi
+            join orders o on o.id = oi.order_id
+            where o.user_id = $1 and oi.product_id = $2 and o.status = $3
+        )
+    `, userID, productID, string(model.StatusPaid)).Scan(&purchased)
+	return purchased, err
+}
+
+// PurchasedQuantities sums how many of each product a user has bought or
+// is paying for. Orders awaiting payment count too: the cart is not cleared
+// at checkout, so without them the same cart could be prepared twice and
+// both orders paid. An abandoned checkout stops counting once the payment
+// timeout cancels it.
+func (s *PGStore) PurchasedQuantities(ctx context.Context, userID string, productIDs []string) (map[string]int, error) {
+	rows, err := s.db.Query(ctx, `
+        select oi.product_id::text, sum(oi.quantity)::int
+        from order_items oi
+        join orders o on o.id = oi.order_id
+        where o.user_id = $1
+          and oi.product_id = any($2::uuid[])
+          and o.status = any($3::text[])
+        group by oi.product_id
+    `, userID, productIDs, []string{string(model.StatusDraft), string(model.StatusPending), string(model.StatusPaid), string(model.StatusProc)})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]int, len(productIDs))
+	for rows.Next() {
+		var id string
+		var qty int
+		if err := rows.Scan(&id, &qty); err != nil {
+			return nil, err
+		}
+		out[id] = qty
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-orders/internal/model"
+)
+
+func discountCents(promotions []model.OrderPromotion) int64 {
+	var total int64
+	for _, p := range promotions {
+		total += p.AmountCents
+	}
+	return total
+}
+
+// MarkPromotionsRedeemed records that the cart service counted an order's
+// promotions against their limits.
+func (s *PGStore) MarkPromotionsRedeemed(ctx context.Context, orderID string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE orders SET promotions_redeemed_at = now()
+		WHERE id = $1 AND promotions_redeemed_at IS NULL
+	`, orderID)
+	return err
+}
+
+func (s *PGStore) MarkPromotionsReleased(ctx context.Context, orderID string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE orders SET promotions_released_at = now()
+		WHERE id = $1 AND promotions_released_at IS NULL
+	`, orderID)
+	return err
+}
+
+// ListUnreleasedPromotionOrders returns orders with promotions whose uses
+// have not been given back to the cart service yet, oldest first: cancelled
+// orders, and orders still awaiting payment staleAfter after they were
+// placed, which would otherwise hold a capped offer for good. The cart
+// ignores releases for orders it never counted.
+func (s *PGStore) ListUnreleasedPromotionOrders(ctx context.Context, staleAfter time.Duration, limit int) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT o.id
+		FROM orders o
+		WHERE o.promotions_released_at IS NULL
+		  AND (
+		      o.status = 'cancelled'
+		      OR (o.status = ANY($1::text[]) AND o.created_at <= now() - make_interval(secs => $2))
+		  )
+		  AND EXISTS (SELECT 1 FROM order_promotions p WHERE p.order_id = o.id)
+		ORDER BY o.updated_at
+		LIMIT $3
+	`, []string{string(model.StatusDraft), string(model.StatusPending)}, staleAfter.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
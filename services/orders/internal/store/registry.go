@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+
+	"github.com/devmanishoffl/sabhyatam-orders/internal/model"
+)
+
+// GetUnrecordedRegistryItems returns an order's gift registry lines whose
+// purchase has not yet been reported to the cart service.
+// RegistryQuantity is filled in for lines from before it was stored.
+func (s *PGStore) GetUnrecordedRegistryItems(ctx context.Context, orderID string) ([]model.OrderItem, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT i.product_id, i.quantity, i.price_cents, i.registry_id,
+		       COALESCE(i.registry_quantity, i.quantity)
+		FROM order_items i
+		JOIN orders o ON o.id = i.order_id
+		WHERE i.order_id = $1 AND i.registry_id IS NOT NULL
+		  AND o.registry_recorded_at IS NULL
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.OrderItem
+	for rows.Next() {
+		it := model.OrderItem{OrderID: orderID}
+		if err := rows.Scan(&it.ProductID, &it.Quantity, &it.PriceCents, &it.RegistryID, &it.RegistryQuantity); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGStore) MarkRegistryRecorded(ctx context.Context, orderID string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE orders SET registry_recorded_at = now()
+		WHERE id = $1 AND registry_recorded_at IS NULL
+	`, orderID)
+	return err
+}
+
+// ListUnrecordedRegistryOrders returns paid orders with gift registry
+// lines not yet reported to the cart service, oldest first.
+func (s *PGStore) ListUnrecordedRegistryOrders(ctx context.Context, limit int) ([]model.Order, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT o.id, o.user_id
+		FROM orders o
+		WHERE o.status = ANY($1::text[]) AND o.registry_recorded_at IS NULL
+		  AND EXISTS (
+		      SELECT 1 FROM order_items i
+		      WHERE i.order_id = o.id AND i.registry_id IS NOT NULL
+		  )
+		ORDER BY o.updated_at
+		LIMIT $2
+	`, []string{string(model.StatusPaid), string(model.StatusProc)}, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.Order
+	for rows.Next() {
+		var o model.Order
+		if err := rows.Scan(&o.ID, &o.UserID); err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
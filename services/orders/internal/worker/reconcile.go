@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-orders/internal/client"
+	"github.com/devmanishoffl/sabhyatam-orders/internal/model"
+	"github.com/devmanishoffl/sabhyatam-orders/internal/store"
+)
+
+// reconcileBatch bounds the orders retried per sweep.
+const reconcileBatch = 100
+
+// ReconcileWorker retries reports to the cart service that failed inline:
+// giving back the promotion uses of cancelled orders (and of orders left
+// unpaid past paymentTimeout), recording gift
+// registry purchases of paid ones and marking their carts converted, so an
+// outage does not leave the cart's counters wrong for good.
+type ReconcileWorker struct {
+	store          *store.PGStore
+	cartClient     *client.CartClient
+	interval       time.Duration
+	paymentTimeout time.Duration
+}
+
+func NewReconcileWorker(
+	s *store.PGStore,
+	c *client.CartClient,
+	interval time.Duration,
+	paymentTimeout time.Duration,
+) *ReconcileWorker {
+	return &ReconcileWorker{
+		store:          s,
+		cartClient:     c,
+		interval:       interval,
+		paymentTimeout: paymentTimeout,
+	}
+}
+
+func (w *ReconcileWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *ReconcileWorker) sweep(ctx context.Context) {
+	w.releasePromotions(ctx)
+	w.recordRegistryPurchases(ctx)
+	w.markCartsConverted(ctx)
+}
+
+func (w *ReconcileWorker) releasePromotions(ctx context.Context) {
+	ids, err := w.store.ListUnreleasedPromotionOrders(ctx, w.paymentTimeout, reconcileBatch)
+	if err != nil {
+		log.Println("promotion release sweep failed:", err)
+		return
+	}
+
+	for _, id := range ids {
+		// idempotent — the cart releases an order once
+		if err := w.cartClient.ReleasePromotions(ctx, id); err != nil {
+			log.Println("failed to release promotions:", id, err)
+			continue
+		}
+		if err := w.store.MarkPromotionsReleased(ctx, id); err != nil {
+			log.Println("mark promotions released:", id, err)
+		}
+	}
+}
+
+func (w *ReconcileWorker) recordRegistryPurchases(ctx context.Context) {
+	orders, err := w.store.ListUnrecordedRegistryOrders(ctx, reconcileBatch)
+	if err != nil {
+		log.Println("registry purchase sweep failed:", err)
+		return
+	}
+
+	for i := range orders {
+		if err := RecordRegistryPurchases(ctx, w.store, w.cartClient, &orders[i]); err != nil {
+			log.Println("failed to record registry purchases:", orders[i].ID, err)
+		}
+	}
+}
+
+// RecordRegistryPurchases reports a paid order's unrecorded gift registry
+// lines to the cart service and marks them recorded. The cart ignores
+// repeats for the same order, so it is safe to retry.
+func RecordRegistryPurchases(ctx context.Context, s *store.PGStore, c *client.CartClient, order *model.Order) error {
+	items, err := s.GetUnrecordedRegistryItems(ctx, order.ID)
+	if err != nil || len(items) == 0 {
+		return err
+	}
+
+	purchases := make([]client.RegistryPurchase, 0, len(items))
+	for _, it := range items {
+		purchases = append(purchases, client.RegistryPurchase{
+			RegistryID: it.RegistryID,
+			ProductID:  it.ProductID,
+			Quantity:   it.RegistryQuantity,
+		})
+	}
+	if err := c.RecordRegistryPurchases(ctx, order.ID, order.UserID, purchases); err != nil {
+		return err
+	}
+	return s.MarkRegistryRecorded(ctx, order.ID)
+}
+
+func (w *ReconcileWorker) markCartsConverted(ctx context.Context) {
+	ids, err := w.store.ListUnconvertedCartOrders(ctx, reconcileBatch)
+	if err != nil {
+		log.Println("cart conversion sweep failed:", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := MarkCartConverted(ctx, w.store, w.cartClient, id); err != nil {
+			log.Println("failed to mark cart converted:", id, err)
+		}
+	}
+}
+
+// MarkCartConverted tells the cart service that a paid order's cart was
+// checked out and records that it did. Orders from before cart keys were
+// stored are skipped.
+func MarkCartConverted(ctx context.Context, s *store.PGStore, c *client.CartClient, orderID string) error {
+	key, err := s.GetUnconvertedCartKey(ctx, orderID)
+	if err != nil || key == "" {
+		return err
+	}
+	if err := c.MarkCartConverted(ctx, orderID, key); err != nil {
+		return err
+	}
+	return s.MarkCartConverted(ctx, orderID)
+}
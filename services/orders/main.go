@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/devmanishoffl/sabhyatam-orders/internal/api"
 	"github.com/devmanishoffl/sabhyatam-orders/internal/client"
 	"github.com/devmanishoffl/sabhyatam-orders/internal/store"
+	"github.com/devmanishoffl/sabhyatam-orders/internal/worker"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware" // Import standard middleware
 	"github.com/go-chi/cors"
@@ -45,6 +48,25 @@ func main() {
 	cc := client.NewCartClient()
 	h := api.NewHandler(ps, pc, cc)
 
+	// Retry cart reports that failed inline, every ORDERS_RECONCILE_INTERVAL
+	// (default 5m).
+	reconcileEvery := 5 * time.Minute
+	if v := os.Getenv("ORDERS_RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			reconcileEvery = d
+		}
+	}
+	// Orders still unpaid ORDERS_PAYMENT_TIMEOUT (default 30m) after they
+	// were placed give their promotion uses back. It should outlast the
+	// payments service's own timeout, which cancels orders it gave up on.
+	paymentTimeout := 30 * time.Minute
+	if v := os.Getenv("ORDERS_PAYMENT_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			paymentTimeout = d
+		}
+	}
+	go worker.NewReconcileWorker(ps, cc, reconcileEvery, paymentTimeout).Run(context.Background())
+
 	// 3. Setup Router
 	r := chi.NewRouter()
 
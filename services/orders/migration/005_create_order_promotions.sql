@@ -0,0 +1,12 @@
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS discount_cents BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS promotions_redeemed_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS order_promotions (
+  order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  promotion_id TEXT NOT NULL,
+  code TEXT,
+  description TEXT NOT NULL DEFAULT '',
+  amount_cents BIGINT NOT NULL,
+  PRIMARY KEY (order_id, promotion_id)
+);
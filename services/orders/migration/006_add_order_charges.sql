@@ -0,0 +1,3 @@
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS shipping_cents BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS tax_cents BIGINT NOT NULL DEFAULT 0;
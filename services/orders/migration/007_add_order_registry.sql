@@ -0,0 +1,5 @@
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS registry_id TEXT;
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS registry_recorded_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS promotions_released_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- how many of a line's units were bought for its gift registry; the rest
+-- are the buyer's own. Lines from before this column count whole.
+ALTER TABLE order_items
+ADD COLUMN IF NOT EXISTS registry_quantity INT;
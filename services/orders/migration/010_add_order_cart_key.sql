@@ -0,0 +1,7 @@
+-- the cart an order was prepared from ("user:..." or "session:..."), and
+-- when the cart service was told it was paid for
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS cart_key TEXT;
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS cart_converted_at TIMESTAMPTZ;
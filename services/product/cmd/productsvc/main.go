@@ -9,9 +9,15 @@ import (
 	"time"
 
 	"github.com/devmanishoffl/sabhyatam-product/internal/api"
+	"github.com/devmanishoffl/sabhyatam-product/internal/bulk"
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
 	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+	"github.com/devmanishoffl/sabhyatam-product/internal/feed"
 	"github.com/devmanishoffl/sabhyatam-product/internal/gateway"
+	"github.com/devmanishoffl/sabhyatam-product/internal/notify"
 	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/devmanishoffl/sabhyatam-product/internal/trash"
+	"github.com/devmanishoffl/sabhyatam-product/internal/worker"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
@@ -28,6 +34,10 @@ func main() {
 	}
 	defer db.Close(context.Background())
 
+	// Context for background workers
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 
@@ -48,6 +58,8 @@ func main() {
 			"Content-Type",
 			"X-SESSION-ID",
 			"X-ADMIN-KEY",
+			"X-CACHE-BYPASS",
+			"X-VENDOR-KEY",
 		},
 		ExposedHeaders: []string{
 			"Link",
@@ -68,7 +80,35 @@ func main() {
 
 	s3Gw := gateway.NewS3Gateway(bucketName)
 
-	h := api.NewHandler(db, s3Gw)
+	feeds := feed.NewGenerator(db, cfg)
+	go worker.NewFeedRefreshWorker(feeds, cfg.FeedRefresh).Run(ctx)
+
+	productCache, err := cache.New(
+		cfg.ProductCacheSize,
+		cfg.ProductCacheTTL,
+		cfg.ProductCacheRedisAddr,
+		cfg.ProductCacheRedisPassword,
+	)
+	if err != nil {
+		log.Fatalf("product cache redis error: %v", err)
+	}
+	go productCache.Run(ctx)
+
+	purger := trash.NewPurger(db, s3Gw, productCache)
+	go worker.NewTrashRetentionWorker(purger, cfg.TrashRetentionDays).Run(ctx)
+
+	sender, err := notify.NewSender(cfg)
+	if err != nil {
+		log.Fatalf("restock notifier error: %v", err)
+	}
+	restock := worker.NewRestockNotifyWorker(db, sender, cfg)
+	go restock.Run(ctx)
+
+	bulkRunner := bulk.NewRunner(ctx, db, productCache)
+
+	supabase := gateway.NewSupabaseGateway(os.Getenv("SUPABASE_URL"), os.Getenv("SUPABASE_ANON_KEY"))
+
+	h := api.NewHandler(db, s3Gw, cfg, feeds, productCache, purger, restock, bulkRunner, supabase)
 
 	h.RegisterRoutes(r)
 
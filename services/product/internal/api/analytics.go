@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// inventoryAnalyticsHandler serves GET /v1/admin/analytics/inventory.
+// ?dead_days= (default 90) sets the no-sale window for dead stock and
+// ?low_stock= (default 5) the low stock threshold.
+func (h *Handler) inventoryAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	deadDays := 90
+	if v := q.Get("dead_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "dead_days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		deadDays = n
+	}
+	lowStock := 5
+	if v := q.Get("low_stock"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "low_stock must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		lowStock = n
+	}
+
+	report, err := h.store.InventoryAnalytics(r.Context(), deadDays, lowStock)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
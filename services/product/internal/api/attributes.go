@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) listAttributeDefinitionsHandler(w http.ResponseWriter, r *http.Request) {
+	defs, err := h.store.ListAttributeDefinitions(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"attributes": defs})
+}
+
+// putAttributeDefinitionHandler creates or replaces the definition for a
+// products.attributes key. Comparable defaults to true.
+func (h *Handler) putAttributeDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	d := model.AttributeDefinition{Comparable: true}
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	d.Key = chi.URLParam(r, "key")
+	d.Label = strings.TrimSpace(d.Label)
+	if d.Label == "" {
+		http.Error(w, "label required", http.StatusBadRequest)
+		return
+	}
+	switch d.ValueType {
+	case "":
+		d.ValueType = model.AttrText
+	case model.AttrText, model.AttrNumber, model.AttrBoolean:
+	default:
+		http.Error(w, "value_type must be text, number or boolean", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.UpsertAttributeDefinition(r.Context(), &d); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (h *Handler) deleteAttributeDefinitionHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.store.DeleteAttributeDefinition(r.Context(), chi.URLParam(r, "key"))
+	if errors.Is(err, store.ErrAttributeNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
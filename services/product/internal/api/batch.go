@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxBatchIDs bounds a single batch lookup; a cart or order never comes
+// close.
+const maxBatchIDs = 100
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// batchProductsHandler serves POST /v1/products/batch with {"ids": [...]}
+// and GET /v1/products/batch?ids=a,b,c. It is meant for internal callers
+// (cart, orders) hydrating many products at once: the response keys products
+// by ID and lists IDs that are malformed, deleted or unknown under "missing".
+// Only callers with the internal service key see unpublished products; for
+// anyone else they are missing too.
+func (h *Handler) batchProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+	if r.Method == http.MethodPost {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+		ids = body.IDs
+	} else if v := r.URL.Query().Get("ids"); v != "" {
+		ids = strings.Split(v, ",")
+	}
+
+	seen := map[string]bool{}
+	var valid, missing []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if uuidPattern.MatchString(id) {
+			valid = append(valid, id)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(seen) > maxBatchIDs {
+		http.Error(w, "too many ids", http.StatusBadRequest)
+		return
+	}
+
+	products, err := h.store.GetProductsByIDs(r.Context(), valid)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if key := os.Getenv("INTERNAL_SERVICE_KEY"); key == "" || r.Header.Get("X-INTERNAL-KEY") != key {
+		for id, p := range products {
+			if !p.Published {
+				delete(products, id)
+			}
+		}
+	}
+	for _, id := range valid {
+		if _, ok := products[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if missing == nil {
+		missing = []string{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"products": products,
+		"missing":  missing,
+	})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/bulk"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// bulkProductsHandler applies one operation to many products. In the
+// default "transaction" mode it answers with the full report (200 when
+// committed, 409 when rolled back); in "job" mode it answers 202 with the
+// job to poll.
+func (h *Handler) bulkProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var req model.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		rep *model.BulkReport
+		err error
+	)
+	switch req.Mode {
+	case "", "transaction":
+		rep, err = h.bulk.Run(r.Context(), &req)
+	case "job":
+		rep, err = h.bulk.Start(r.Context(), &req)
+	default:
+		http.Error(w, "mode must be transaction or job", http.StatusBadRequest)
+		return
+	}
+
+	var invalid *bulk.InvalidError
+	if errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case rep.JobID != "":
+		writeJSON(w, http.StatusAccepted, rep)
+	case !rep.Committed:
+		writeJSON(w, http.StatusConflict, rep)
+	default:
+		writeJSON(w, http.StatusOK, rep)
+	}
+}
+
+func (h *Handler) listBulkJobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.store.ListBulkJobs(r.Context(), 50)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs})
+}
+
+func (h *Handler) getBulkJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.store.GetBulkJob(r.Context(), chi.URLParam(r, "job_id"))
+	if errors.Is(err, store.ErrBulkJobNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// setBundleHandler defines (or redefines) a product as a bundle of other
+// products. The bundle's stock is derived from its components from then on.
+func (h *Handler) setBundleHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var body struct {
+		Items []model.BundleItem `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	// Components dropped from the definition need their cached bundle view
+	// refreshed too, so collect dependents before and after.
+	before, _ := h.store.StockDependents(r.Context(), id)
+
+	err := h.store.SetBundle(r.Context(), id, body.Items)
+	if errors.Is(err, store.ErrInvalidBundle) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, dep := range before {
+		h.cache.Invalidate(r.Context(), dep)
+	}
+	h.invalidateStock(r.Context(), id)
+
+	components, err := h.store.GetBundleComponents(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "updated", "components": components})
+}
+
+func (h *Handler) removeBundleHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	before, _ := h.store.StockDependents(r.Context(), id)
+
+	err := h.store.RemoveBundle(r.Context(), id)
+	if errors.Is(err, store.ErrInvalidBundle) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, dep := range before {
+		h.cache.Invalidate(r.Context(), dep)
+	}
+	h.cache.Invalidate(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "removed"})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// maxCompareIDs is how many products fit side by side in the comparison.
+const maxCompareIDs = 4
+
+// compareProductsHandler serves GET /v1/products/compare?ids=a,b,c. Products
+// come back in request order and every row of the grid has one cell per
+// product, so clients can render columns without matching IDs. Rows follow
+// the comparable attribute definitions, then price and rating. IDs that are
+// malformed, unpublished or unknown are dropped and listed under "missing".
+func (h *Handler) compareProductsHandler(w http.ResponseWriter, r *http.Request) {
+	seen := map[string]bool{}
+	var valid, missing []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if uuidPattern.MatchString(id) {
+			valid = append(valid, id)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(seen) == 0 {
+		http.Error(w, "ids required", http.StatusBadRequest)
+		return
+	}
+	if len(seen) > maxCompareIDs {
+		http.Error(w, fmt.Sprintf("at most %d products can be compared", maxCompareIDs), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	found, err := h.store.GetProductsByIDs(ctx, valid)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var products []*model.Product
+	for _, id := range valid {
+		p, ok := found[id]
+		if !ok || !p.Published {
+			missing = append(missing, id)
+			continue
+		}
+		products = append(products, p)
+	}
+	if len(products) == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if missing == nil {
+		missing = []string{}
+	}
+
+	defs, err := h.store.ListAttributeDefinitions(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	// Ratings are decoration; without them the row is simply all missing.
+	ratings, err := h.store.GetRatingSummaries(ctx, ids)
+	if err != nil {
+		log.Println("compare ratings:", err)
+	}
+
+	locale := h.requestLocale(w, r)
+	var labels *model.Labels
+	if locale != model.DefaultLocale {
+		translations, err := h.store.GetTranslationsFor(ctx, ids, locale)
+		if err != nil {
+			log.Println("load translations:", err)
+		}
+		labels = h.loadLabels(ctx, locale)
+		for i, p := range products {
+			if t, ok := translations[p.ID]; ok && t.Title != "" {
+				cp := *p
+				cp.Title = t.Title
+				products[i] = &cp
+			}
+		}
+	}
+
+	columns := make([]model.CompareProduct, len(products))
+	for i, p := range products {
+		columns[i] = model.CompareProduct{
+			ID:       p.ID,
+			Slug:     p.Slug,
+			Title:    p.Title,
+			ImageURL: p.ImageURL,
+			Price:    p.Price,
+			MRP:      p.MRP,
+			InStock:  p.InStock,
+		}
+	}
+
+	h.writeCachedJSON(w, r, map[string]any{
+		"products": columns,
+		"rows":     compareRows(products, defs, ratings, labels),
+		"missing":  missing,
+		"locale":   locale,
+	}, time.Time{}) // ratings and definitions change without touching updated_at
+}
+
+// compareRows builds the grid. A cell is missing when the product has no
+// value, a null or an empty string for the attribute.
+func compareRows(products []*model.Product, defs []model.AttributeDefinition, ratings map[string]model.RatingSummary, labels *model.Labels) []model.CompareRow {
+	var rows []model.CompareRow
+	for _, d := range defs {
+		if !d.Comparable {
+			continue
+		}
+		row := model.CompareRow{Key: d.Key, Label: d.Label, Unit: d.Unit}
+		for _, p := range products {
+			v, ok := p.Attributes[d.Key]
+			if s, isString := v.(string); !ok || v == nil || (isString && strings.TrimSpace(s) == "") {
+				row.Values = append(row.Values, model.CompareCell{Missing: true})
+				continue
+			}
+			cell := model.CompareCell{Value: v}
+			if s, isString := v.(string); isString && labels != nil {
+				cell.Label = labels.Attributes[d.Key][s]
+			}
+			row.Values = append(row.Values, cell)
+		}
+		rows = append(rows, finishRow(row))
+	}
+
+	price := model.CompareRow{Key: "price", Label: "Price", Unit: "INR"}
+	for _, p := range products {
+		price.Values = append(price.Values, model.CompareCell{Value: p.Price})
+	}
+	rows = append(rows, finishRow(price))
+
+	rating := model.CompareRow{Key: "rating", Label: "Rating"}
+	for _, p := range products {
+		rs, ok := ratings[p.ID]
+		if !ok || rs.Count == 0 {
+			rating.Values = append(rating.Values, model.CompareCell{Missing: true})
+			continue
+		}
+		rs.Average = math.Round(rs.Average*10) / 10
+		rating.Values = append(rating.Values, model.CompareCell{Value: rs})
+	}
+	return append(rows, finishRow(rating))
+}
+
+// finishRow sets Same: every cell present and equal.
+func finishRow(row model.CompareRow) model.CompareRow {
+	row.Same = len(row.Values) > 1
+	for _, c := range row.Values {
+		if c.Missing || fmt.Sprint(c.Value) != fmt.Sprint(row.Values[0].Value) {
+			row.Same = false
+			break
+		}
+	}
+	return row
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/feed"
+	"github.com/go-chi/chi/v5"
+)
+
+// feedFileHandler serves a marketplace feed from a stable URL such as
+// /v1/feeds/google.xml. Feeds are built by the refresh worker; if a request
+// arrives before the first run, the feed is generated inline.
+func (h *Handler) feedFileHandler(w http.ResponseWriter, r *http.Request) {
+	format := feed.Format(chi.URLParam(r, "file"))
+
+	snap := h.feeds.Latest()
+	if snap == nil {
+		var err error
+		if snap, err = h.feeds.Regenerate(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	body, ok := snap.Files[format]
+	if !ok {
+		http.Error(w, "unknown feed", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Last-Modified", snap.GeneratedAt.Format(http.TimeFormat))
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *Handler) feedReportHandler(w http.ResponseWriter, r *http.Request) {
+	snap := h.feeds.Latest()
+	if snap == nil {
+		writeJSON(w, http.StatusOK, map[string]any{"status": "pending"})
+		return
+	}
+	writeJSON(w, http.StatusOK, feedReport(snap))
+}
+
+func (h *Handler) regenerateFeedsHandler(w http.ResponseWriter, r *http.Request) {
+	snap, err := h.feeds.Regenerate(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, feedReport(snap))
+}
+
+func feedReport(snap *feed.Snapshot) map[string]any {
+	files := map[string]string{}
+	for _, f := range feed.Formats {
+		files[string(f)] = "/v1/feeds/" + string(f)
+	}
+	return map[string]any{
+		"generated_at": snap.GeneratedAt.Format(time.RFC3339),
+		"item_count":   snap.ItemCount,
+		"excluded":     snap.Excluded,
+		"issues":       snap.Issues,
+		"files":        files,
+	}
+}
@@ -9,9 +9,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/devmanishoffl/sabhyatam-product/internal/bulk"
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+	"github.com/devmanishoffl/sabhyatam-product/internal/feed"
 	"github.com/devmanishoffl/sabhyatam-product/internal/gateway"
 	"github.com/devmanishoffl/sabhyatam-product/internal/model"
 	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/devmanishoffl/sabhyatam-product/internal/trash"
+	"github.com/devmanishoffl/sabhyatam-product/internal/worker"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -19,35 +25,125 @@ import (
 type Handler struct {
 	store     *store.Store
 	s3gateway *gateway.S3Gateway
+	cfg       *config.Config
+	feeds     *feed.Generator
+	cache     *cache.ProductCache
+	purger    *trash.Purger
+	restock   *worker.RestockNotifyWorker
+	bulk      *bulk.Runner
+	auth      *gateway.SupabaseGateway
 }
 
-func NewHandler(s *store.Store, s3gw *gateway.S3Gateway) *Handler {
+func NewHandler(s *store.Store, s3gw *gateway.S3Gateway, cfg *config.Config, feeds *feed.Generator, pc *cache.ProductCache, purger *trash.Purger, restock *worker.RestockNotifyWorker, bulkRunner *bulk.Runner, auth *gateway.SupabaseGateway) *Handler {
 	return &Handler{
 		store:     s,
 		s3gateway: s3gw,
+		cfg:       cfg,
+		feeds:     feeds,
+		cache:     pc,
+		purger:    purger,
+		restock:   restock,
+		bulk:      bulkRunner,
+		auth:      auth,
 	}
 }
 
 func (h *Handler) RegisterRoutes(r *chi.Mux) {
+	// Sitemaps are also served unversioned, at the paths the sitemap index
+	// lists, so the storefront can proxy /sitemap.xml and /sitemaps/* as is.
+	h.registerSitemapRoutes(r)
+
 	r.Route("/v1", func(r chi.Router) {
 		r.Use(middleware.StripSlashes)
 
 		r.Get("/products/search", h.searchProductsHandler)
+		r.Get("/products/batch", h.batchProductsHandler)
+		r.Post("/products/batch", h.batchProductsHandler)
+		r.Get("/products/compare", h.compareProductsHandler)
 		r.Get("/products/slug/{slug}", h.getProductBySlugHandler)
 		r.Get("/products/{id}", h.getProductDetailHandler)
+		r.Post("/products/{id}/notify-me", h.subscribeRestockHandler)
+		r.Delete("/products/{id}/notify-me", h.unsubscribeRestockHandler)
+		r.Post("/notify-me/confirm", h.confirmRestockHandler)
+		r.Post("/notify-me/cancel", h.cancelRestockHandler)
 		r.Get("/products", h.listProductsHandler)
 
+		// SEO
+		h.registerSitemapRoutes(r)
+
+		// Marketplace feeds
+		r.Get("/feeds/{file}", h.feedFileHandler)
+
+		r.Get("/artisans/{slug}", h.artisanProfileHandler)
+
+		r.Get("/attribute-definitions", h.listAttributeDefinitionsHandler)
+
+		// Vendor-scoped admin: only the authenticated vendor's products
+		r.Route("/vendor", func(r chi.Router) {
+			r.Use(h.VendorOnly)
+
+			r.Get("/me", h.vendorMeHandler)
+			r.Get("/products", h.vendorListProductsHandler)
+			r.Post("/products", h.vendorCreateProductHandler)
+			r.Put("/products/{id}", h.vendorUpdateProductHandler)
+			r.Put("/products/{id}/stock", h.vendorSetStockHandler)
+		})
+
 		r.Route("/admin", func(r chi.Router) {
 			r.Use(AdminOnly)
 
 			r.Get("/media/upload-url", h.GetUploadURL)
 
+			r.Get("/feeds", h.feedReportHandler)
+			r.Post("/feeds/regenerate", h.regenerateFeedsHandler)
+
+			r.Get("/analytics/inventory", h.inventoryAnalyticsHandler)
+
+			r.Get("/cache/stats", h.cacheStatsHandler)
+			r.Post("/cache/purge", h.cachePurgeHandler)
+
 			// Products
 			r.Get("/products", h.adminListProductsHandler)
 			r.Post("/products", h.createProductHandler)
 			r.Put("/products/{id}", h.updateProductHandler)
 			r.Delete("/products/{id}", h.deleteProductHandler)
 
+			r.Put("/products/{id}/vendor", h.assignProductVendorHandler)
+
+			// Bundles
+			r.Put("/products/{id}/bundle", h.setBundleHandler)
+			r.Delete("/products/{id}/bundle", h.removeBundleHandler)
+
+			r.Get("/products/{id}/restock-subscriptions", h.restockSubscriptionsHandler)
+
+			// Translations
+			r.Get("/products/{id}/translations", h.listProductTranslationsHandler)
+			r.Put("/products/{id}/translations/{locale}", h.putProductTranslationHandler)
+			r.Delete("/products/{id}/translations/{locale}", h.deleteProductTranslationHandler)
+			r.Get("/labels/{locale}", h.getLabelsHandler)
+			r.Put("/labels/{locale}", h.putLabelsHandler)
+
+			// Attribute definitions
+			r.Put("/attribute-definitions/{key}", h.putAttributeDefinitionHandler)
+			r.Delete("/attribute-definitions/{key}", h.deleteAttributeDefinitionHandler)
+
+			// Vendors
+			r.Get("/vendors", h.listVendorsHandler)
+			r.Post("/vendors", h.createVendorHandler)
+			r.Get("/vendors/{vendor_id}", h.getVendorHandler)
+			r.Put("/vendors/{vendor_id}", h.updateVendorHandler)
+			r.Post("/vendors/{vendor_id}/api-key", h.rotateVendorKeyHandler)
+
+			// Bulk operations
+			r.Post("/products/bulk", h.bulkProductsHandler)
+			r.Get("/bulk-jobs", h.listBulkJobsHandler)
+			r.Get("/bulk-jobs/{job_id}", h.getBulkJobHandler)
+
+			// Trash
+			r.Get("/products/trash", h.listTrashHandler)
+			r.Post("/products/{id}/restore", h.restoreProductHandler)
+			r.Delete("/products/{id}/purge", h.purgeProductHandler)
+
 			r.Route("/products/{id}/stock", func(r chi.Router) {
 				r.Post("/reserve", h.reserveStockHandler)
 				r.Post("/release", h.releaseStockHandler)
@@ -133,6 +229,7 @@ func (h *Handler) listProductsHandler(w http.ResponseWriter, r *http.Request) {
 		"color":       q.Get("color"),
 		"origin":      q.Get("origin"),
 		"q":           q.Get("q"),
+		"locale":      h.requestLocale(w, r),
 	}
 
 	sortParam := q.Get("sort")
@@ -161,37 +258,39 @@ func (h *Handler) listProductsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
-	activeCount, lowStockCount, _ := h.store.GetDashboardStats(r.Context())
+	h.localizeProducts(r.Context(), items, filters["locale"])
+
+	lastMod, _ := h.store.CatalogLastModified(r.Context())
 
 	resp := map[string]interface{}{
-		"page":            page,
-		"limit":           limit,
-		"items":           items,
-		"total":           total,
-		"active_count":    activeCount,
-		"low_stock_count": lowStockCount,
+		"page":   page,
+		"limit":  limit,
+		"items":  items,
+		"total":  total,
+		"locale": filters["locale"],
 	}
-	writeJSON(w, http.StatusOK, resp)
+	h.writeCachedJSON(w, r, resp, lastMod)
 }
 
 func (h *Handler) getProductDetailHandler(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	product, err := h.store.GetProductByID(r.Context(), id)
+	detail, err := h.loadDetail(w, r, id)
 	if err != nil {
 		http.Error(w, "not found", 404)
 		return
 	}
-	media, _ := h.store.GetMediaByProductID(r.Context(), product.ID)
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"product": product,
-		"media":   media,
-		"seo": map[string]any{
-			"title":       product.Title + " | Sabhyatam",
-			"description": product.ShortDesc,
-			"canonical":   "https://sabhyatam.com/products/" + product.Slug,
-		},
-	})
+	locale := h.requestLocale(w, r)
+	product, media := h.localizeDetail(r.Context(), detail, locale), detail.Media
+	seo, jsonLD := h.productSEO(r.Context(), product, media)
+
+	h.writeCachedJSON(w, r, map[string]any{
+		"product":    product,
+		"media":      media,
+		"components": detail.Components,
+		"locale":     locale,
+		"seo":        seo,
+		"json_ld":    jsonLD,
+	}, h.detailLastModified(r.Context(), detail))
 }
 
 func (h *Handler) createProductHandler(w http.ResponseWriter, r *http.Request) {
@@ -224,6 +323,7 @@ func (h *Handler) updateProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	h.invalidateStock(r.Context(), id)
 
 	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
 }
@@ -236,6 +336,7 @@ func (h *Handler) deleteProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	h.invalidateStock(r.Context(), id)
 
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
@@ -288,6 +389,7 @@ func (h *Handler) createMediaHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.cache.Invalidate(r.Context(), productID)
 
 	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
 }
@@ -295,11 +397,12 @@ func (h *Handler) createMediaHandler(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) deleteMediaHandler(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "media_id")
 
-	err := h.store.DeleteMedia(r.Context(), id)
+	productID, err := h.store.DeleteMedia(r.Context(), id)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
+	h.cache.Invalidate(r.Context(), productID)
 
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
@@ -319,6 +422,7 @@ func (h *Handler) reserveStockHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 409)
 		return
 	}
+	h.invalidateStock(r.Context(), id)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "reserved"})
 }
 
@@ -337,6 +441,7 @@ func (h *Handler) releaseStockHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 409)
 		return
 	}
+	h.invalidateStock(r.Context(), id)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "released"})
 }
 
@@ -355,6 +460,7 @@ func (h *Handler) deductStockHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 409)
 		return
 	}
+	h.invalidateStock(r.Context(), id)
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deducted"})
 }
 
@@ -380,6 +486,7 @@ func (h *Handler) searchProductsHandler(w http.ResponseWriter, r *http.Request)
 		Sort:     q.Get("sort"),
 		Page:     page,
 		Limit:    limit,
+		Locale:   h.requestLocale(w, r),
 	}
 
 	if v := q.Get("min_price"); v != "" {
@@ -389,7 +496,17 @@ func (h *Handler) searchProductsHandler(w http.ResponseWriter, r *http.Request)
 		params.MaxPrice, _ = strconv.Atoi(v)
 	}
 
-	items, total, facets, err := h.store.SearchProducts(r.Context(), params)
+	params.PriceEdges = h.cfg.PriceHistogramEdges
+	if v := q.Get("price_buckets"); v != "" {
+		edges, err := config.ParsePriceEdges(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params.PriceEdges = edges
+	}
+
+	items, total, facets, priceFacet, err := h.store.SearchProducts(r.Context(), params)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -398,29 +515,41 @@ func (h *Handler) searchProductsHandler(w http.ResponseWriter, r *http.Request)
 	if items == nil {
 		items = []model.ProductCard{}
 	}
+	labels := h.localizeCards(r.Context(), items, params.Locale)
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"items":  items,
-		"facets": facets,
-		"page":   page,
-		"limit":  limit,
-		"total":  total,
-	})
+	lastMod, _ := h.store.CatalogLastModified(r.Context())
+
+	h.writeCachedJSON(w, r, map[string]any{
+		"items":        items,
+		"facets":       facets,
+		"facet_labels": facetLabels(labels, facets),
+		"price":        priceFacet,
+		"page":         page,
+		"limit":        limit,
+		"total":        total,
+		"locale":       params.Locale,
+	}, lastMod)
 }
 
 func (h *Handler) getProductBySlugHandler(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 
-	product, err := h.store.GetProductBySlug(r.Context(), slug)
+	detail, err := h.loadDetailBySlug(w, r, slug)
 	if err != nil {
 		http.Error(w, "not found", http.StatusNotFound)
 		return
 	}
 
-	media, _ := h.store.GetMediaByProductID(r.Context(), product.ID)
+	locale := h.requestLocale(w, r)
+	product, media := h.localizeDetail(r.Context(), detail, locale), detail.Media
+	seo, jsonLD := h.productSEO(r.Context(), product, media)
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"product": product,
-		"media":   media,
-	})
+	h.writeCachedJSON(w, r, map[string]any{
+		"product":    product,
+		"media":      media,
+		"components": detail.Components,
+		"locale":     locale,
+		"seo":        seo,
+		"json_ld":    jsonLD,
+	}, h.detailLastModified(r.Context(), detail))
 }
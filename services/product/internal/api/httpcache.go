@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+)
+
+// writeCachedJSON writes a public catalogue response with validators and
+// Cache-Control, answering 304 Not Modified when the client's copy is still
+// current. lastMod may be zero when the payload has no natural timestamp, in
+// which case only the ETag is used.
+func (h *Handler) writeCachedJSON(w http.ResponseWriter, r *http.Request, v any, lastMod time.Time) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body := buf.Bytes()
+
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	hdr := w.Header()
+	hdr.Set("ETag", etag)
+	hdr.Set("Cache-Control", h.cfg.CatalogCacheControl())
+	if !lastMod.IsZero() {
+		lastMod = lastMod.UTC().Truncate(time.Second)
+		hdr.Set("Last-Modified", lastMod.Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastMod) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	hdr.Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// notModified implements the RFC 9110 precedence: If-None-Match wins, and
+// If-Modified-Since is only consulted when no entity tag was sent.
+func notModified(r *http.Request, etag string, lastMod time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETag(candidate) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastMod.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err == nil && !lastMod.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func weakETag(tag string) string {
+	return strings.TrimPrefix(tag, "W/")
+}
+
+// detailLastModified is the newest change behind a product detail body: the
+// product row, any of its translations, or its reviews, which feed the
+// rating in json_ld. Review timestamps are best effort like the rating.
+func (h *Handler) detailLastModified(ctx context.Context, d *cache.Detail) time.Time {
+	latest := d.Product.UpdatedAt
+	for _, t := range d.Translations {
+		if t.UpdatedAt.After(latest) {
+			latest = t.UpdatedAt
+		}
+	}
+	if t, err := h.store.ReviewsLastModified(ctx, d.Product.ID); err == nil && t.After(latest) {
+		latest = t
+	}
+	return latest
+}
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// requestLocale picks the response locale from ?locale=, then
+// Accept-Language, falling back to English. It marks the response as varying
+// on the header so shared caches keep locales apart.
+func (h *Handler) requestLocale(w http.ResponseWriter, r *http.Request) string {
+	w.Header().Add("Vary", "Accept-Language")
+
+	locale := model.DefaultLocale
+	if v := strings.ToLower(r.URL.Query().Get("locale")); v != "" {
+		if h.cfg.SupportsLocale(primaryTag(v)) {
+			locale = primaryTag(v)
+		}
+	} else if v := r.Header.Get("Accept-Language"); v != "" {
+		locale = h.negotiateLocale(v)
+	}
+
+	w.Header().Set("Content-Language", locale)
+	return locale
+}
+
+// negotiateLocale returns the supported locale with the highest q-value in
+// an Accept-Language header. Regional variants match their language, so
+// "hi-IN" selects "hi".
+func (h *Handler) negotiateLocale(header string) string {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		locale := primaryTag(strings.ToLower(tag))
+		if q > 0 && h.cfg.SupportsLocale(locale) {
+			candidates = append(candidates, candidate{locale, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return model.DefaultLocale
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale
+}
+
+func primaryTag(tag string) string {
+	lang, _, _ := strings.Cut(tag, "-")
+	return lang
+}
+
+// loadLabels returns the category and attribute labels for a locale. Labels
+// are decoration, so a failure degrades to untranslated names.
+func (h *Handler) loadLabels(ctx context.Context, locale string) *model.Labels {
+	if locale == model.DefaultLocale {
+		return nil
+	}
+	labels, err := h.store.GetLabels(ctx, locale)
+	if err != nil {
+		log.Println("load labels:", err)
+		return nil
+	}
+	return labels
+}
+
+func attributeLabels(labels *model.Labels, attrs map[string]any) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := map[string]string{}
+	for attr, v := range attrs {
+		value, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if label := labels.Attributes[attr][value]; label != "" {
+			out[attr] = label
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// localizeProduct returns a copy of p with text from tr and labels applied.
+// Missing fields keep the English values. The cached product is never
+// modified.
+func localizeProduct(p *model.Product, tr *model.ProductTranslation, labels *model.Labels) *model.Product {
+	out := *p
+	if tr != nil {
+		if tr.Title != "" {
+			out.Title = tr.Title
+		}
+		if tr.ShortDesc != "" {
+			out.ShortDesc = tr.ShortDesc
+		}
+		if tr.LongDesc != "" {
+			out.LongDesc = tr.LongDesc
+		}
+	}
+	if labels != nil {
+		out.CategoryLabel = labels.Categories[p.Category]
+		out.AttributeLabels = attributeLabels(labels, p.Attributes)
+	}
+	return &out
+}
+
+// localizeDetail applies a locale to a cached detail's product.
+func (h *Handler) localizeDetail(ctx context.Context, d *cache.Detail, locale string) *model.Product {
+	if locale == model.DefaultLocale {
+		return d.Product
+	}
+	var tr *model.ProductTranslation
+	if t, ok := d.Translations[locale]; ok {
+		tr = &t
+	}
+	return localizeProduct(d.Product, tr, h.loadLabels(ctx, locale))
+}
+
+// localizeProducts applies a locale to listing rows in place.
+func (h *Handler) localizeProducts(ctx context.Context, items []model.Product, locale string) {
+	if locale == model.DefaultLocale || len(items) == 0 {
+		return
+	}
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+	translations, err := h.store.GetTranslationsFor(ctx, ids, locale)
+	if err != nil {
+		log.Println("load translations:", err)
+	}
+	labels := h.loadLabels(ctx, locale)
+	for i := range items {
+		var tr *model.ProductTranslation
+		if t, ok := translations[items[i].ID]; ok {
+			tr = &t
+		}
+		items[i] = *localizeProduct(&items[i], tr, labels)
+	}
+}
+
+// localizeCards applies a locale to search and artisan cards in place and
+// returns the labels used, for facet decoration.
+func (h *Handler) localizeCards(ctx context.Context, items []model.ProductCard, locale string) *model.Labels {
+	if locale == model.DefaultLocale {
+		return nil
+	}
+	labels := h.loadLabels(ctx, locale)
+	if len(items) == 0 {
+		return labels
+	}
+	ids := make([]string, len(items))
+	for i := range items {
+		ids[i] = items[i].ID
+	}
+	translations, err := h.store.GetTranslationsFor(ctx, ids, locale)
+	if err != nil {
+		log.Println("load translations:", err)
+	}
+	for i := range items {
+		if t, ok := translations[items[i].ID]; ok && t.Title != "" {
+			items[i].Title = t.Title
+		}
+		if labels != nil {
+			items[i].CategoryLabel = labels.Categories[items[i].Category]
+			items[i].AttributeLabels = attributeLabels(labels, items[i].Attrs)
+		}
+	}
+	return labels
+}
+
+// facetLabels returns the labels for the facet values present in a search
+// response, keyed like the facets themselves.
+func facetLabels(labels *model.Labels, facets model.Facets) map[string]map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := map[string]map[string]string{}
+	for facet, values := range facets {
+		source := labels.Attributes[facet]
+		if facet == "category" {
+			source = labels.Categories
+		}
+		for value := range values {
+			if label := source[value]; label != "" {
+				if out[facet] == nil {
+					out[facet] = map[string]string{}
+				}
+				out[facet][value] = label
+			}
+		}
+	}
+	return out
+}
+
+// --- ADMIN ---
+
+func (h *Handler) adminLocale(w http.ResponseWriter, r *http.Request) (string, bool) {
+	locale := strings.ToLower(chi.URLParam(r, "locale"))
+	if locale == model.DefaultLocale {
+		http.Error(w, "English text lives on the product itself", http.StatusBadRequest)
+		return "", false
+	}
+	if !h.cfg.SupportsLocale(locale) {
+		http.Error(w, "unsupported locale", http.StatusBadRequest)
+		return "", false
+	}
+	return locale, true
+}
+
+func (h *Handler) listProductTranslationsHandler(w http.ResponseWriter, r *http.Request) {
+	translations, err := h.store.ListProductTranslations(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"translations": translations})
+}
+
+func (h *Handler) putProductTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	locale, ok := h.adminLocale(w, r)
+	if !ok {
+		return
+	}
+
+	var t model.ProductTranslation
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	t.Locale = locale
+
+	if err := h.store.UpsertProductTranslation(r.Context(), id, &t); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.cache.Invalidate(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *Handler) deleteProductTranslationHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	locale, ok := h.adminLocale(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.store.DeleteProductTranslation(r.Context(), id, locale)
+	if errors.Is(err, store.ErrTranslationNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.cache.Invalidate(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+func (h *Handler) getLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	locale, ok := h.adminLocale(w, r)
+	if !ok {
+		return
+	}
+	labels, err := h.store.GetLabels(r.Context(), locale)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+// putLabelsHandler merges category and attribute value labels for a locale.
+// Entries with an empty label are removed; entries not mentioned are kept.
+func (h *Handler) putLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	locale, ok := h.adminLocale(w, r)
+	if !ok {
+		return
+	}
+
+	var body model.Labels
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.SetCategoryLabels(r.Context(), locale, body.Categories); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.store.SetAttributeLabels(r.Context(), locale, body.Attributes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
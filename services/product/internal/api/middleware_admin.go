@@ -17,8 +17,7 @@ func AdminOnly(next http.Handler) http.Handler {
 			return
 		}
 
-		reqKey := r.Header.Get("X-ADMIN-KEY")
-		if reqKey == "" || reqKey != adminKey {
+		if !validAdminKey(r) {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -26,3 +25,10 @@ func AdminOnly(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// validAdminKey reports whether the request carries the configured admin key.
+func validAdminKey(r *http.Request) bool {
+	adminKey := os.Getenv("ADMIN_KEY")
+	reqKey := r.Header.Get("X-ADMIN-KEY")
+	return adminKey != "" && reqKey != "" && reqKey == adminKey
+}
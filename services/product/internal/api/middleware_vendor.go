@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+type ctxKey string
+
+const ctxVendor ctxKey = "vendor"
+
+func hashVendorKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// VendorOnly authenticates a vendor from X-VENDOR-KEY and stores it in the
+// request context. Handlers behind it must scope every read and write to
+// that vendor's products.
+func (h *Handler) VendorOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-VENDOR-KEY")
+		if key == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		vendor, err := h.store.GetVendorByAPIKeyHash(r.Context(), hashVendorKey(key))
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxVendor, vendor)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func vendorFromContext(ctx context.Context) *model.Vendor {
+	v, _ := ctx.Value(ctxVendor).(*model.Vendor)
+	return v
+}
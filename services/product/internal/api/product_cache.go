@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// cacheBypassHeader lets admin tools read straight from the database. It is
+// honoured only alongside a valid X-ADMIN-KEY; the fresh result still
+// refreshes the cache.
+const cacheBypassHeader = "X-CACHE-BYPASS"
+
+func (h *Handler) bypassCache(r *http.Request) bool {
+	return r.Header.Get(cacheBypassHeader) != "" && validAdminKey(r)
+}
+
+// loadDetail is the read-through path for GET /v1/products/{id}.
+func (h *Handler) loadDetail(w http.ResponseWriter, r *http.Request, id string) (*cache.Detail, error) {
+	ctx := r.Context()
+
+	if h.bypassCache(r) {
+		h.cache.RecordBypass()
+		w.Header().Set("X-Cache", "BYPASS")
+	} else if d := h.cache.Get(ctx, id); d != nil {
+		w.Header().Set("X-Cache", "HIT")
+		return d, nil
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+
+	product, err := h.store.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return h.fillDetail(ctx, product)
+}
+
+// loadDetailBySlug is the read-through path for GET /v1/products/slug/{slug}.
+func (h *Handler) loadDetailBySlug(w http.ResponseWriter, r *http.Request, slug string) (*cache.Detail, error) {
+	ctx := r.Context()
+
+	if h.bypassCache(r) {
+		h.cache.RecordBypass()
+		w.Header().Set("X-Cache", "BYPASS")
+	} else if d := h.cache.GetBySlug(ctx, slug); d != nil {
+		w.Header().Set("X-Cache", "HIT")
+		return d, nil
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+
+	product, err := h.store.GetProductBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return h.fillDetail(ctx, product)
+}
+
+// fillDetail loads the rest of a product's detail and caches it.
+func (h *Handler) fillDetail(ctx context.Context, product *model.Product) (*cache.Detail, error) {
+	media, _ := h.store.GetMediaByProductID(ctx, product.ID)
+
+	translations, err := h.store.ListProductTranslations(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &cache.Detail{Product: product, Media: media, Translations: translations}
+	if product.IsBundle {
+		components, err := h.store.GetBundleComponents(ctx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		d.Components = components
+	}
+	h.cache.Put(ctx, d)
+	return d, nil
+}
+
+// invalidateStock drops a product and everything whose stock is derived from
+// or feeds into it (see store.StockDependents). It also nudges the
+// back-in-stock worker, since the change may have been a restock.
+func (h *Handler) invalidateStock(ctx context.Context, productID string) {
+	h.restock.Kick()
+	h.cache.Invalidate(ctx, productID)
+	ids, err := h.store.StockDependents(ctx, productID)
+	if err != nil {
+		log.Println("stock dependents:", err)
+		return
+	}
+	for _, id := range ids {
+		h.cache.Invalidate(ctx, id)
+	}
+}
+
+func (h *Handler) cacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.cache.Stats())
+}
+
+func (h *Handler) cachePurgeHandler(w http.ResponseWriter, r *http.Request) {
+	h.cache.Purge(r.Context())
+	writeJSON(w, http.StatusOK, map[string]string{"status": "purged"})
+}
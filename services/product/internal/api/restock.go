@@ -0,0 +1,206 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/gateway"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// restockIdentity returns the signed-in user behind a request: the owner of
+// a bearer token Supabase accepts, or X-USER-ID on calls carrying the
+// internal service key. A bare X-USER-ID is ignored. The email is the
+// account's confirmed address, if any.
+func (h *Handler) restockIdentity(w http.ResponseWriter, r *http.Request) (userID, email string, ok bool) {
+	if key := os.Getenv("INTERNAL_SERVICE_KEY"); key != "" && r.Header.Get("X-INTERNAL-KEY") == key {
+		return r.Header.Get("X-USER-ID"), "", true
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return "", "", true
+	}
+	id, err := h.auth.VerifyToken(r.Context(), token)
+	if errors.Is(err, gateway.ErrInvalidToken) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return "", "", false
+	}
+	if err != nil {
+		log.Println("verify token:", err)
+		http.Error(w, "could not verify sign-in", http.StatusServiceUnavailable)
+		return "", "", false
+	}
+	return id.UserID, id.Email, true
+}
+
+// newRestockToken returns a random token for the confirmation links and
+// the hash that is stored in its place.
+func newRestockToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRestockToken(token), nil
+}
+
+func hashRestockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// subscribeRestockHandler subscribes the signed-in user, at their account
+// address, or else the email in the body. An email from the body is only
+// notified once its owner follows the confirmation link mailed to it.
+func (h *Handler) subscribeRestockHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var body struct {
+		Email string `json:"email"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, email, ok := h.restockIdentity(w, r)
+	if !ok {
+		return
+	}
+	if userID == "" && body.Email != "" {
+		addr, err := mail.ParseAddress(body.Email)
+		if err != nil {
+			http.Error(w, "invalid email", http.StatusBadRequest)
+			return
+		}
+		email = addr.Address
+	}
+	if userID == "" && email == "" {
+		http.Error(w, "sign in or give an email", http.StatusUnauthorized)
+		return
+	}
+
+	token, hash, err := newRestockToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	confirmation, err := h.store.SubscribeRestock(r.Context(), id, userID, email, hash)
+	switch {
+	case errors.Is(err, store.ErrProductNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, store.ErrNotOutOfStock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, store.ErrAlreadySubscribed):
+		writeJSON(w, http.StatusOK, map[string]string{"status": "already subscribed"})
+	case errors.Is(err, store.ErrConfirmationPending):
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "confirmation pending"})
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case confirmation == nil:
+		writeJSON(w, http.StatusCreated, map[string]string{"status": "subscribed"})
+	default:
+		if err := h.restock.SendConfirmation(r.Context(), confirmation, token); err != nil {
+			log.Printf("restock confirmation for %s failed: %v", id, err)
+			if err := h.store.CancelRestock(r.Context(), hash); err != nil {
+				log.Println("restock cancel failed:", err)
+			}
+			http.Error(w, "could not send confirmation email", http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "confirmation sent"})
+	}
+}
+
+// unsubscribeRestockHandler drops the signed-in user's subscription. Email
+// subscribers cancel with the link in their confirmation email instead.
+func (h *Handler) unsubscribeRestockHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	userID, _, ok := h.restockIdentity(w, r)
+	if !ok {
+		return
+	}
+	if userID == "" {
+		http.Error(w, "sign in, or use the cancel link from your email", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.store.UnsubscribeRestock(r.Context(), id, store.RestockRecipient(userID, ""))
+	if errors.Is(err, store.ErrNotSubscribed) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "unsubscribed"})
+}
+
+// restockToken reads the token from a confirmation link out of the body.
+func restockToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Token == "" {
+		http.Error(w, "token required", http.StatusBadRequest)
+		return "", false
+	}
+	return body.Token, true
+}
+
+func (h *Handler) confirmRestockHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := restockToken(w, r)
+	if !ok {
+		return
+	}
+	err := h.store.ConfirmRestock(r.Context(), hashRestockToken(token))
+	if errors.Is(err, store.ErrInvalidRestockToken) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "subscribed"})
+}
+
+func (h *Handler) cancelRestockHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := restockToken(w, r)
+	if !ok {
+		return
+	}
+	err := h.store.CancelRestock(r.Context(), hashRestockToken(token))
+	if errors.Is(err, store.ErrInvalidRestockToken) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "unsubscribed"})
+}
+
+func (h *Handler) restockSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	n, err := h.store.CountPendingRestock(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"pending": n})
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+// sitemapPageSize keeps each product sitemap well under the 50,000 URL /
+// 50MB limits of the sitemap protocol.
+const sitemapPageSize = 5000
+
+func (h *Handler) categoryURL(category string) string {
+	return h.cfg.SiteURL + "/category/" + url.PathEscape(category)
+}
+
+func (h *Handler) collectionURL(category, subcategory string) string {
+	return h.categoryURL(category) + "?subcategory=" + url.QueryEscape(subcategory)
+}
+
+func sitemapDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// productSEO builds the "seo" block and schema.org Product JSON-LD returned
+// next to a product detail response.
+func (h *Handler) productSEO(ctx context.Context, p *model.Product, media []model.Media) (map[string]any, model.ProductJSONLD) {
+	canonical := h.cfg.ProductURL(p.Slug)
+
+	images := make([]string, 0, len(media))
+	for _, m := range media {
+		if m.MediaType == "" || m.MediaType == "image" {
+			images = append(images, m.URL)
+		}
+	}
+
+	brand := h.cfg.Brand
+	if b, ok := p.Attributes["brand"].(string); ok && b != "" {
+		brand = b
+	}
+
+	availability := "https://schema.org/OutOfStock"
+	if p.InStock {
+		availability = "https://schema.org/InStock"
+	}
+
+	ld := model.ProductJSONLD{
+		Context:     "https://schema.org/",
+		Type:        "Product",
+		Name:        p.Title,
+		Description: p.ShortDesc,
+		SKU:         p.SKU,
+		Category:    p.Category,
+		Image:       images,
+		Brand:       &model.BrandJSONLD{Type: "Brand", Name: brand},
+		Offers: model.OfferJSONLD{
+			Type:          "Offer",
+			URL:           canonical,
+			PriceCurrency: "INR",
+			Price:         strconv.Itoa(p.Price),
+			Availability:  availability,
+			ItemCondition: "https://schema.org/NewCondition",
+		},
+	}
+
+	// Ratings are best effort; a product page must not fail because the
+	// reviews table is unavailable.
+	if avg, count, err := h.store.GetRatingSummary(ctx, p.ID); err == nil && count > 0 {
+		ld.AggregateRating = &model.RatingJSONLD{
+			Type:        "AggregateRating",
+			RatingValue: math.Round(avg*10) / 10,
+			ReviewCount: count,
+		}
+	}
+
+	seo := map[string]any{
+		"title":       p.Title + " | " + h.cfg.Brand,
+		"description": p.ShortDesc,
+		"canonical":   canonical,
+	}
+	return seo, ld
+}
+
+func (h *Handler) registerSitemapRoutes(r chi.Router) {
+	r.Get("/sitemap.xml", h.sitemapIndexHandler)
+	r.Get("/sitemaps/products-{page}.xml", h.productSitemapHandler)
+	r.Get("/sitemaps/categories.xml", h.categorySitemapHandler)
+	r.Get("/sitemaps/collections.xml", h.collectionSitemapHandler)
+}
+
+// sitemapIndexHandler lists every child sitemap. Locations point at the
+// storefront origin, which is expected to proxy /sitemaps/* to the
+// unversioned routes of this service.
+func (h *Handler) sitemapIndexHandler(w http.ResponseWriter, r *http.Request) {
+	count, lastMod, err := h.store.CountSitemapProducts(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pages := (count + sitemapPageSize - 1) / sitemapPageSize
+	if pages == 0 {
+		pages = 1
+	}
+
+	idx := model.SitemapIndex{XMLNS: model.SitemapNS}
+	for i := 1; i <= pages; i++ {
+		idx.Sitemaps = append(idx.Sitemaps, model.SitemapEntry{
+			Loc:     fmt.Sprintf("%s/sitemaps/products-%d.xml", h.cfg.SiteURL, i),
+			LastMod: sitemapDate(lastMod),
+		})
+	}
+	idx.Sitemaps = append(idx.Sitemaps,
+		model.SitemapEntry{Loc: h.cfg.SiteURL + "/sitemaps/categories.xml", LastMod: sitemapDate(lastMod)},
+		model.SitemapEntry{Loc: h.cfg.SiteURL + "/sitemaps/collections.xml", LastMod: sitemapDate(lastMod)},
+	)
+
+	writeXML(w, http.StatusOK, idx)
+}
+
+func (h *Handler) productSitemapHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(chi.URLParam(r, "page"))
+	if err != nil || page < 1 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := h.store.ListSitemapProducts(r.Context(), sitemapPageSize, (page-1)*sitemapPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(rows) == 0 && page > 1 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	set := model.URLSet{XMLNS: model.SitemapNS, URLs: []model.SitemapURL{}}
+	for _, row := range rows {
+		set.URLs = append(set.URLs, model.SitemapURL{
+			Loc:        h.cfg.ProductURL(row.Slug),
+			LastMod:    sitemapDate(row.UpdatedAt),
+			ChangeFreq: "weekly",
+			Priority:   "0.8",
+		})
+	}
+
+	writeXML(w, http.StatusOK, set)
+}
+
+func (h *Handler) categorySitemapHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.store.ListSitemapCategories(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	set := model.URLSet{XMLNS: model.SitemapNS, URLs: []model.SitemapURL{}}
+	for _, row := range rows {
+		set.URLs = append(set.URLs, model.SitemapURL{
+			Loc:        h.categoryURL(row.Slug),
+			LastMod:    sitemapDate(row.UpdatedAt),
+			ChangeFreq: "daily",
+			Priority:   "0.6",
+		})
+	}
+
+	writeXML(w, http.StatusOK, set)
+}
+
+func (h *Handler) collectionSitemapHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.store.ListSitemapCollections(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	set := model.URLSet{XMLNS: model.SitemapNS, URLs: []model.SitemapURL{}}
+	for _, row := range rows {
+		set.URLs = append(set.URLs, model.SitemapURL{
+			Loc:        h.collectionURL(row.Category, row.Slug),
+			LastMod:    sitemapDate(row.UpdatedAt),
+			ChangeFreq: "daily",
+			Priority:   "0.5",
+		})
+	}
+
+	writeXML(w, http.StatusOK, set)
+}
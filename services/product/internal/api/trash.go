@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+
+	items, total, err := h.store.ListDeletedProducts(r.Context(), page, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items":          items,
+		"total":          total,
+		"page":           page,
+		"limit":          limit,
+		"retention_days": h.cfg.TrashRetentionDays,
+	})
+}
+
+func (h *Handler) restoreProductHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.store.RestoreProduct(r.Context(), id)
+	switch {
+	case errors.Is(err, store.ErrNotInTrash):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrRestoreConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.invalidateStock(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+}
+
+func (h *Handler) purgeProductHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	res, err := h.purger.Purge(r.Context(), id)
+	if errors.Is(err, store.ErrNotInTrash) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrInBundle) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
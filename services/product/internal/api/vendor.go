@@ -0,0 +1,317 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// --- ADMIN: VENDOR MANAGEMENT ---
+
+func (h *Handler) listVendorsHandler(w http.ResponseWriter, r *http.Request) {
+	vendors, err := h.store.ListVendors(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": vendors})
+}
+
+func (h *Handler) getVendorHandler(w http.ResponseWriter, r *http.Request) {
+	vendor, err := h.store.GetVendor(r.Context(), chi.URLParam(r, "vendor_id"))
+	if errors.Is(err, store.ErrVendorNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, vendor)
+}
+
+// decodeVendor reads a vendor body. Active is returned apart so callers can
+// tell an omitted "active" from false: a new vendor defaults to active, and
+// an update keeps the current value.
+func decodeVendor(r *http.Request) (*model.Vendor, *bool, error) {
+	var body struct {
+		model.Vendor
+		Active *bool `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, nil, err
+	}
+	v := body.Vendor
+	if v.Slug == "" || v.Name == "" {
+		return nil, nil, errors.New("slug and name are required")
+	}
+	if v.CommissionRate < 0 || v.CommissionRate > 100 {
+		return nil, nil, errors.New("commission_rate must be between 0 and 100")
+	}
+	return &v, body.Active, nil
+}
+
+func (h *Handler) createVendorHandler(w http.ResponseWriter, r *http.Request) {
+	v, active, err := decodeVendor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	v.Active = active == nil || *active
+
+	id, err := h.store.CreateVendor(r.Context(), v)
+	if errors.Is(err, store.ErrDuplicateVendor) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (h *Handler) updateVendorHandler(w http.ResponseWriter, r *http.Request) {
+	v, active, err := decodeVendor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vendorID := chi.URLParam(r, "vendor_id")
+	if active != nil {
+		v.Active = *active
+	} else {
+		cur, err := h.store.GetVendor(r.Context(), vendorID)
+		if errors.Is(err, store.ErrVendorNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v.Active = cur.Active
+	}
+
+	err = h.store.UpdateVendor(r.Context(), vendorID, v)
+	switch {
+	case errors.Is(err, store.ErrVendorNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, store.ErrDuplicateVendor):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+// rotateVendorKeyHandler issues a new vendor API key. The plaintext key is
+// returned once; only its hash is stored.
+func (h *Handler) rotateVendorKeyHandler(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	key := "vk_" + hex.EncodeToString(buf)
+
+	err := h.store.SetVendorAPIKeyHash(r.Context(), chi.URLParam(r, "vendor_id"), hashVendorKey(key))
+	if errors.Is(err, store.ErrVendorNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"api_key": key})
+}
+
+func (h *Handler) assignProductVendorHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var body struct {
+		VendorID *string `json:"vendor_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if body.VendorID != nil && *body.VendorID == "" {
+		body.VendorID = nil
+	}
+
+	if err := h.store.AssignProductVendor(r.Context(), id, body.VendorID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.cache.Invalidate(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+// --- VENDOR-SCOPED ADMIN ---
+
+func (h *Handler) vendorMeHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, vendorFromContext(r.Context()))
+}
+
+func (h *Handler) vendorListProductsHandler(w http.ResponseWriter, r *http.Request) {
+	vendor := vendorFromContext(r.Context())
+
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 {
+		limit = 10
+	}
+
+	items, total, err := h.store.ListVendorProducts(r.Context(), vendor.ID, page, limit, q.Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+func (h *Handler) vendorCreateProductHandler(w http.ResponseWriter, r *http.Request) {
+	vendor := vendorFromContext(r.Context())
+
+	var req model.Product
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.VendorID = &vendor.ID
+
+	id, err := h.store.CreateProduct(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"id": id})
+}
+
+func (h *Handler) vendorUpdateProductHandler(w http.ResponseWriter, r *http.Request) {
+	vendor := vendorFromContext(r.Context())
+	id := chi.URLParam(r, "id")
+
+	if !h.requireVendorProduct(w, r, vendor.ID, id) {
+		return
+	}
+
+	var req model.Product
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.VendorID = &vendor.ID
+
+	if err := h.store.UpdateProduct(r.Context(), id, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.invalidateStock(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *Handler) vendorSetStockHandler(w http.ResponseWriter, r *http.Request) {
+	vendor := vendorFromContext(r.Context())
+	id := chi.URLParam(r, "id")
+
+	if !h.requireVendorProduct(w, r, vendor.ID, id) {
+		return
+	}
+
+	var body struct {
+		Stock int `json:"stock"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.SetStock(r.Context(), id, body.Stock); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.invalidateStock(r.Context(), id)
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *Handler) requireVendorProduct(w http.ResponseWriter, r *http.Request, vendorID, productID string) bool {
+	err := h.store.CheckVendorOwnership(r.Context(), vendorID, productID)
+	if errors.Is(err, store.ErrNotVendorOwned) {
+		// 404 rather than 403 so vendors cannot probe other vendors' IDs
+		http.Error(w, "not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// --- PUBLIC ---
+
+func (h *Handler) artisanProfileHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 50 {
+		limit = 12
+	}
+
+	artisan, err := h.store.GetArtisanProfile(r.Context(), chi.URLParam(r, "slug"))
+	if errors.Is(err, store.ErrVendorNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	items, total, err := h.store.ListArtisanProducts(r.Context(), artisan.ID, limit, (page-1)*limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	locale := h.requestLocale(w, r)
+	h.localizeCards(r.Context(), items, locale)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"artisan": artisan,
+		"items":   items,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+		"locale":  locale,
+	})
+}
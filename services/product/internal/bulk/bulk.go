@@ -0,0 +1,146 @@
+// Package bulk applies one admin operation to many products, either as a
+// single transaction or as a tracked background job.
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+)
+
+const (
+	// MaxTransactionItems bounds synchronous requests so one transaction
+	// never holds row locks for long.
+	MaxTransactionItems = 500
+	// MaxJobItems bounds background jobs.
+	MaxJobItems = 10000
+
+	// progressEvery is how many items a job processes between progress
+	// writes.
+	progressEvery = 50
+)
+
+type Runner struct {
+	store *store.Store
+	cache *cache.ProductCache
+	// base outlives requests so jobs keep running after the response, but
+	// stops with the service.
+	base context.Context
+}
+
+func NewRunner(base context.Context, s *store.Store, pc *cache.ProductCache) *Runner {
+	return &Runner{store: s, cache: pc, base: base}
+}
+
+func (r *Runner) prepare(ctx context.Context, req *model.BulkRequest, limit int) ([]string, error) {
+	if err := req.Operation.Validate(); err != nil {
+		return nil, err
+	}
+	return r.store.ResolveBulkSelector(ctx, req.Selector, limit)
+}
+
+// Run applies the operation to every selected product atomically and
+// reports per item.
+func (r *Runner) Run(ctx context.Context, req *model.BulkRequest) (*model.BulkReport, error) {
+	ids, err := r.prepare(ctx, req, MaxTransactionItems)
+	if err != nil {
+		return nil, &InvalidError{err}
+	}
+
+	rep := &model.BulkReport{
+		Mode:      "transaction",
+		Operation: req.Operation,
+		Total:     len(ids),
+		CreatedAt: time.Now(),
+	}
+	results, committed, err := r.store.ApplyBulkTx(ctx, ids, req.Operation)
+	if err != nil {
+		return nil, err
+	}
+	finished := time.Now()
+	rep.Results, rep.Committed, rep.FinishedAt = results, committed, &finished
+	rep.Status = model.BulkJobCompleted
+	if !committed {
+		rep.Status = model.BulkJobFailed
+		rep.Error = "rolled back: an item failed"
+	}
+	for _, res := range results {
+		switch res.Status {
+		case model.BulkItemOK:
+			rep.Succeeded++
+			r.cache.Invalidate(ctx, res.ProductID)
+		case model.BulkItemFailed:
+			rep.Failed++
+		}
+	}
+	return rep, nil
+}
+
+// Start records a job and applies the operation to each product
+// independently in the background. Failures are recorded per item and do
+// not stop the job.
+func (r *Runner) Start(ctx context.Context, req *model.BulkRequest) (*model.BulkReport, error) {
+	ids, err := r.prepare(ctx, req, MaxJobItems)
+	if err != nil {
+		return nil, &InvalidError{err}
+	}
+
+	rep, err := r.store.CreateBulkJob(ctx, req.Operation, len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	job := *rep
+	go r.runJob(&job, ids)
+	return rep, nil
+}
+
+func (r *Runner) runJob(rep *model.BulkReport, ids []string) {
+	ctx := r.base
+	for i, id := range ids {
+		if ctx.Err() != nil {
+			rep.Status = model.BulkJobFailed
+			rep.Error = "interrupted by shutdown"
+			break
+		}
+
+		res := model.BulkItemResult{ProductID: id, Status: model.BulkItemOK}
+		if err := r.store.ApplyBulkOperation(ctx, id, rep.Operation); err != nil {
+			res.Status, res.Error = model.BulkItemFailed, err.Error()
+			rep.Failed++
+		} else {
+			rep.Succeeded++
+			r.cache.Invalidate(ctx, id)
+		}
+		rep.Results = append(rep.Results, res)
+
+		if (i+1)%progressEvery == 0 {
+			if err := r.store.SaveBulkJob(ctx, rep, false); err != nil {
+				log.Println("bulk job progress:", err)
+			}
+		}
+	}
+
+	if rep.Status == model.BulkJobRunning {
+		rep.Status = model.BulkJobCompleted
+	}
+	// Use a fresh context so an interrupted job still records where it
+	// stopped.
+	saveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.store.SaveBulkJob(saveCtx, rep, true); err != nil {
+		log.Println("bulk job finish:", err)
+	}
+}
+
+// InvalidError wraps request problems (bad operation, empty or oversized
+// selection) so handlers can answer 400.
+type InvalidError struct{ Err error }
+
+func (e *InvalidError) Error() string { return fmt.Sprintf("invalid bulk request: %v", e.Err) }
+func (e *InvalidError) Unwrap() error { return e.Err }
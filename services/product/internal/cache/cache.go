@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix         = "productsvc:cache:"
+	invalidateChannel = "productsvc:cache:invalidate"
+	purgeAll          = "*"
+)
+
+// Detail is what the product detail endpoints render: the product row plus
+// its media, translations and, for bundles, the components, exactly as
+// loaded from the store. Localisation is applied per request on top.
+type Detail struct {
+	Product      *model.Product                      `json:"product"`
+	Media        []model.Media                       `json:"media"`
+	Components   []model.BundleComponent             `json:"components,omitempty"`
+	Translations map[string]model.ProductTranslation `json:"translations,omitempty"`
+}
+
+type Stats struct {
+	LocalHits     uint64  `json:"local_hits"`
+	RedisHits     uint64  `json:"redis_hits"`
+	Misses        uint64  `json:"misses"`
+	Bypasses      uint64  `json:"bypasses"`
+	Invalidations uint64  `json:"invalidations"`
+	LocalEntries  int     `json:"local_entries"`
+	HitRatio      float64 `json:"hit_ratio"`
+	RedisEnabled  bool    `json:"redis_enabled"`
+}
+
+// ProductCache is a read-through cache for product detail lookups. It keeps
+// an in-process LRU in front of an optional shared Redis tier; invalidations
+// are broadcast over Redis pub/sub so every instance drops its local copy.
+type ProductCache struct {
+	local *lru
+	rdb   *redis.Client
+	ttl   time.Duration
+
+	localHits     atomic.Uint64
+	redisHits     atomic.Uint64
+	misses        atomic.Uint64
+	bypasses      atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// New builds a cache holding up to size entries locally. redisAddr may be
+// empty to run with the in-process tier only.
+func New(size int, ttl time.Duration, redisAddr, redisPassword string) (*ProductCache, error) {
+	c := &ProductCache{
+		local: newLRU(size, ttl),
+		ttl:   ttl,
+	}
+	if redisAddr == "" {
+		return c, nil
+	}
+
+	c.rdb = redis.NewClient(&redis.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+	})
+	if err := c.rdb.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run listens for invalidations published by other instances until ctx is
+// cancelled. It is a no-op without a Redis tier.
+func (c *ProductCache) Run(ctx context.Context) {
+	if c.rdb == nil {
+		return
+	}
+	sub := c.rdb.Subscribe(ctx, invalidateChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			if msg.Payload == purgeAll {
+				c.local.purge()
+			} else {
+				c.local.delete(idKey(msg.Payload))
+			}
+		}
+	}
+}
+
+func idKey(id string) string     { return keyPrefix + "id:" + id }
+func slugKey(slug string) string { return keyPrefix + "slug:" + slug }
+
+// get reads a key from the local tier, then Redis, promoting Redis hits into
+// the local LRU. The returned tier is "local", "redis" or "".
+func (c *ProductCache) get(ctx context.Context, key string) ([]byte, string) {
+	if b, ok := c.local.get(key); ok {
+		return b, "local"
+	}
+	if c.rdb == nil {
+		return nil, ""
+	}
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Println("product cache redis get:", err)
+		}
+		return nil, ""
+	}
+	c.local.set(key, b)
+	return b, "redis"
+}
+
+func (c *ProductCache) set(ctx context.Context, key string, b []byte) {
+	c.local.set(key, b)
+	if c.rdb == nil {
+		return
+	}
+	if err := c.rdb.Set(ctx, key, b, c.ttl).Err(); err != nil {
+		log.Println("product cache redis set:", err)
+	}
+}
+
+func (c *ProductCache) detail(ctx context.Context, id string) (*Detail, string) {
+	b, tier := c.get(ctx, idKey(id))
+	if b == nil {
+		return nil, ""
+	}
+	var d Detail
+	if err := json.Unmarshal(b, &d); err != nil || d.Product == nil {
+		return nil, ""
+	}
+	return &d, tier
+}
+
+func (c *ProductCache) record(tier string) {
+	switch tier {
+	case "local":
+		c.localHits.Add(1)
+	case "redis":
+		c.redisHits.Add(1)
+	default:
+		c.misses.Add(1)
+	}
+}
+
+// Get returns the cached detail for a product ID, or nil on a miss.
+func (c *ProductCache) Get(ctx context.Context, id string) *Detail {
+	d, tier := c.detail(ctx, id)
+	c.record(tier)
+	return d
+}
+
+// GetBySlug resolves a slug through its slug -> id mapping. The entry only
+// counts as a hit if it still describes a live, published product with that
+// slug, matching what the store's slug query would return.
+func (c *ProductCache) GetBySlug(ctx context.Context, slug string) *Detail {
+	id, _ := c.get(ctx, slugKey(slug))
+	if id == nil {
+		c.record("")
+		return nil
+	}
+	d, tier := c.detail(ctx, string(id))
+	if d == nil || d.Product.Slug != slug || !d.Product.Published || d.Product.DeletedAt != nil {
+		c.record("")
+		return nil
+	}
+	c.record(tier)
+	return d
+}
+
+// Put stores a freshly loaded detail under its ID and slug.
+func (c *ProductCache) Put(ctx context.Context, d *Detail) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+	c.set(ctx, idKey(d.Product.ID), b)
+	c.set(ctx, slugKey(d.Product.Slug), []byte(d.Product.ID))
+}
+
+// RecordBypass counts a lookup that deliberately skipped the cache.
+func (c *ProductCache) RecordBypass() {
+	c.bypasses.Add(1)
+}
+
+// Invalidate drops a product from every tier and every instance. Slug
+// mappings are left to expire; GetBySlug re-validates them on read.
+func (c *ProductCache) Invalidate(ctx context.Context, productID string) {
+	if productID == "" {
+		return
+	}
+	c.invalidations.Add(1)
+	c.local.delete(idKey(productID))
+	if c.rdb == nil {
+		return
+	}
+	if err := c.rdb.Del(ctx, idKey(productID)).Err(); err != nil {
+		log.Println("product cache redis del:", err)
+	}
+	c.rdb.Publish(ctx, invalidateChannel, productID)
+}
+
+// Purge empties the cache everywhere.
+func (c *ProductCache) Purge(ctx context.Context) {
+	c.invalidations.Add(1)
+	c.local.purge()
+	if c.rdb == nil {
+		return
+	}
+	iter := c.rdb.Scan(ctx, 0, keyPrefix+"*", 500).Iterator()
+	for iter.Next(ctx) {
+		c.rdb.Del(ctx, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		log.Println("product cache redis purge:", err)
+	}
+	c.rdb.Publish(ctx, invalidateChannel, purgeAll)
+}
+
+func (c *ProductCache) Stats() Stats {
+	s := Stats{
+		LocalHits:     c.localHits.Load(),
+		RedisHits:     c.redisHits.Load(),
+		Misses:        c.misses.Load(),
+		Bypasses:      c.bypasses.Load(),
+		Invalidations: c.invalidations.Load(),
+		LocalEntries:  c.local.len(),
+		RedisEnabled:  c.rdb != nil,
+	}
+	if total := s.LocalHits + s.RedisHits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.LocalHits+s.RedisHits) / float64(total)
+	}
+	return s
+}
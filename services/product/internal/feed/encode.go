@@ -0,0 +1,200 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+)
+
+func encode(f Format, items []Item, cfg *config.Config) ([]byte, error) {
+	switch f {
+	case GoogleXML:
+		return encodeGoogleXML(items, cfg)
+	case GoogleTSV:
+		return encodeGoogleTSV(items)
+	case MetaCSV:
+		return encodeMetaCSV(items)
+	}
+	return nil, fmt.Errorf("unknown feed format %q", f)
+}
+
+func money(rupees int) string {
+	return fmt.Sprintf("%d.00 INR", rupees)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// --- GOOGLE MERCHANT (RSS 2.0 + g: namespace) ---
+
+type googleRSS struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	NSG     string        `xml:"xmlns:g,attr"`
+	Channel googleChannel `xml:"channel"`
+}
+
+type googleChannel struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	Description string       `xml:"description"`
+	Items       []googleItem `xml:"item"`
+}
+
+type googleItem struct {
+	ID                    string   `xml:"g:id"`
+	Title                 string   `xml:"g:title"`
+	Description           string   `xml:"g:description"`
+	Link                  string   `xml:"g:link"`
+	ImageLink             string   `xml:"g:image_link"`
+	AdditionalImageLinks  []string `xml:"g:additional_image_link,omitempty"`
+	Availability          string   `xml:"g:availability"`
+	Price                 string   `xml:"g:price"`
+	SalePrice             string   `xml:"g:sale_price,omitempty"`
+	Brand                 string   `xml:"g:brand"`
+	GTIN                  string   `xml:"g:gtin,omitempty"`
+	MPN                   string   `xml:"g:mpn,omitempty"`
+	Condition             string   `xml:"g:condition"`
+	Color                 string   `xml:"g:color,omitempty"`
+	Material              string   `xml:"g:material,omitempty"`
+	ProductType           string   `xml:"g:product_type,omitempty"`
+	GoogleProductCategory string   `xml:"g:google_product_category,omitempty"`
+}
+
+func googleAvailability(inStock bool) string {
+	if inStock {
+		return "in_stock"
+	}
+	return "out_of_stock"
+}
+
+func encodeGoogleXML(items []Item, cfg *config.Config) ([]byte, error) {
+	feed := googleRSS{
+		Version: "2.0",
+		NSG:     "http://base.google.com/ns/1.0",
+		Channel: googleChannel{
+			Title:       cfg.Brand,
+			Link:        cfg.SiteURL,
+			Description: cfg.Brand + " product catalogue",
+		},
+	}
+	for _, it := range items {
+		gi := googleItem{
+			ID:                    it.ID,
+			Title:                 it.Title,
+			Description:           it.Description,
+			Link:                  it.Link,
+			ImageLink:             it.ImageLink,
+			AdditionalImageLinks:  it.ExtraImages,
+			Availability:          googleAvailability(it.InStock),
+			Price:                 money(it.Price),
+			Brand:                 it.Brand,
+			GTIN:                  it.GTIN,
+			MPN:                   it.MPN,
+			Condition:             "new",
+			Color:                 it.Color,
+			Material:              it.Material,
+			ProductType:           it.ProductType,
+			GoogleProductCategory: it.GoogleCategory,
+		}
+		if it.SalePrice > 0 {
+			gi.SalePrice = money(it.SalePrice)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, gi)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// --- GOOGLE MERCHANT (tab separated) ---
+
+var googleTSVHeader = []string{
+	"id", "title", "description", "link", "image_link", "additional_image_link",
+	"availability", "price", "sale_price", "brand", "gtin", "mpn", "condition",
+	"color", "material", "product_type", "google_product_category",
+}
+
+// tsvField strips characters that would break a tab separated row; Google's
+// TSV parser does not support quoting.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func encodeGoogleTSV(items []Item) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(googleTSVHeader, "\t"))
+	buf.WriteByte('\n')
+
+	for _, it := range items {
+		sale := ""
+		if it.SalePrice > 0 {
+			sale = money(it.SalePrice)
+		}
+		row := []string{
+			it.ID, it.Title, it.Description, it.Link, it.ImageLink,
+			strings.Join(it.ExtraImages, ","),
+			googleAvailability(it.InStock), money(it.Price), sale,
+			it.Brand, it.GTIN, it.MPN, "new",
+			it.Color, it.Material, it.ProductType, it.GoogleCategory,
+		}
+		for i := range row {
+			row[i] = tsvField(row[i])
+		}
+		buf.WriteString(strings.Join(row, "\t"))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// --- META COMMERCE (CSV) ---
+
+var metaCSVHeader = []string{
+	"id", "title", "description", "availability", "condition", "price",
+	"sale_price", "link", "image_link", "additional_image_link", "brand",
+	"gtin", "mpn", "color", "material", "product_type", "google_product_category",
+}
+
+func encodeMetaCSV(items []Item) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(metaCSVHeader); err != nil {
+		return nil, err
+	}
+
+	for _, it := range items {
+		availability := "out of stock"
+		if it.InStock {
+			availability = "in stock"
+		}
+		sale := ""
+		if it.SalePrice > 0 {
+			sale = money(it.SalePrice)
+		}
+		if err := w.Write([]string{
+			it.ID, it.Title, it.Description, availability, "new", money(it.Price),
+			sale, it.Link, it.ImageLink, strings.Join(it.ExtraImages, ","), it.Brand,
+			it.GTIN, it.MPN, it.Color, it.Material, it.ProductType, it.GoogleCategory,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,252 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+)
+
+type Format string
+
+const (
+	GoogleXML Format = "google.xml"
+	GoogleTSV Format = "google.tsv"
+	MetaCSV   Format = "meta.csv"
+)
+
+var Formats = []Format{GoogleXML, GoogleTSV, MetaCSV}
+
+func (f Format) ContentType() string {
+	switch f {
+	case GoogleXML:
+		return "application/xml; charset=utf-8"
+	case GoogleTSV:
+		return "text/tab-separated-values; charset=utf-8"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+const (
+	maxTitleLen       = 150
+	maxDescriptionLen = 5000
+	maxExtraImages    = 10
+)
+
+// Item is the marketplace-neutral view of one product. Encoders map it to
+// each channel's column names and value conventions.
+type Item struct {
+	ID             string
+	Title          string
+	Description    string
+	Link           string
+	ImageLink      string
+	ExtraImages    []string
+	InStock        bool
+	Price          int // rupees; MRP when the product is discounted
+	SalePrice      int // rupees; 0 when not discounted
+	Brand          string
+	GTIN           string
+	MPN            string
+	Color          string
+	Material       string
+	ProductType    string
+	GoogleCategory string
+}
+
+// Issue is a per-item validation finding. Items with an "error" issue are
+// left out of every feed; warnings are reported but the item is kept.
+type Issue struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku"`
+	Field     string `json:"field"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+type Snapshot struct {
+	GeneratedAt time.Time
+	ItemCount   int
+	Excluded    int
+	Issues      []Issue
+	Files       map[Format][]byte
+}
+
+type Generator struct {
+	store *store.Store
+	cfg   *config.Config
+
+	mu     sync.RWMutex
+	latest *Snapshot
+}
+
+func NewGenerator(s *store.Store, cfg *config.Config) *Generator {
+	return &Generator{store: s, cfg: cfg}
+}
+
+// Latest returns the most recently generated snapshot, or nil if feeds have
+// not been built yet.
+func (g *Generator) Latest() *Snapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.latest
+}
+
+// Regenerate rebuilds every feed format from the published catalogue and
+// swaps it in atomically.
+func (g *Generator) Regenerate(ctx context.Context) (*Snapshot, error) {
+	products, err := g.store.ListFeedProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		GeneratedAt: time.Now().UTC(),
+		Issues:      []Issue{},
+		Files:       map[Format][]byte{},
+	}
+
+	items := make([]Item, 0, len(products))
+	for i := range products {
+		item, issues := g.buildItem(&products[i])
+		snap.Issues = append(snap.Issues, issues...)
+		if hasError(issues) {
+			snap.Excluded++
+			continue
+		}
+		items = append(items, item)
+	}
+	snap.ItemCount = len(items)
+
+	for _, f := range Formats {
+		b, err := encode(f, items, g.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", f, err)
+		}
+		snap.Files[f] = b
+	}
+
+	g.mu.Lock()
+	g.latest = snap
+	g.mu.Unlock()
+
+	return snap, nil
+}
+
+func (g *Generator) buildItem(p *model.Product) (Item, []Issue) {
+	var issues []Issue
+	report := func(field, severity, msg string) {
+		issues = append(issues, Issue{
+			ProductID: p.ID,
+			SKU:       p.SKU,
+			Field:     field,
+			Severity:  severity,
+			Message:   msg,
+		})
+	}
+
+	item := Item{
+		ID:             p.SKU,
+		Title:          p.Title,
+		Description:    p.LongDesc,
+		Link:           g.cfg.ProductURL(p.Slug),
+		InStock:        p.InStock,
+		Price:          p.Price,
+		Brand:          g.cfg.Brand,
+		MPN:            p.SKU,
+		Color:          attrString(p.Attributes, "color"),
+		Material:       attrString(p.Attributes, "fabric"),
+		GTIN:           attrString(p.Attributes, "gtin"),
+		GoogleCategory: g.cfg.FeedGoogleCategory,
+	}
+	if item.ID == "" {
+		item.ID = p.ID
+		report("sku", "warning", "product has no SKU; using product id as feed id")
+	}
+	if b := attrString(p.Attributes, "brand"); b != "" {
+		item.Brand = b
+	}
+
+	item.ProductType = p.Category
+	if p.Subcat != "" {
+		item.ProductType += " > " + p.Subcat
+	}
+
+	if t := truncate(item.Title, maxTitleLen); t != item.Title {
+		item.Title = t
+		report("title", "warning", fmt.Sprintf("title truncated to %d characters", maxTitleLen))
+	}
+
+	if item.Description == "" {
+		item.Description = p.ShortDesc
+	}
+	if item.Description == "" {
+		item.Description = p.Title
+		report("description", "warning", "no description; falling back to title")
+	}
+	if d := truncate(item.Description, maxDescriptionLen); d != item.Description {
+		item.Description = d
+		report("description", "warning", fmt.Sprintf("description truncated to %d characters", maxDescriptionLen))
+	}
+
+	for _, m := range p.Media {
+		if m.MediaType != "" && m.MediaType != "image" {
+			continue
+		}
+		if item.ImageLink == "" {
+			item.ImageLink = m.URL
+		} else if len(item.ExtraImages) < maxExtraImages {
+			item.ExtraImages = append(item.ExtraImages, m.URL)
+		}
+	}
+	if item.ImageLink == "" {
+		report("image_link", "error", "product has no image")
+	}
+
+	if p.Price <= 0 {
+		report("price", "error", "price must be greater than zero")
+	}
+	if p.MRP != nil {
+		switch {
+		case *p.MRP > p.Price:
+			item.Price = *p.MRP
+			item.SalePrice = p.Price
+		case *p.MRP < p.Price:
+			report("mrp", "warning", "mrp is lower than price; mrp ignored")
+		}
+	}
+
+	if item.GTIN == "" {
+		report("gtin", "warning", "no gtin attribute; item relies on brand + mpn")
+	}
+	if item.Color == "" {
+		report("color", "warning", "no color attribute")
+	}
+	if item.Material == "" {
+		report("material", "warning", "no fabric attribute to map to material")
+	}
+
+	return item, issues
+}
+
+func attrString(attrs map[string]interface{}, key string) string {
+	if v, ok := attrs[key]; ok && v != nil {
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+	return ""
+}
+
+func hasError(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,8 @@ package gateway
 import (
 	"context"
 	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -47,3 +49,27 @@ func (s *S3Gateway) GenerateUploadURL(key string, contentType string) (string, e
 
 	return req.URL, nil
 }
+
+func (s *S3Gateway) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// KeyFromURL maps a public object URL back to its key. It reports false for
+// URLs that do not point into this gateway's bucket, so externally hosted
+// media is never touched.
+func (s *S3Gateway) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || s.Bucket == "" {
+		return "", false
+	}
+	host := strings.ToLower(u.Host)
+	if !strings.HasPrefix(host, strings.ToLower(s.Bucket)+".s3.") || !strings.HasSuffix(host, ".amazonaws.com") {
+		return "", false
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	return key, key != ""
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken means Supabase rejected the bearer token.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Identity is the signed-in user a bearer token belongs to.
+type Identity struct {
+	UserID string
+	Email  string
+}
+
+// SupabaseGateway verifies access tokens by asking Supabase who they
+// belong to, as the cart and reviews services do.
+type SupabaseGateway struct {
+	base    string
+	anonKey string
+	c       *http.Client
+}
+
+func NewSupabaseGateway(baseURL, anonKey string) *SupabaseGateway {
+	return &SupabaseGateway{
+		base:    strings.TrimRight(baseURL, "/"),
+		anonKey: anonKey,
+		c:       &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// VerifyToken returns the user a bearer token was issued to. Only a
+// confirmed email is returned.
+func (g *SupabaseGateway) VerifyToken(ctx context.Context, token string) (*Identity, error) {
+	if g.base == "" {
+		return nil, fmt.Errorf("SUPABASE_URL not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.base+"/auth/v1/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("apikey", g.anonKey)
+
+	resp, err := g.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ErrInvalidToken
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("supabase returned %d", resp.StatusCode)
+	}
+
+	var body struct {
+		ID               string `json:"id"`
+		Email            string `json:"email"`
+		EmailConfirmedAt string `json:"email_confirmed_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	id := &Identity{UserID: body.ID}
+	if body.EmailConfirmedAt != "" {
+		id.Email = body.Email
+	}
+	return id, nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// InventoryGroup aggregates live products sharing one dimension value.
+// Values are in rupees; CostValue only covers products with a cost price.
+type InventoryGroup struct {
+	Key            string  `json:"key"`
+	Products       int     `json:"products"`
+	UnitsOnHand    int     `json:"units_on_hand"`
+	UnitsReserved  int     `json:"units_reserved"`
+	UnitsAvailable int     `json:"units_available"`
+	UnitsSold      int     `json:"units_sold"`
+	SellThrough    float64 `json:"sell_through"`
+	RetailValue    int64   `json:"retail_value"`
+	CostValue      int64   `json:"cost_value"`
+	Uncosted       int     `json:"uncosted_products"`
+}
+
+type DeadStockItem struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	SKU         string     `json:"sku"`
+	Category    string     `json:"category"`
+	Stock       int        `json:"stock"`
+	RetailValue int64      `json:"retail_value"`
+	LastSoldAt  *time.Time `json:"last_sold_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type InventoryAnalytics struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Totals        InventoryGroup `json:"totals"`
+	Published     int            `json:"published_products"`
+	LowStock      int            `json:"low_stock_products"`
+	OutOfStock    int            `json:"out_of_stock_products"`
+	LowStockBelow int            `json:"low_stock_threshold"`
+
+	ByCategory []InventoryGroup `json:"by_category"`
+	ByWeave    []InventoryGroup `json:"by_weave"`
+	ByOrigin   []InventoryGroup `json:"by_origin"`
+
+	DeadStockDays  int             `json:"dead_stock_days"`
+	DeadStockCount int             `json:"dead_stock_count"`
+	DeadStockValue int64           `json:"dead_stock_retail_value"`
+	DeadStock      []DeadStockItem `json:"dead_stock"`
+}
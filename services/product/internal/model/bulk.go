@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Bulk operation types.
+const (
+	BulkPublish      = "publish"
+	BulkUnpublish    = "unpublish"
+	BulkSetCategory  = "set_category"
+	BulkAddTags      = "add_tags"
+	BulkRemoveTags   = "remove_tags"
+	BulkAdjustPrice  = "adjust_price"
+	BulkSetAttribute = "set_attribute"
+)
+
+// BulkSelector picks the products a bulk operation applies to: explicit IDs
+// or a filter over live products, not both.
+type BulkSelector struct {
+	IDs    []string    `json:"ids,omitempty"`
+	Filter *BulkFilter `json:"filter,omitempty"`
+}
+
+type BulkFilter struct {
+	Category    string  `json:"category,omitempty"`
+	Subcategory string  `json:"subcategory,omitempty"`
+	Tag         string  `json:"tag,omitempty"`
+	VendorID    string  `json:"vendor_id,omitempty"`
+	Published   *bool   `json:"published,omitempty"`
+	Query       string  `json:"q,omitempty"`
+	Attribute   string  `json:"attribute,omitempty"`
+	Value       *string `json:"value,omitempty"`
+}
+
+// BulkOperation is one change applied to every selected product. Only the
+// fields relevant to Type are read.
+type BulkOperation struct {
+	Type string `json:"type"`
+
+	Category    string  `json:"category,omitempty"`
+	Subcategory *string `json:"subcategory,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+
+	// adjust_price: exactly one of Percent (e.g. -20 for 20% off) or
+	// Amount (rupees, may be negative).
+	Percent *float64 `json:"percent,omitempty"`
+	Amount  *int     `json:"amount,omitempty"`
+
+	// set_attribute: a null Value removes the attribute.
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func (op *BulkOperation) Validate() error {
+	switch op.Type {
+	case BulkPublish, BulkUnpublish:
+	case BulkSetCategory:
+		if op.Category == "" {
+			return fmt.Errorf("set_category needs category")
+		}
+	case BulkAddTags, BulkRemoveTags:
+		if len(op.Tags) == 0 {
+			return fmt.Errorf("%s needs tags", op.Type)
+		}
+	case BulkAdjustPrice:
+		if (op.Percent == nil) == (op.Amount == nil) {
+			return fmt.Errorf("adjust_price needs exactly one of percent or amount")
+		}
+		if op.Percent != nil && *op.Percent <= -100 {
+			return fmt.Errorf("percent must be greater than -100")
+		}
+	case BulkSetAttribute:
+		if op.Key == "" {
+			return fmt.Errorf("set_attribute needs key")
+		}
+		if len(op.Value) > 0 && !json.Valid(op.Value) {
+			return fmt.Errorf("set_attribute value must be valid JSON")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q", op.Type)
+	}
+	return nil
+}
+
+type BulkRequest struct {
+	Selector  BulkSelector  `json:"selector"`
+	Operation BulkOperation `json:"operation"`
+	// Mode is "transaction" (default: all or nothing, synchronous) or "job"
+	// (each product independently, in the background).
+	Mode string `json:"mode,omitempty"`
+}
+
+// Per-item outcomes.
+const (
+	BulkItemOK      = "ok"
+	BulkItemFailed  = "failed"
+	BulkItemSkipped = "skipped"
+)
+
+type BulkItemResult struct {
+	ProductID string `json:"product_id"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Job states.
+const (
+	BulkJobRunning   = "running"
+	BulkJobCompleted = "completed"
+	BulkJobFailed    = "failed"
+)
+
+// BulkReport is the outcome of a bulk operation, returned directly in
+// transaction mode and tracked as a job otherwise.
+type BulkReport struct {
+	JobID      string           `json:"job_id,omitempty"`
+	Mode       string           `json:"mode"`
+	Status     string           `json:"status"`
+	Operation  BulkOperation    `json:"operation"`
+	Total      int              `json:"total"`
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	Committed  bool             `json:"committed"`
+	Error      string           `json:"error,omitempty"`
+	Results    []BulkItemResult `json:"results"`
+	CreatedAt  time.Time        `json:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package model
+
+// BundleItem is one component line when defining a bundle.
+type BundleItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// BundleComponent is a component as shown on the bundle's detail page.
+type BundleComponent struct {
+	ProductID string `json:"product_id"`
+	Slug      string `json:"slug"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+	Price     int    `json:"price"`
+	Stock     int    `json:"stock"`
+	InStock   bool   `json:"in_stock"`
+	ImageURL  string `json:"image_url"`
+}
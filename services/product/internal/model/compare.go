@@ -0,0 +1,53 @@
+package model
+
+// Attribute value types.
+const (
+	AttrText    = "text"
+	AttrNumber  = "number"
+	AttrBoolean = "boolean"
+)
+
+// AttributeDefinition describes how a products.attributes key is shown.
+type AttributeDefinition struct {
+	Key        string `json:"key"`
+	Label      string `json:"label"`
+	ValueType  string `json:"value_type"`
+	Unit       string `json:"unit,omitempty"`
+	Position   int    `json:"position"`
+	Comparable bool   `json:"comparable"`
+}
+
+// CompareProduct is a column header of the comparison grid.
+type CompareProduct struct {
+	ID       string `json:"id"`
+	Slug     string `json:"slug"`
+	Title    string `json:"title"`
+	ImageURL string `json:"image_url"`
+	Price    int    `json:"price"`
+	MRP      *int   `json:"mrp,omitempty"`
+	InStock  bool   `json:"in_stock"`
+}
+
+// CompareCell is one product's value in a row. Missing is true when the
+// product has no value, so clients need not guess from nulls.
+type CompareCell struct {
+	Value   any    `json:"value"`
+	Label   string `json:"label,omitempty"`
+	Missing bool   `json:"missing"`
+}
+
+// CompareRow is one attribute across all compared products, in product
+// order. Same is true when every product has the same, present value.
+type CompareRow struct {
+	Key    string        `json:"key"`
+	Label  string        `json:"label"`
+	Unit   string        `json:"unit,omitempty"`
+	Same   bool          `json:"same"`
+	Values []CompareCell `json:"values"`
+}
+
+// RatingSummary is the approved-review average and count of a product.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
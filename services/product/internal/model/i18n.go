@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// DefaultLocale is the language of the text stored on the products row and
+// the fallback for any missing translation.
+const DefaultLocale = "en"
+
+// ProductTranslation holds a product's copy in one locale. Empty fields fall
+// back to the English text.
+type ProductTranslation struct {
+	Locale    string    `json:"locale"`
+	Title     string    `json:"title"`
+	ShortDesc string    `json:"short_desc"`
+	LongDesc  string    `json:"long_desc"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Labels are the display names for category slugs and attribute values in
+// one locale.
+type Labels struct {
+	Categories map[string]string            `json:"categories"`
+	Attributes map[string]map[string]string `json:"attributes"`
+}
@@ -19,12 +19,28 @@ type Product struct {
 	StockReserved int    `json:"stock_reserved"`
 	InStock       bool   `json:"in_stock"` // Computed field
 
+	// CostPrice is the purchase cost per unit. It is admin-only: public
+	// reads never select it.
+	CostPrice *int `json:"cost_price,omitempty"`
+
+	// IsBundle products sell a fixed set of components as one line; their
+	// stock is derived from component stock.
+	IsBundle bool `json:"is_bundle"`
+
+	VendorID *string `json:"vendor_id,omitempty"`
+
 	Attributes map[string]interface{} `json:"attributes"`
 	Tags       []string               `json:"tags"`
 
-	Published bool      `json:"published"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	// Localised display names, filled on public reads for non-default
+	// locales; raw Category and Attributes stay the filterable values.
+	CategoryLabel   string            `json:"category_label,omitempty"`
+	AttributeLabels map[string]string `json:"attribute_labels,omitempty"`
+
+	Published bool       `json:"published"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 
 	// ImageURL is often the first image from Media
 	ImageURL string  `json:"image_url"`
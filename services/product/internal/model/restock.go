@@ -0,0 +1,21 @@
+package model
+
+// RestockNotice is a claimed back-in-stock subscription ready to be sent.
+type RestockNotice struct {
+	SubscriptionID string `json:"subscription_id"`
+	ProductID      string `json:"product_id"`
+	Slug           string `json:"slug"`
+	Title          string `json:"title"`
+	Recipient      string `json:"recipient"`
+	UserID         string `json:"user_id,omitempty"`
+	Email          string `json:"email,omitempty"`
+}
+
+// RestockConfirmation asks an email subscriber to confirm their address
+// before any notice is sent to it.
+type RestockConfirmation struct {
+	ProductID string `json:"product_id"`
+	Slug      string `json:"slug"`
+	Title     string `json:"title"`
+	Email     string `json:"email"`
+}
@@ -12,6 +12,12 @@ type SearchParams struct {
 	Sort     string
 	Page     int
 	Limit    int
+
+	// Locale also matches Query against that locale's translations.
+	Locale string
+
+	// PriceEdges are the ascending bucket boundaries of the price histogram.
+	PriceEdges []int
 }
 
 type ProductCard struct {
@@ -25,8 +31,26 @@ type ProductCard struct {
 	Attrs     map[string]any `json:"attributes"`
 	VariantID string         `json:"variant_id"`
 	InStock   bool           `json:"in_stock"`
+
+	CategoryLabel   string            `json:"category_label,omitempty"`
+	AttributeLabels map[string]string `json:"attribute_labels,omitempty"`
 }
 
 type FacetCounts map[string]map[string]int
 
 type Facets map[string]map[string]int
+
+// PriceFacet summarises effective prices over the matched set, ignoring the
+// price filter itself.
+type PriceFacet struct {
+	Min     int           `json:"min"`
+	Max     int           `json:"max"`
+	Buckets []PriceBucket `json:"buckets"`
+}
+
+// PriceBucket covers [Min, Max); a nil Min or Max is open-ended.
+type PriceBucket struct {
+	Min   *int `json:"min"`
+	Max   *int `json:"max"`
+	Count int  `json:"count"`
+}
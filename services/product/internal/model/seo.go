@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// --- SITEMAPS (sitemaps.org protocol 0.9) ---
+
+const SitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type SitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	XMLNS    string         `xml:"xmlns,attr"`
+	Sitemaps []SitemapEntry `xml:"sitemap"`
+}
+
+type SitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// SitemapRow is one indexable page as read from the catalogue.
+// Slug is the product slug for products, the category name for
+// categories and the subcategory name for collections.
+type SitemapRow struct {
+	Slug      string
+	Category  string
+	UpdatedAt time.Time
+}
+
+// --- STRUCTURED DATA (schema.org JSON-LD) ---
+
+type ProductJSONLD struct {
+	Context         string        `json:"@context"`
+	Type            string        `json:"@type"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	SKU             string        `json:"sku,omitempty"`
+	Category        string        `json:"category,omitempty"`
+	Image           []string      `json:"image,omitempty"`
+	Brand           *BrandJSONLD  `json:"brand,omitempty"`
+	Offers          OfferJSONLD   `json:"offers"`
+	AggregateRating *RatingJSONLD `json:"aggregateRating,omitempty"`
+}
+
+type BrandJSONLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type OfferJSONLD struct {
+	Type          string `json:"@type"`
+	URL           string `json:"url"`
+	PriceCurrency string `json:"priceCurrency"`
+	Price         string `json:"price"`
+	Availability  string `json:"availability"`
+	ItemCondition string `json:"itemCondition"`
+}
+
+type RatingJSONLD struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	ReviewCount int     `json:"reviewCount"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type Vendor struct {
+	ID             string    `json:"id"`
+	Slug           string    `json:"slug"`
+	Name           string    `json:"name"`
+	Bio            string    `json:"bio"`
+	City           string    `json:"city"`
+	State          string    `json:"state"`
+	PayoutRef      string    `json:"payout_ref"`
+	CommissionRate float64   `json:"commission_rate"` // percent of sale price
+	Active         bool      `json:"active"`
+	HasAPIKey      bool      `json:"has_api_key"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ArtisanProfile is the public face of a vendor; payout and commission
+// details are never exposed.
+type ArtisanProfile struct {
+	ID    string `json:"id"`
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Bio   string `json:"bio"`
+	City  string `json:"city"`
+	State string `json:"state"`
+}
@@ -0,0 +1,167 @@
+// Package notify delivers back-in-stock notices, and the confirmations
+// email subscribers get first, through a configurable channel: the log
+// (default), SMTP or a webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// Message is a notice with the storefront link resolved.
+type Message struct {
+	model.RestockNotice
+	URL string `json:"url"`
+}
+
+// Confirmation asks an email subscriber to confirm, or cancel, their
+// subscription.
+type Confirmation struct {
+	model.RestockConfirmation
+	ConfirmURL string `json:"confirm_url"`
+	CancelURL  string `json:"cancel_url"`
+}
+
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+	SendConfirmation(ctx context.Context, c Confirmation) error
+}
+
+// NewSender builds the sender selected by RESTOCK_NOTIFIER.
+func NewSender(cfg *config.Config) (Sender, error) {
+	switch cfg.RestockNotifier {
+	case "", "log":
+		return LogSender{}, nil
+	case "smtp":
+		if cfg.SMTPAddr == "" || cfg.SMTPFrom == "" {
+			return nil, fmt.Errorf("smtp notifier needs SMTP_ADDR and SMTP_FROM")
+		}
+		return &SMTPSender{
+			addr:     cfg.SMTPAddr,
+			from:     cfg.SMTPFrom,
+			user:     cfg.SMTPUser,
+			password: cfg.SMTPPassword,
+			brand:    cfg.Brand,
+		}, nil
+	case "webhook":
+		if cfg.RestockWebhookURL == "" {
+			return nil, fmt.Errorf("webhook notifier needs RESTOCK_WEBHOOK_URL")
+		}
+		return &WebhookSender{
+			url: cfg.RestockWebhookURL,
+			c:   &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.RestockNotifier)
+	}
+}
+
+// LogSender only logs; useful in development and as a safe default.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, m Message) error {
+	log.Printf("back in stock: %s -> %s (%s)", m.Title, m.Recipient, m.URL)
+	return nil
+}
+
+// SendConfirmation leaves the links out of the log: they carry the token.
+func (LogSender) SendConfirmation(ctx context.Context, c Confirmation) error {
+	log.Printf("confirm back-in-stock subscription: %s -> %s", c.Title, c.Email)
+	return nil
+}
+
+// SMTPSender emails subscribers. Signed-in users are mailed at their
+// confirmed account address; a user without one fails here, so use the
+// webhook to reach them some other way.
+type SMTPSender struct {
+	addr     string
+	from     string
+	user     string
+	password string
+	brand    string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, m Message) error {
+	if m.Email == "" {
+		return fmt.Errorf("subscription has no email address")
+	}
+
+	subject := fmt.Sprintf("Back in stock: %s", m.Title)
+	body := fmt.Sprintf("Good news! %s is available again at %s.\r\n\r\n%s\r\n", m.Title, s.brand, m.URL)
+	return s.mail(m.Email, subject, body)
+}
+
+func (s *SMTPSender) SendConfirmation(ctx context.Context, c Confirmation) error {
+	subject := fmt.Sprintf("Confirm your back-in-stock alert for %s", c.Title)
+	body := fmt.Sprintf("We'll email you when %s is back at %s. Confirm your address to turn the alert on:\r\n\r\n%s\r\n\r\n"+
+		"Didn't ask for this? Ignore this email, or cancel the alert:\r\n\r\n%s\r\n",
+		c.Title, s.brand, c.ConfirmURL, c.CancelURL)
+	return s.mail(c.Email, subject, body)
+}
+
+func (s *SMTPSender) mail(to, subject, body string) error {
+	var auth smtp.Auth
+	if s.user != "" {
+		host, _, _ := strings.Cut(s.addr, ":")
+		auth = smtp.PlainAuth("", s.user, s.password, host)
+	}
+
+	msg := "From: " + s.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(s.addr, auth, s.from, []string{to}, []byte(msg))
+}
+
+// WebhookSender POSTs each notice and confirmation as JSON, leaving
+// delivery (push, SMS, email by user ID) to the receiver. Confirmations
+// carry "type": "confirmation". Any non-2xx response is a failure.
+type WebhookSender struct {
+	url string
+	c   *http.Client
+}
+
+func (s *WebhookSender) Send(ctx context.Context, m Message) error {
+	return s.post(ctx, m)
+}
+
+func (s *WebhookSender) SendConfirmation(ctx context.Context, c Confirmation) error {
+	return s.post(ctx, struct {
+		Type string `json:"type"`
+		Confirmation
+	}{"confirmation", c})
+}
+
+func (s *WebhookSender) post(ctx context.Context, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
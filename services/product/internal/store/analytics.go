@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// deadStockListLimit caps the dead stock rows returned; the count and value
+// always cover all of them.
+const deadStockListLimit = 100
+
+// InventoryAnalytics computes the admin inventory report. The per-dimension
+// breakdowns come from a single GROUPING SETS scan over live products.
+// Sell-through is units sold / (units sold + units on hand), where on hand
+// includes reserved units.
+func (s *Store) InventoryAnalytics(ctx context.Context, deadDays, lowStock int) (*model.InventoryAnalytics, error) {
+	a := &model.InventoryAnalytics{
+		GeneratedAt:   time.Now().UTC(),
+		LowStockBelow: lowStock,
+		DeadStockDays: deadDays,
+		ByCategory:    []model.InventoryGroup{},
+		ByWeave:       []model.InventoryGroup{},
+		ByOrigin:      []model.InventoryGroup{},
+		DeadStock:     []model.DeadStockItem{},
+	}
+
+	rows, err := s.db.Query(ctx, `
+    SELECT
+      CASE
+        WHEN GROUPING(category) = 0 THEN 'category'
+        WHEN GROUPING(attributes->>'weave') = 0 THEN 'weave'
+        WHEN GROUPING(attributes->>'origin') = 0 THEN 'origin'
+        ELSE 'total'
+      END AS dimension,
+      COALESCE(
+        CASE
+          WHEN GROUPING(category) = 0 THEN category
+          WHEN GROUPING(attributes->>'weave') = 0 THEN attributes->>'weave'
+          WHEN GROUPING(attributes->>'origin') = 0 THEN attributes->>'origin'
+        END, ''
+      ) AS key,
+      COUNT(*),
+      COALESCE(SUM(stock + stock_reserved), 0),
+      COALESCE(SUM(stock_reserved), 0),
+      COALESCE(SUM(stock), 0),
+      COALESCE(SUM(units_sold), 0),
+      COALESCE(SUM((stock + stock_reserved)::bigint * price), 0),
+      COALESCE(SUM((stock + stock_reserved)::bigint * cost_price), 0),
+      COUNT(*) FILTER (WHERE cost_price IS NULL AND stock + stock_reserved > 0)
+    FROM products
+    WHERE deleted_at IS NULL AND NOT is_bundle
+    GROUP BY GROUPING SETS ((), (category), (attributes->>'weave'), (attributes->>'origin'))
+    ORDER BY 1, 8 DESC
+  `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dim string
+		var g model.InventoryGroup
+		if err := rows.Scan(
+			&dim, &g.Key, &g.Products, &g.UnitsOnHand, &g.UnitsReserved, &g.UnitsAvailable,
+			&g.UnitsSold, &g.RetailValue, &g.CostValue, &g.Uncosted,
+		); err != nil {
+			return nil, err
+		}
+		if total := g.UnitsSold + g.UnitsOnHand; total > 0 {
+			g.SellThrough = float64(g.UnitsSold) / float64(total)
+		}
+		switch dim {
+		case "total":
+			a.Totals = g
+		case "category":
+			a.ByCategory = append(a.ByCategory, g)
+		case "weave":
+			a.ByWeave = append(a.ByWeave, g)
+		case "origin":
+			a.ByOrigin = append(a.ByOrigin, g)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.QueryRow(ctx, `
+    SELECT
+      COUNT(*) FILTER (WHERE published),
+      COUNT(*) FILTER (WHERE stock > 0 AND stock < $1),
+      COUNT(*) FILTER (WHERE stock <= 0)
+    FROM products
+    WHERE deleted_at IS NULL
+  `, lowStock).Scan(&a.Published, &a.LowStock, &a.OutOfStock); err != nil {
+		return nil, err
+	}
+
+	// Dead stock: units on hand, and no sale (or, never sold, no listing)
+	// within the window.
+	const deadWhere = `
+    WHERE deleted_at IS NULL AND NOT is_bundle AND stock > 0
+      AND COALESCE(last_sold_at, created_at) < now() - make_interval(days => $1)`
+
+	if err := s.db.QueryRow(ctx, `
+    SELECT COUNT(*), COALESCE(SUM(stock::bigint * price), 0)
+    FROM products`+deadWhere, deadDays).Scan(&a.DeadStockCount, &a.DeadStockValue); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(ctx, `
+    SELECT id, title, COALESCE(sku, ''), category, stock, stock::bigint * price, last_sold_at, created_at
+    FROM products`+deadWhere+`
+    ORDER BY stock::bigint * price DESC
+    LIMIT $2
+  `, deadDays, deadStockListLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d model.DeadStockItem
+		if err := rows.Scan(&d.ID, &d.Title, &d.SKU, &d.Category, &d.Stock, &d.RetailValue, &d.LastSoldAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.DeadStock = append(a.DeadStock, d)
+	}
+	return a, rows.Err()
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+var ErrAttributeNotFound = errors.New("attribute definition not found")
+
+func (s *Store) ListAttributeDefinitions(ctx context.Context) ([]model.AttributeDefinition, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT key, label, value_type, COALESCE(unit, ''), position, comparable
+    FROM attribute_definitions
+    ORDER BY position, key
+  `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.AttributeDefinition{}
+	for rows.Next() {
+		var d model.AttributeDefinition
+		if err := rows.Scan(&d.Key, &d.Label, &d.ValueType, &d.Unit, &d.Position, &d.Comparable); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) UpsertAttributeDefinition(ctx context.Context, d *model.AttributeDefinition) error {
+	var unit *string
+	if d.Unit != "" {
+		unit = &d.Unit
+	}
+	_, err := s.db.Exec(ctx, `
+    INSERT INTO attribute_definitions (key, label, value_type, unit, position, comparable)
+    VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (key) DO UPDATE SET
+      label = EXCLUDED.label,
+      value_type = EXCLUDED.value_type,
+      unit = EXCLUDED.unit,
+      position = EXCLUDED.position,
+      comparable = EXCLUDED.comparable
+  `, d.Key, d.Label, d.ValueType, unit, d.Position, d.Comparable)
+	return err
+}
+
+func (s *Store) DeleteAttributeDefinition(ctx context.Context, key string) error {
+	cmd, err := s.db.Exec(ctx, `DELETE FROM attribute_definitions WHERE key = $1`, key)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrAttributeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// GetProductsByIDs loads several live products with their media, keyed by
+// ID. Unpublished products are included so callers can act on the flag;
+// deleted or unknown IDs are simply absent.
+func (s *Store) GetProductsByIDs(ctx context.Context, ids []string) (map[string]*model.Product, error) {
+	out := map[string]*model.Product{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	rows, err := s.db.Query(ctx, `
+    SELECT id, slug, title,
+           COALESCE(short_desc, ''),
+           category,
+           COALESCE(subcategory, ''),
+           price, mrp, stock,
+           COALESCE(sku, ''),
+           attributes, tags, published, created_at, updated_at,
+           vendor_id, is_bundle
+    FROM products
+    WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+  `, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make([]string, 0, len(ids))
+	for rows.Next() {
+		var p model.Product
+		var attrs []byte
+		if err := rows.Scan(
+			&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.Category, &p.Subcat,
+			&p.Price, &p.MRP, &p.Stock, &p.SKU,
+			&attrs, &p.Tags, &p.Published, &p.CreatedAt, &p.UpdatedAt,
+			&p.VendorID, &p.IsBundle,
+		); err != nil {
+			return nil, err
+		}
+		p.InStock = p.Stock > 0
+		_ = json.Unmarshal(attrs, &p.Attributes)
+		p.Media = []model.Media{}
+		out[p.ID] = &p
+		found = append(found, p.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mediaMap, err := s.getMediaForProducts(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+	for id, media := range mediaMap {
+		if p := out[id]; p != nil {
+			p.Media = media
+			if len(media) > 0 {
+				p.ImageURL = media[0].URL
+			}
+		}
+	}
+	return out, nil
+}
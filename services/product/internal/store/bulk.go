@@ -0,0 +1,304 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrBulkJobNotFound = errors.New("bulk job not found")
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// ResolveBulkSelector turns a selector into product IDs. Explicit IDs are
+// de-duplicated but not checked, so unknown ones surface as per-item
+// failures; a filter only ever matches live products.
+func (s *Store) ResolveBulkSelector(ctx context.Context, sel model.BulkSelector, limit int) ([]string, error) {
+	if len(sel.IDs) > 0 && sel.Filter != nil {
+		return nil, fmt.Errorf("selector takes ids or filter, not both")
+	}
+
+	if sel.Filter == nil {
+		seen := map[string]bool{}
+		var ids []string
+		for _, id := range sel.IDs {
+			if id = strings.TrimSpace(id); id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("selector matched no products")
+		}
+		if len(ids) > limit {
+			return nil, fmt.Errorf("selector matched %d products, limit is %d", len(ids), limit)
+		}
+		return ids, nil
+	}
+
+	f := sel.Filter
+	where := []string{"deleted_at IS NULL"}
+	args := []any{}
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Category != "" {
+		add("category = $%d", f.Category)
+	}
+	if f.Subcategory != "" {
+		add("subcategory = $%d", f.Subcategory)
+	}
+	if f.Tag != "" {
+		add("$%d = ANY(tags)", f.Tag)
+	}
+	if f.VendorID != "" {
+		add("vendor_id = $%d", f.VendorID)
+	}
+	if f.Published != nil {
+		add("published = $%d", *f.Published)
+	}
+	if f.Query != "" {
+		args = append(args, "%"+f.Query+"%")
+		where = append(where, fmt.Sprintf("(title ILIKE $%d OR sku ILIKE $%d)", len(args), len(args)))
+	}
+	if f.Attribute != "" {
+		if f.Value != nil {
+			args = append(args, f.Attribute, *f.Value)
+			where = append(where, fmt.Sprintf("attributes->>$%d = $%d", len(args)-1, len(args)))
+		} else {
+			add("attributes ? $%d", f.Attribute)
+		}
+	}
+	if len(where) == 1 {
+		return nil, fmt.Errorf("filter needs at least one condition")
+	}
+
+	// Fetch one past the limit to tell "exactly limit" from "too many".
+	args = append(args, limit+1)
+	rows, err := s.db.Query(ctx, fmt.Sprintf(`
+    SELECT id FROM products
+    WHERE %s
+    ORDER BY created_at
+    LIMIT $%d
+  `, strings.Join(where, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("selector matched no products")
+	}
+	if len(ids) > limit {
+		return nil, fmt.Errorf("selector matched more than %d products", limit)
+	}
+	return ids, nil
+}
+
+// bulkStatement builds the UPDATE for one product. $1 is always the
+// product ID.
+func bulkStatement(op model.BulkOperation) (string, []any) {
+	const live = ` WHERE id = $1 AND deleted_at IS NULL`
+	switch op.Type {
+	case model.BulkPublish:
+		return `UPDATE products SET published = true` + live, nil
+	case model.BulkUnpublish:
+		return `UPDATE products SET published = false` + live, nil
+	case model.BulkSetCategory:
+		return `UPDATE products SET category = $2, subcategory = COALESCE($3, subcategory)` + live,
+			[]any{op.Category, op.Subcategory}
+	case model.BulkAddTags:
+		return `
+      UPDATE products SET tags = COALESCE(tags, '{}') || ARRAY(
+        SELECT unnest($2::text[]) EXCEPT SELECT unnest(COALESCE(tags, '{}'))
+      )` + live, []any{op.Tags}
+	case model.BulkRemoveTags:
+		return `
+      UPDATE products SET tags = ARRAY(
+        SELECT t FROM unnest(COALESCE(tags, '{}')) t WHERE t <> ALL($2::text[])
+      )` + live, []any{op.Tags}
+	case model.BulkAdjustPrice:
+		if op.Percent != nil {
+			return `
+        UPDATE products SET price = ROUND(price * (100 + $2::numeric) / 100)::int` + live +
+				` AND ROUND(price * (100 + $2::numeric) / 100) > 0`, []any{*op.Percent}
+		}
+		return `UPDATE products SET price = price + $2` + live + ` AND price + $2 > 0`, []any{*op.Amount}
+	case model.BulkSetAttribute:
+		if len(op.Value) == 0 || string(op.Value) == "null" {
+			return `UPDATE products SET attributes = COALESCE(attributes, '{}') - $2` + live, []any{op.Key}
+		}
+		return `UPDATE products SET attributes = jsonb_set(COALESCE(attributes, '{}'), ARRAY[$2], $3::jsonb)` + live,
+			[]any{op.Key, string(op.Value)}
+	}
+	return "", nil
+}
+
+// ApplyBulkOperation changes one product on its own.
+func (s *Store) ApplyBulkOperation(ctx context.Context, productID string, op model.BulkOperation) error {
+	return s.applyBulk(ctx, s.db, productID, op)
+}
+
+func (s *Store) applyBulk(ctx context.Context, q execer, productID string, op model.BulkOperation) error {
+	stmt, extra := bulkStatement(op)
+	if stmt == "" {
+		return fmt.Errorf("unknown operation %q", op.Type)
+	}
+	cmd, err := q.Exec(ctx, stmt, append([]any{productID}, extra...)...)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		if op.Type == model.BulkAdjustPrice {
+			return fmt.Errorf("product not found or price would drop to zero")
+		}
+		return fmt.Errorf("product not found")
+	}
+	return nil
+}
+
+// ApplyBulkTx applies op to every product in one transaction. The first
+// failure rolls everything back and every other item is reported skipped.
+func (s *Store) ApplyBulkTx(ctx context.Context, ids []string, op model.BulkOperation) ([]model.BulkItemResult, bool, error) {
+	results := make([]model.BulkItemResult, len(ids))
+	for i, id := range ids {
+		results[i] = model.BulkItemResult{ProductID: id, Status: model.BulkItemSkipped}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, id := range ids {
+		if err := s.applyBulk(ctx, tx, id, op); err != nil {
+			results[i].Status = model.BulkItemFailed
+			results[i].Error = err.Error()
+			return results, false, nil
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	for i := range results {
+		results[i].Status = model.BulkItemOK
+	}
+	return results, true, nil
+}
+
+// --- BULK JOBS ---
+
+func (s *Store) CreateBulkJob(ctx context.Context, op model.BulkOperation, total int) (*model.BulkReport, error) {
+	opJSON, err := json.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	r := &model.BulkReport{
+		Mode:      "job",
+		Status:    model.BulkJobRunning,
+		Operation: op,
+		Total:     total,
+		Results:   []model.BulkItemResult{},
+	}
+	err = s.db.QueryRow(ctx, `
+    INSERT INTO bulk_jobs (status, operation, total)
+    VALUES ($1, $2, $3)
+    RETURNING id, created_at
+  `, r.Status, opJSON, total).Scan(&r.JobID, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SaveBulkJob writes a job's progress. finished also stamps finished_at.
+func (s *Store) SaveBulkJob(ctx context.Context, r *model.BulkReport, finished bool) error {
+	results, err := json.Marshal(r.Results)
+	if err != nil {
+		return err
+	}
+	var errMsg *string
+	if r.Error != "" {
+		errMsg = &r.Error
+	}
+	_, err = s.db.Exec(ctx, `
+    UPDATE bulk_jobs SET
+      status = $2, succeeded = $3, failed = $4, error = $5, results = $6,
+      finished_at = CASE WHEN $7 THEN now() ELSE finished_at END
+    WHERE id = $1
+  `, r.JobID, r.Status, r.Succeeded, r.Failed, errMsg, results, finished)
+	return err
+}
+
+func (s *Store) GetBulkJob(ctx context.Context, id string) (*model.BulkReport, error) {
+	var r model.BulkReport
+	var opJSON, results []byte
+	var errMsg *string
+	err := s.db.QueryRow(ctx, `
+    SELECT id, status, operation, total, succeeded, failed, error, results, created_at, finished_at
+    FROM bulk_jobs WHERE id = $1
+  `, id).Scan(&r.JobID, &r.Status, &opJSON, &r.Total, &r.Succeeded, &r.Failed, &errMsg, &results, &r.CreatedAt, &r.FinishedAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrBulkJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Mode = "job"
+	r.Committed = r.Status == model.BulkJobCompleted
+	if errMsg != nil {
+		r.Error = *errMsg
+	}
+	_ = json.Unmarshal(opJSON, &r.Operation)
+	_ = json.Unmarshal(results, &r.Results)
+	return &r, nil
+}
+
+func (s *Store) ListBulkJobs(ctx context.Context, limit int) ([]model.BulkReport, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT id, status, operation, total, succeeded, failed, created_at, finished_at
+    FROM bulk_jobs
+    ORDER BY created_at DESC
+    LIMIT $1
+  `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.BulkReport{}
+	for rows.Next() {
+		var r model.BulkReport
+		var opJSON []byte
+		if err := rows.Scan(&r.JobID, &r.Status, &opJSON, &r.Total, &r.Succeeded, &r.Failed, &r.CreatedAt, &r.FinishedAt); err != nil {
+			return nil, err
+		}
+		r.Mode = "job"
+		r.Committed = r.Status == model.BulkJobCompleted
+		_ = json.Unmarshal(opJSON, &r.Operation)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
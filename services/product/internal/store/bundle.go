@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// bundleItems returns a bundle's components ordered by component id, or
+// nothing if the product is not a bundle.
+func (s *Store) bundleItems(ctx context.Context, q querier, bundleID string) ([]model.BundleItem, error) {
+	rows, err := q.Query(ctx, `
+    SELECT bi.component_id, bi.quantity
+    FROM product_bundle_items bi
+    JOIN products b ON b.id = bi.bundle_id
+    WHERE bi.bundle_id = $1 AND b.is_bundle
+    ORDER BY bi.component_id
+  `, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.BundleItem
+	for rows.Next() {
+		var it model.BundleItem
+		if err := rows.Scan(&it.ProductID, &it.Quantity); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// GetBundleComponents returns the display view of a bundle's components.
+func (s *Store) GetBundleComponents(ctx context.Context, bundleID string) ([]model.BundleComponent, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT c.id, c.slug, c.title, bi.quantity, c.price,
+           CASE WHEN c.deleted_at IS NULL THEN c.stock ELSE 0 END,
+           COALESCE((SELECT url FROM product_media WHERE product_id = c.id ORDER BY (meta->>'order')::int LIMIT 1), '')
+    FROM product_bundle_items bi
+    JOIN products c ON c.id = bi.component_id
+    WHERE bi.bundle_id = $1
+    ORDER BY c.title
+  `, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.BundleComponent{}
+	for rows.Next() {
+		var c model.BundleComponent
+		if err := rows.Scan(&c.ProductID, &c.Slug, &c.Title, &c.Quantity, &c.Price, &c.Stock, &c.ImageURL); err != nil {
+			return nil, err
+		}
+		c.InStock = c.Stock >= c.Quantity
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// StockDependents returns the products whose derived stock or component
+// view changes when productID's stock does: its own components if it is a
+// bundle, every bundle that contains it, and every bundle sharing one of its
+// components.
+func (s *Store) StockDependents(ctx context.Context, productID string) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT DISTINCT id FROM (
+      SELECT component_id FROM product_bundle_items WHERE bundle_id = $1
+      UNION
+      SELECT bundle_id FROM product_bundle_items WHERE component_id = $1
+      UNION
+      SELECT other.bundle_id
+      FROM product_bundle_items own
+      JOIN product_bundle_items other ON other.component_id = own.component_id
+      WHERE own.bundle_id = $1
+    ) t(id)
+    WHERE id <> $1
+  `, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetBundle turns a product into a bundle of the given components,
+// replacing any previous definition, and derives its stock.
+func (s *Store) SetBundle(ctx context.Context, bundleID string, items []model.BundleItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("%w: a bundle needs at least one component", ErrInvalidBundle)
+	}
+	seen := map[string]bool{}
+	for _, it := range items {
+		if it.ProductID == "" || it.Quantity <= 0 {
+			return fmt.Errorf("%w: each component needs a product_id and a positive quantity", ErrInvalidBundle)
+		}
+		if it.ProductID == bundleID {
+			return fmt.Errorf("%w: a bundle cannot contain itself", ErrInvalidBundle)
+		}
+		if seen[it.ProductID] {
+			return fmt.Errorf("%w: duplicate component %s", ErrInvalidBundle, it.ProductID)
+		}
+		seen[it.ProductID] = true
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Nesting is not supported: a bundle that is a component of another
+	// bundle cannot become a bundle, and components cannot be bundles.
+	var isComponent bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1)`, bundleID).Scan(&isComponent); err != nil {
+		return err
+	}
+	if isComponent {
+		return fmt.Errorf("%w: product is a component of another bundle", ErrInvalidBundle)
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	var valid int
+	if err := tx.QueryRow(ctx, `
+    SELECT COUNT(*) FROM products
+    WHERE id = ANY($1) AND deleted_at IS NULL AND NOT is_bundle
+  `, ids).Scan(&valid); err != nil {
+		return err
+	}
+	if valid != len(ids) {
+		return fmt.Errorf("%w: components must be existing, non-bundle products", ErrInvalidBundle)
+	}
+
+	cmd, err := tx.Exec(ctx, `UPDATE products SET is_bundle = true WHERE id = $1 AND deleted_at IS NULL`, bundleID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("product not found")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_bundle_items WHERE bundle_id = $1`, bundleID); err != nil {
+		return err
+	}
+	for _, it := range items {
+		if _, err := tx.Exec(ctx, `
+      INSERT INTO product_bundle_items (bundle_id, component_id, quantity)
+      VALUES ($1, $2, $3)
+    `, bundleID, it.ProductID, it.Quantity); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+    UPDATE products b
+    SET stock = COALESCE((
+      SELECT MIN(c.stock / bi.quantity)
+      FROM product_bundle_items bi
+      JOIN products c ON c.id = bi.component_id
+      WHERE bi.bundle_id = b.id
+    ), 0)
+    WHERE b.id = $1
+  `, bundleID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveBundle turns a bundle back into a plain product. Its stock keeps the
+// last derived value and becomes directly editable again.
+func (s *Store) RemoveBundle(ctx context.Context, bundleID string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_bundle_items WHERE bundle_id = $1`, bundleID); err != nil {
+		return err
+	}
+	cmd, err := tx.Exec(ctx, `UPDATE products SET is_bundle = false WHERE id = $1 AND is_bundle`, bundleID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("%w: product is not a bundle", ErrInvalidBundle)
+	}
+	return tx.Commit(ctx)
+}
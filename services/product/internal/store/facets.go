@@ -61,3 +61,74 @@ func (s *Store) SearchFacets(
 
 	return facets, nil
 }
+
+// PriceFacet computes min/max price and a histogram over the given edges in
+// one query. Bucket i counts prices in [edges[i-1], edges[i]), with open-ended
+// buckets below the first and from the last edge up.
+func (s *Store) PriceFacet(
+	ctx context.Context,
+	where []string,
+	args []any,
+	edges []int,
+) (*model.PriceFacet, error) {
+
+	baseWhere := strings.Join(where, " AND ")
+
+	// Min and max come from the same grouped scan as the histogram: the
+	// smallest bucket minimum is the overall minimum, and likewise for max.
+	// With no edges every price falls in bucket 0.
+	if edges == nil {
+		edges = []int{}
+	}
+	out := &model.PriceFacet{Buckets: []model.PriceBucket{}}
+	counts := make([]int, len(edges)+1)
+	rows, err := s.db.Query(ctx, fmt.Sprintf(`
+		SELECT width_bucket(p.price, $%d::int[]) AS bucket, COUNT(p.id),
+		       MIN(p.price), MAX(p.price)
+		FROM products p
+		WHERE %s
+		GROUP BY bucket
+	`, len(args)+1, baseWhere), append(args, edges)...)
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close()
+	seen := false
+	for rows.Next() {
+		var bucket, count, lo, hi int
+		if err := rows.Scan(&bucket, &count, &lo, &hi); err != nil {
+			return out, err
+		}
+		if !seen || lo < out.Min {
+			out.Min = lo
+		}
+		if !seen || hi > out.Max {
+			out.Max = hi
+		}
+		seen = true
+		if bucket >= 0 && bucket < len(counts) {
+			counts[bucket] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return out, err
+	}
+
+	if len(edges) == 0 {
+		return out, nil
+	}
+
+	for i, c := range counts {
+		b := model.PriceBucket{Count: c}
+		if i > 0 {
+			lo := edges[i-1]
+			b.Min = &lo
+		}
+		if i < len(edges) {
+			hi := edges[i]
+			b.Max = &hi
+		}
+		out.Buckets = append(out.Buckets, b)
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// ListFeedProducts returns the full published catalogue with media, in a
+// stable order, for marketplace feed generation.
+func (s *Store) ListFeedProducts(ctx context.Context) ([]model.Product, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT id, slug, title,
+           COALESCE(short_desc, ''),
+           COALESCE(long_desc, ''),
+           category,
+           COALESCE(subcategory, ''),
+           price, mrp, stock,
+           COALESCE(sku, ''),
+           attributes, tags, published, created_at, updated_at
+    FROM products
+    WHERE published = true AND deleted_at IS NULL
+    ORDER BY created_at ASC, id ASC
+  `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	var productIDs []string
+	for rows.Next() {
+		var p model.Product
+		var attrs []byte
+		if err := rows.Scan(
+			&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.LongDesc, &p.Category, &p.Subcat,
+			&p.Price, &p.MRP, &p.Stock, &p.SKU,
+			&attrs, &p.Tags, &p.Published, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		p.InStock = p.Stock > 0
+		_ = json.Unmarshal(attrs, &p.Attributes)
+		products = append(products, p)
+		productIDs = append(productIDs, p.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mediaMap, err := s.getMediaForProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].Media = mediaMap[products[i].ID]
+	}
+	return products, nil
+}
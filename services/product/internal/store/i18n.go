@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+var ErrTranslationNotFound = errors.New("translation not found")
+
+// translatedTextMatch is a WHERE fragment matching the ILIKE pattern in
+// $qArg against the product's title and short description in the locale in
+// $localeArg.
+func translatedTextMatch(qArg, localeArg int) string {
+	return fmt.Sprintf(`EXISTS (
+      SELECT 1 FROM product_translations t
+      WHERE t.product_id = p.id AND t.locale = $%d
+        AND (t.title ILIKE $%d OR t.short_desc ILIKE $%d)
+    )`, localeArg, qArg, qArg)
+}
+
+// ListProductTranslations returns every translation of a product keyed by
+// locale.
+func (s *Store) ListProductTranslations(ctx context.Context, productID string) (map[string]model.ProductTranslation, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT locale, title, short_desc, long_desc, updated_at
+    FROM product_translations
+    WHERE product_id = $1
+  `, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]model.ProductTranslation{}
+	for rows.Next() {
+		var t model.ProductTranslation
+		if err := rows.Scan(&t.Locale, &t.Title, &t.ShortDesc, &t.LongDesc, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out[t.Locale] = t
+	}
+	return out, rows.Err()
+}
+
+// GetTranslationsFor returns the translations of several products in one
+// locale, keyed by product ID. Products without one are absent.
+func (s *Store) GetTranslationsFor(ctx context.Context, productIDs []string, locale string) (map[string]model.ProductTranslation, error) {
+	out := map[string]model.ProductTranslation{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+	rows, err := s.db.Query(ctx, `
+    SELECT product_id, locale, title, short_desc, long_desc, updated_at
+    FROM product_translations
+    WHERE product_id = ANY($1) AND locale = $2
+  `, productIDs, locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var t model.ProductTranslation
+		if err := rows.Scan(&id, &t.Locale, &t.Title, &t.ShortDesc, &t.LongDesc, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out[id] = t
+	}
+	return out, rows.Err()
+}
+
+// UpsertProductTranslation writes one locale's copy for a live product and
+// bumps the product's updated_at so HTTP validators change with it.
+func (s *Store) UpsertProductTranslation(ctx context.Context, productID string, t *model.ProductTranslation) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1 AND deleted_at IS NULL`, productID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("product not found")
+	}
+
+	if _, err := tx.Exec(ctx, `
+    INSERT INTO product_translations (product_id, locale, title, short_desc, long_desc)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (product_id, locale) DO UPDATE SET
+      title = EXCLUDED.title,
+      short_desc = EXCLUDED.short_desc,
+      long_desc = EXCLUDED.long_desc
+  `, productID, t.Locale, t.Title, t.ShortDesc, t.LongDesc); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *Store) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	cmd, err := s.db.Exec(ctx, `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`, productID, locale)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrTranslationNotFound
+	}
+	_, err = s.db.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1`, productID)
+	return err
+}
+
+// GetLabels returns the category and attribute value labels for a locale.
+func (s *Store) GetLabels(ctx context.Context, locale string) (*model.Labels, error) {
+	labels := &model.Labels{
+		Categories: map[string]string{},
+		Attributes: map[string]map[string]string{},
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT category, label FROM category_translations WHERE locale = $1`, locale)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var category, label string
+		if err := rows.Scan(&category, &label); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		labels.Categories[category] = label
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(ctx, `SELECT attribute, value, label FROM attribute_value_translations WHERE locale = $1`, locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var attr, value, label string
+		if err := rows.Scan(&attr, &value, &label); err != nil {
+			return nil, err
+		}
+		if labels.Attributes[attr] == nil {
+			labels.Attributes[attr] = map[string]string{}
+		}
+		labels.Attributes[attr][value] = label
+	}
+	return labels, rows.Err()
+}
+
+// SetCategoryLabels upserts category labels for a locale. An empty label
+// removes the translation.
+func (s *Store) SetCategoryLabels(ctx context.Context, locale string, labels map[string]string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for category, label := range labels {
+		if label == "" {
+			_, err = tx.Exec(ctx, `DELETE FROM category_translations WHERE category = $1 AND locale = $2`, category, locale)
+		} else {
+			_, err = tx.Exec(ctx, `
+        INSERT INTO category_translations (category, locale, label)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (category, locale) DO UPDATE SET label = EXCLUDED.label
+      `, category, locale, label)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// SetAttributeLabels upserts attribute value labels for a locale, keyed by
+// attribute then value. An empty label removes the translation.
+func (s *Store) SetAttributeLabels(ctx context.Context, locale string, labels map[string]map[string]string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for attr, values := range labels {
+		for value, label := range values {
+			if label == "" {
+				_, err = tx.Exec(ctx, `
+          DELETE FROM attribute_value_translations
+          WHERE attribute = $1 AND value = $2 AND locale = $3
+        `, attr, value, locale)
+			} else {
+				_, err = tx.Exec(ctx, `
+          INSERT INTO attribute_value_translations (attribute, value, locale, label)
+          VALUES ($1, $2, $3, $4)
+          ON CONFLICT (attribute, value, locale) DO UPDATE SET label = EXCLUDED.label
+        `, attr, value, locale, label)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
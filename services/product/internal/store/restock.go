@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrAlreadySubscribed = errors.New("already subscribed")
+	ErrNotOutOfStock     = errors.New("product is in stock")
+	ErrNotSubscribed     = errors.New("subscription not found")
+
+	ErrConfirmationPending = errors.New("confirmation already sent")
+	ErrInvalidRestockToken = errors.New("invalid or expired link")
+)
+
+const (
+	// maxRestockAttempts is how often a failing notification is retried
+	// before it is marked failed.
+	maxRestockAttempts = 5
+
+	// An unconfirmed email subscription gets a new confirmation mail at most
+	// every restockConfirmResend, and its link works for restockConfirmTTL.
+	restockConfirmResend = 10 * time.Minute
+	restockConfirmTTL    = 7 * 24 * time.Hour
+)
+
+// RestockRecipient is the key a subscriber is deduplicated and rate limited
+// on. A user ID wins over an email.
+func RestockRecipient(userID, email string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// SubscribeRestock registers interest in a live, published, out-of-stock
+// product. A signed-in user's subscription is confirmed right away; an
+// email-only one waits for the address to be confirmed with the token
+// hashed in tokenHash, and the confirmation to mail is returned. Asking
+// again for an unconfirmed address issues a new token, at most every
+// restockConfirmResend.
+func (s *Store) SubscribeRestock(ctx context.Context, productID, userID, email, tokenHash string) (*model.RestockConfirmation, error) {
+	c := &model.RestockConfirmation{ProductID: productID}
+	var stock int
+	err := s.db.QueryRow(ctx, `
+    SELECT stock, slug, title FROM products
+    WHERE id = $1 AND deleted_at IS NULL AND published = true
+  `, productID).Scan(&stock, &c.Slug, &c.Title)
+	if err == pgx.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stock > 0 {
+		return nil, ErrNotOutOfStock
+	}
+
+	var uid, mail, token *string
+	if userID != "" {
+		uid = &userID
+	}
+	if email != "" {
+		e := strings.ToLower(strings.TrimSpace(email))
+		mail = &e
+		c.Email = e
+	}
+	confirmed := userID != ""
+	if !confirmed {
+		token = &tokenHash
+	}
+	recipient := RestockRecipient(userID, email)
+
+	cmd, err := s.db.Exec(ctx, `
+    INSERT INTO stock_subscriptions
+      (product_id, recipient, user_id, email, confirmed_at, token_hash, confirmation_sent_at)
+    VALUES ($1, $2, $3, $4,
+            CASE WHEN $5 THEN now() END, $6, CASE WHEN $5 THEN NULL ELSE now() END)
+    ON CONFLICT (product_id, recipient) WHERE notified_at IS NULL AND failed_at IS NULL
+    DO UPDATE SET token_hash = EXCLUDED.token_hash, confirmation_sent_at = now()
+    WHERE stock_subscriptions.confirmed_at IS NULL
+      AND EXCLUDED.token_hash IS NOT NULL
+      AND (stock_subscriptions.confirmation_sent_at IS NULL
+           OR stock_subscriptions.confirmation_sent_at < now() - make_interval(secs => $7))
+  `, productID, recipient, uid, mail, confirmed, token, restockConfirmResend.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if cmd.RowsAffected() == 0 {
+		var pending bool
+		err := s.db.QueryRow(ctx, `
+      SELECT confirmed_at IS NULL FROM stock_subscriptions
+      WHERE product_id = $1 AND recipient = $2 AND notified_at IS NULL AND failed_at IS NULL
+    `, productID, recipient).Scan(&pending)
+		if err == nil && pending {
+			return nil, ErrConfirmationPending
+		}
+		return nil, ErrAlreadySubscribed
+	}
+	if confirmed {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// ConfirmRestock confirms the email subscription a token was mailed for.
+// Confirming twice is fine.
+func (s *Store) ConfirmRestock(ctx context.Context, tokenHash string) error {
+	cmd, err := s.db.Exec(ctx, `
+    UPDATE stock_subscriptions
+    SET confirmed_at = COALESCE(confirmed_at, now())
+    WHERE token_hash = $1 AND notified_at IS NULL AND failed_at IS NULL
+      AND (confirmed_at IS NOT NULL
+           OR confirmation_sent_at > now() - make_interval(secs => $2))
+  `, tokenHash, restockConfirmTTL.Seconds())
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrInvalidRestockToken
+	}
+	return nil
+}
+
+// UnsubscribeRestock drops a signed-in user's pending subscription.
+func (s *Store) UnsubscribeRestock(ctx context.Context, productID, recipient string) error {
+	cmd, err := s.db.Exec(ctx, `
+    DELETE FROM stock_subscriptions
+    WHERE product_id = $1 AND recipient = $2 AND notified_at IS NULL AND failed_at IS NULL
+  `, productID, recipient)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotSubscribed
+	}
+	return nil
+}
+
+// CancelRestock drops the pending email subscription a token was mailed
+// for, confirmed or not.
+func (s *Store) CancelRestock(ctx context.Context, tokenHash string) error {
+	cmd, err := s.db.Exec(ctx, `
+    DELETE FROM stock_subscriptions
+    WHERE token_hash = $1 AND notified_at IS NULL AND failed_at IS NULL
+  `, tokenHash)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrInvalidRestockToken
+	}
+	return nil
+}
+
+// ClaimRestockNotices marks up to limit due subscriptions as notified and
+// returns them. A subscription is due once it is confirmed, its product was
+// restocked and is still in stock and published, and its recipient has not
+// been notified within cooldown. Claiming uses SKIP LOCKED so several
+// instances never pick the same row.
+func (s *Store) ClaimRestockNotices(ctx context.Context, cooldown time.Duration, limit int) ([]model.RestockNotice, error) {
+	rows, err := s.db.Query(ctx, `
+    WITH due AS (
+      SELECT s.id
+      FROM stock_subscriptions s
+      JOIN products p ON p.id = s.product_id
+      WHERE s.notified_at IS NULL AND s.failed_at IS NULL AND s.restocked_at IS NOT NULL
+        AND s.confirmed_at IS NOT NULL
+        AND p.stock > 0 AND p.published = true AND p.deleted_at IS NULL
+        AND NOT EXISTS (
+          SELECT 1 FROM stock_subscriptions o
+          WHERE o.recipient = s.recipient
+            AND o.notified_at > now() - make_interval(secs => $1)
+        )
+      ORDER BY s.restocked_at
+      LIMIT $2
+      FOR UPDATE OF s SKIP LOCKED
+    )
+    UPDATE stock_subscriptions s
+    SET notified_at = now(), attempts = s.attempts + 1
+    FROM due, products p
+    WHERE s.id = due.id AND p.id = s.product_id
+    RETURNING s.id, s.product_id, p.slug, p.title, s.recipient,
+              COALESCE(s.user_id, ''), COALESCE(s.email, '')
+  `, cooldown.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.RestockNotice
+	for rows.Next() {
+		var n model.RestockNotice
+		if err := rows.Scan(&n.SubscriptionID, &n.ProductID, &n.Slug, &n.Title, &n.Recipient, &n.UserID, &n.Email); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// ReleaseRestockNotice returns a claimed subscription to the queue without
+// counting it as an attempt, e.g. when its recipient already got a notice
+// in the same batch.
+func (s *Store) ReleaseRestockNotice(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `
+    UPDATE stock_subscriptions
+    SET notified_at = NULL, attempts = attempts - 1
+    WHERE id = $1
+  `, id)
+	return err
+}
+
+// FailRestockNotice records a failed send. The subscription is retried on a
+// later sweep until maxRestockAttempts, then marked failed.
+func (s *Store) FailRestockNotice(ctx context.Context, id string, sendErr error) error {
+	_, err := s.db.Exec(ctx, `
+    UPDATE stock_subscriptions
+    SET notified_at = NULL,
+        last_error = $2,
+        failed_at = CASE WHEN attempts >= $3 THEN now() ELSE NULL END
+    WHERE id = $1
+  `, id, sendErr.Error(), maxRestockAttempts)
+	return err
+}
+
+// CountPendingRestock returns how many confirmed subscriptions wait on a
+// product.
+func (s *Store) CountPendingRestock(ctx context.Context, productID string) (int, error) {
+	var n int
+	err := s.db.QueryRow(ctx, `
+    SELECT COUNT(*) FROM stock_subscriptions
+    WHERE product_id = $1 AND notified_at IS NULL AND failed_at IS NULL
+      AND confirmed_at IS NOT NULL
+  `, productID).Scan(&n)
+	return n, err
+}
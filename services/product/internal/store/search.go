@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/devmanishoffl/sabhyatam-product/internal/model"
@@ -11,7 +12,7 @@ import (
 func (s *Store) SearchProducts(
 	ctx context.Context,
 	p model.SearchParams,
-) ([]model.ProductCard, int, model.Facets, error) {
+) ([]model.ProductCard, int, model.Facets, *model.PriceFacet, error) {
 
 	where := []string{
 		"p.deleted_at IS NULL",
@@ -21,9 +22,16 @@ func (s *Store) SearchProducts(
 	arg := 1
 
 	if p.Query != "" {
-		where = append(where, fmt.Sprintf("(p.title ILIKE $%d OR p.tags::text ILIKE $%d)", arg, arg))
-		args = append(args, "%"+p.Query+"%")
-		arg++
+		if p.Locale != "" && p.Locale != model.DefaultLocale {
+			where = append(where, fmt.Sprintf("(p.title ILIKE $%d OR p.tags::text ILIKE $%d OR %s)",
+				arg, arg, translatedTextMatch(arg, arg+1)))
+			args = append(args, "%"+p.Query+"%", p.Locale)
+			arg += 2
+		} else {
+			where = append(where, fmt.Sprintf("(p.title ILIKE $%d OR p.tags::text ILIKE $%d)", arg, arg))
+			args = append(args, "%"+p.Query+"%")
+			arg++
+		}
 	}
 
 	if p.Category != "" {
@@ -32,6 +40,11 @@ func (s *Store) SearchProducts(
 		arg++
 	}
 
+	// The price facet describes the set before price filtering, so the
+	// slider can always be widened back out.
+	priceFacetWhere := append([]string{}, where...)
+	priceFacetArgs := append([]any{}, args...)
+
 	// Price Filters (Direct on Product)
 	if p.MinPrice > 0 {
 		where = append(where, fmt.Sprintf("p.price >= $%d", arg))
@@ -60,7 +73,7 @@ func (s *Store) SearchProducts(
 
 	rows, err := s.db.Query(ctx, query, append(args, p.Limit, offset)...)
 	if err != nil {
-		return nil, 0, nil, err
+		return nil, 0, nil, nil, err
 	}
 	defer rows.Close()
 
@@ -68,7 +81,7 @@ func (s *Store) SearchProducts(
 	for rows.Next() {
 		var pc model.ProductCard
 		if err := rows.Scan(&pc.ID, &pc.Title, &pc.Slug, &pc.Category, &pc.Price, &pc.ImageURL, &pc.Attrs); err != nil {
-			return nil, 0, nil, err
+			return nil, 0, nil, nil, err
 		}
 		items = append(items, pc)
 	}
@@ -79,6 +92,11 @@ func (s *Store) SearchProducts(
 
 	// Facets (Simplified)
 	facets, _ := s.SearchFacets(ctx, where, args)
+	// The price facet is decoration; a failure leaves it empty, not the search.
+	priceFacet, err := s.PriceFacet(ctx, priceFacetWhere, priceFacetArgs, p.PriceEdges)
+	if err != nil {
+		log.Println("price facet:", err)
+	}
 
-	return items, total, facets, nil
+	return items, total, facets, priceFacet, nil
 }
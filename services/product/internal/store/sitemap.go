@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+)
+
+// CountSitemapProducts returns the number of publicly indexable products.
+func (s *Store) CountSitemapProducts(ctx context.Context) (int, time.Time, error) {
+	var count int
+	var lastMod *time.Time
+	err := s.db.QueryRow(ctx, `
+    SELECT COUNT(*), MAX(COALESCE(updated_at, created_at))
+    FROM products
+    WHERE published = true AND deleted_at IS NULL
+  `).Scan(&count, &lastMod)
+	if err != nil || lastMod == nil {
+		return count, time.Time{}, err
+	}
+	return count, *lastMod, nil
+}
+
+func (s *Store) ListSitemapProducts(ctx context.Context, limit, offset int) ([]model.SitemapRow, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT slug, category, COALESCE(updated_at, created_at, now())
+    FROM products
+    WHERE published = true AND deleted_at IS NULL
+    ORDER BY created_at ASC, id ASC
+    LIMIT $1 OFFSET $2
+  `, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.SitemapRow
+	for rows.Next() {
+		var r model.SitemapRow
+		if err := rows.Scan(&r.Slug, &r.Category, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListSitemapCategories returns every category with at least one published
+// product, stamped with the most recent product change inside it.
+func (s *Store) ListSitemapCategories(ctx context.Context) ([]model.SitemapRow, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT category, COALESCE(MAX(updated_at), now())
+    FROM products
+    WHERE published = true AND deleted_at IS NULL
+    GROUP BY category
+    ORDER BY category
+  `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.SitemapRow
+	for rows.Next() {
+		var r model.SitemapRow
+		if err := rows.Scan(&r.Slug, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		r.Category = r.Slug
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListSitemapCollections returns (category, subcategory) pairs; a collection
+// is a subcategory listing within its parent category.
+func (s *Store) ListSitemapCollections(ctx context.Context) ([]model.SitemapRow, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT subcategory, category, COALESCE(MAX(updated_at), now())
+    FROM products
+    WHERE published = true AND deleted_at IS NULL
+      AND COALESCE(subcategory, '') <> ''
+    GROUP BY category, subcategory
+    ORDER BY category, subcategory
+  `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []model.SitemapRow
+	for rows.Next() {
+		var r model.SitemapRow
+		if err := rows.Scan(&r.Slug, &r.Category, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetRatingSummary reads approved review stats from the reviews table, which
+// lives in the same database. A missing table is treated as "no ratings".
+func (s *Store) GetRatingSummary(ctx context.Context, productID string) (float64, int, error) {
+	var avg float64
+	var count int
+	err := s.db.QueryRow(ctx, `
+    SELECT COALESCE(AVG(rating), 0), COUNT(*)
+    FROM reviews
+    WHERE product_id = $1 AND status = 'approved'
+  `, productID).Scan(&avg, &count)
+	return avg, count, err
+}
+
+// ReviewsLastModified is when any review of a product last changed, in any
+// status, since moderation moves a review in or out of the rating.
+func (s *Store) ReviewsLastModified(ctx context.Context, productID string) (time.Time, error) {
+	var t *time.Time
+	err := s.db.QueryRow(ctx, `SELECT MAX(updated_at) FROM reviews WHERE product_id = $1`, productID).Scan(&t)
+	if err != nil || t == nil {
+		return time.Time{}, err
+	}
+	return *t, nil
+}
+
+// GetRatingSummaries is GetRatingSummary for several products, keyed by
+// product ID. Products without approved reviews are absent.
+func (s *Store) GetRatingSummaries(ctx context.Context, productIDs []string) (map[string]model.RatingSummary, error) {
+	out := map[string]model.RatingSummary{}
+	if len(productIDs) == 0 {
+		return out, nil
+	}
+	rows, err := s.db.Query(ctx, `
+    SELECT product_id::text, AVG(rating), COUNT(*)
+    FROM reviews
+    WHERE product_id = ANY($1::uuid[]) AND status = 'approved'
+    GROUP BY product_id
+  `, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var r model.RatingSummary
+		if err := rows.Scan(&id, &r.Average, &r.Count); err != nil {
+			return nil, err
+		}
+		out[id] = r
+	}
+	return out, rows.Err()
+}
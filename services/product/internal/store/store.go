@@ -3,10 +3,12 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -30,6 +32,15 @@ func (s *Store) Close(ctx context.Context) {
 // --- ADMIN SPECIFIC METHODS ---
 
 func (s *Store) ListAdminProducts(ctx context.Context, page, limit int, search string) ([]model.Product, int, error) {
+	return s.listAdminProducts(ctx, page, limit, search, "")
+}
+
+// ListVendorProducts is the admin listing restricted to one vendor's products.
+func (s *Store) ListVendorProducts(ctx context.Context, vendorID string, page, limit int, search string) ([]model.Product, int, error) {
+	return s.listAdminProducts(ctx, page, limit, search, vendorID)
+}
+
+func (s *Store) listAdminProducts(ctx context.Context, page, limit int, search, vendorID string) ([]model.Product, int, error) {
 	offset := (page - 1) * limit
 
 	whereClause := "WHERE deleted_at IS NULL"
@@ -38,7 +49,12 @@ func (s *Store) ListAdminProducts(ctx context.Context, page, limit int, search s
 
 	if search != "" {
 		whereClause += fmt.Sprintf(" AND (title ILIKE $%d OR short_desc ILIKE $%d OR sku ILIKE $%d)", argIdx, argIdx, argIdx)
-		args = append(args, "%"+search+"%", "%"+search+"%", "%"+search+"%")
+		args = append(args, "%"+search+"%")
+		argIdx++
+	}
+	if vendorID != "" {
+		whereClause += fmt.Sprintf(" AND vendor_id = $%d", argIdx)
+		args = append(args, vendorID)
 		argIdx++
 	}
 
@@ -57,7 +73,7 @@ func (s *Store) ListAdminProducts(ctx context.Context, page, limit int, search s
            COALESCE(subcategory, ''), 
            price, mrp, stock, 
            COALESCE(sku, ''), 
-           published, created_at
+           published, created_at, vendor_id, cost_price
     FROM products
     %s
     ORDER BY created_at DESC
@@ -79,7 +95,7 @@ func (s *Store) ListAdminProducts(ctx context.Context, page, limit int, search s
 		var p model.Product
 		if err := rows.Scan(
 			&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.Category, &p.Subcat,
-			&p.Price, &p.MRP, &p.Stock, &p.SKU, &p.Published, &p.CreatedAt,
+			&p.Price, &p.MRP, &p.Stock, &p.SKU, &p.Published, &p.CreatedAt, &p.VendorID, &p.CostPrice,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -101,6 +117,17 @@ func (s *Store) ListAdminProducts(ctx context.Context, page, limit int, search s
 	return products, total, nil
 }
 
+// CatalogLastModified is the most recent change to any product, including
+// soft deletes, and versions every listing and search response.
+func (s *Store) CatalogLastModified(ctx context.Context) (time.Time, error) {
+	var t *time.Time
+	err := s.db.QueryRow(ctx, `SELECT MAX(updated_at) FROM products`).Scan(&t)
+	if err != nil || t == nil {
+		return time.Time{}, err
+	}
+	return *t, nil
+}
+
 func (s *Store) GetDashboardStats(ctx context.Context) (int, int, error) {
 	var active int
 	var lowStock int
@@ -147,7 +174,8 @@ func (s *Store) GetProductByID(ctx context.Context, id string) (*model.Product,
                COALESCE(subcategory, ''), 
                price, mrp, stock, 
                COALESCE(sku, ''),
-               attributes, tags, published, created_at, updated_at 
+               attributes, tags, published, created_at, updated_at, deleted_at,
+               vendor_id, is_bundle
         FROM products WHERE id=$1
     `, id)
 
@@ -157,7 +185,8 @@ func (s *Store) GetProductByID(ctx context.Context, id string) (*model.Product,
 	if err := row.Scan(
 		&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.Category, &p.Subcat,
 		&p.Price, &p.MRP, &p.Stock, &p.SKU,
-		&attrs, &p.Tags, &p.Published, &p.CreatedAt, &p.UpdatedAt,
+		&attrs, &p.Tags, &p.Published, &p.CreatedAt, &p.UpdatedAt, &p.DeletedAt,
+		&p.VendorID, &p.IsBundle,
 	); err != nil {
 		return nil, err
 	}
@@ -221,9 +250,16 @@ func (s *Store) ListProductsFiltered(ctx context.Context, filters map[string]str
 		argIndex++
 	}
 	if v := filters["q"]; v != "" {
-		query += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.short_desc ILIKE $%d)", argIndex, argIndex)
-		args = append(args, "%"+v+"%")
-		argIndex++
+		if locale := filters["locale"]; locale != "" && locale != model.DefaultLocale {
+			query += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.short_desc ILIKE $%d OR %s)",
+				argIndex, argIndex, translatedTextMatch(argIndex, argIndex+1))
+			args = append(args, "%"+v+"%", locale)
+			argIndex += 2
+		} else {
+			query += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.short_desc ILIKE $%d)", argIndex, argIndex)
+			args = append(args, "%"+v+"%")
+			argIndex++
+		}
 	}
 
 	switch sort {
@@ -275,14 +311,14 @@ func (s *Store) CreateProduct(ctx context.Context, p *model.Product) (string, er
     INSERT INTO products (
       slug, title, short_desc, long_desc, category, subcategory, 
       price, mrp, stock, sku,
-      attributes, tags, published
+      attributes, tags, published, vendor_id, cost_price
     ) 
-    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13) 
+    VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15) 
     RETURNING id
   `,
 		p.Slug, p.Title, p.ShortDesc, p.LongDesc, p.Category, p.Subcat,
 		p.Price, p.MRP, p.Stock, p.SKU,
-		attrs, p.Tags, p.Published,
+		attrs, p.Tags, p.Published, p.VendorID, p.CostPrice,
 	).Scan(&id)
 
 	if err != nil {
@@ -300,13 +336,16 @@ func (s *Store) UpdateProduct(ctx context.Context, id string, p *model.Product)
         UPDATE products SET
             slug = $1, title = $2, short_desc = $3, long_desc = $4,
             category = $5, subcategory = $6, attributes = $7, tags = $8,
-            published = $9, price = $10, mrp = $11, stock = $12, sku = $13,
+            published = $9, price = $10, mrp = $11, sku = $13,
+            stock = CASE WHEN is_bundle THEN stock ELSE $12 END,
+            vendor_id = COALESCE($15, vendor_id),
+            cost_price = COALESCE($16, cost_price),
             updated_at = now()
         WHERE id = $14
     `,
 		p.Slug, p.Title, p.ShortDesc, p.LongDesc, p.Category, p.Subcat,
 		attrs, p.Tags, p.Published, p.Price, p.MRP, p.Stock, p.SKU,
-		id,
+		id, p.VendorID, p.CostPrice,
 	)
 	return err
 }
@@ -324,73 +363,141 @@ func (s *Store) DeleteProduct(ctx context.Context, id string) error {
 
 // --- STOCK METHODS ---
 
-func (s *Store) ReserveStock(ctx context.Context, productID string, qty int) error {
-	if qty <= 0 {
-		return fmt.Errorf("invalid quantity")
-	}
-	res, err := s.db.Exec(ctx, `
+// Stock mutations are single guarded UPDATEs; RowsAffected() == 0 means the
+// guard failed. $1 is the quantity and $2 the product.
+const (
+	reserveStockSQL = `
     UPDATE products 
     SET stock = stock - $1, stock_reserved = stock_reserved + $1 
     WHERE id = $2 AND stock >= $1
-  `, qty, productID)
+  `
+	releaseStockSQL = `
+    UPDATE products 
+    SET stock = stock + $1, stock_reserved = stock_reserved - $1 
+    WHERE id = $2 AND stock_reserved >= $1
+  `
+	deductStockSQL = `
+    UPDATE products 
+    SET stock_reserved = stock_reserved - $1,
+        units_sold = units_sold + $1, last_sold_at = now()
+    WHERE id = $2 AND stock_reserved >= $1
+  `
+)
 
-	if err != nil {
-		return err
-	}
-	if res.RowsAffected() == 0 {
-		return fmt.Errorf("insufficient stock")
-	}
-	return nil
+func (s *Store) ReserveStock(ctx context.Context, productID string, qty int) error {
+	return s.mutateStock(ctx, productID, qty, reserveStockSQL, "insufficient stock")
 }
 
 func (s *Store) ReleaseStock(ctx context.Context, productID string, qty int) error {
+	return s.mutateStock(ctx, productID, qty, releaseStockSQL, "invalid reserved stock")
+}
+
+func (s *Store) DeductStock(ctx context.Context, productID string, qty int) error {
+	return s.mutateStock(ctx, productID, qty, deductStockSQL, "insufficient reserved stock")
+}
+
+// stockAttempts bounds how often mutateStock retries a transaction Postgres
+// aborted as a deadlock victim.
+const stockAttempts = 3
+
+// mutateStock applies a stock statement to a product, or for a bundle fans
+// it out to every component (qty x component quantity) in one transaction,
+// so a bundle is reserved, released or deducted entirely or not at all. The
+// bundle's own stock is then re-derived by the bundle_stock_sync trigger.
+func (s *Store) mutateStock(ctx context.Context, productID string, qty int, stmt, failMsg string) error {
 	if qty <= 0 {
 		return fmt.Errorf("invalid quantity")
 	}
-	res, err := s.db.Exec(ctx, `
-    UPDATE products 
-    SET stock = stock + $1, stock_reserved = stock_reserved - $1 
-    WHERE id = $2 AND stock_reserved >= $1
-  `, qty, productID)
 
-	if err != nil {
+	for attempt := 1; ; attempt++ {
+		err := s.mutateStockTx(ctx, productID, qty, stmt, failMsg)
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "40P01" && attempt < stockAttempts {
+			continue
+		}
 		return err
 	}
-	if res.RowsAffected() == 0 {
-		return fmt.Errorf("invalid reserved stock")
-	}
-	return nil
 }
 
-func (s *Store) DeductStock(ctx context.Context, productID string, qty int) error {
-	if qty <= 0 {
-		return fmt.Errorf("invalid quantity")
+// mutateStockTx is one attempt of mutateStock. Every stock change fires
+// bundle_stock_sync, which updates the bundles containing the changed
+// product after the product itself is locked. A bundle mutation locks its
+// components one by one, so left alone it would hold a bundle row (via an
+// earlier component's trigger) while waiting for a component that a plain
+// reservation holds while waiting for that bundle. Locking every bundle the
+// trigger will touch, in id order, before any product row keeps the order
+// the same everywhere: bundles first, then components by id.
+func (s *Store) mutateStockTx(ctx context.Context, productID string, qty int, stmt, failMsg string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
-	res, err := s.db.Exec(ctx, `
-    UPDATE products 
-    SET stock_reserved = stock_reserved - $1 
-    WHERE id = $2 AND stock_reserved >= $1
-  `, qty, productID)
+	defer tx.Rollback(ctx)
 
+	components, err := s.bundleItems(ctx, tx, productID)
 	if err != nil {
 		return err
 	}
-	if res.RowsAffected() == 0 {
-		return fmt.Errorf("insufficient reserved stock")
+	targets := components
+	if len(targets) == 0 {
+		targets = []model.BundleItem{{ProductID: productID, Quantity: 1}}
 	}
-	return nil
+
+	ids := make([]string, len(targets))
+	for i, c := range targets {
+		ids[i] = c.ProductID
+	}
+	if _, err := tx.Exec(ctx, `
+    SELECT 1 FROM products
+    WHERE id IN (SELECT bundle_id FROM product_bundle_items WHERE component_id = ANY($1::uuid[]))
+    ORDER BY id
+    FOR UPDATE
+  `, ids); err != nil {
+		return err
+	}
+
+	// bundleItems returns components ordered by id, so concurrent bundle
+	// mutations lock rows in the same order.
+	for _, c := range targets {
+		res, err := tx.Exec(ctx, stmt, qty*c.Quantity, c.ProductID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			if len(components) == 0 {
+				return errors.New(failMsg)
+			}
+			return fmt.Errorf("%s for bundle component %s", failMsg, c.ProductID)
+		}
+	}
+	return tx.Commit(ctx)
 }
 
 // --- MEDIA METHODS ---
 func (s *Store) CreateMedia(ctx context.Context, productID string, m *model.Media) (string, error) {
 	var id string
 	err := s.db.QueryRow(ctx, `INSERT INTO product_media (product_id, url, media_type, meta) VALUES ($1, $2, $3, $4) RETURNING id`, productID, m.URL, m.MediaType, m.Meta).Scan(&id)
+	if err != nil {
+		return id, err
+	}
+	// Media is part of the product representation; bump updated_at so
+	// Last-Modified validators change with it.
+	_, err = s.db.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1`, productID)
 	return id, err
 }
 
-func (s *Store) DeleteMedia(ctx context.Context, id string) error {
-	_, err := s.db.Exec(ctx, `DELETE FROM product_media WHERE id=$1`, id)
-	return err
+// DeleteMedia removes a media row and returns the product it belonged to,
+// or "" if it did not exist.
+func (s *Store) DeleteMedia(ctx context.Context, id string) (string, error) {
+	var productID string
+	err := s.db.QueryRow(ctx, `DELETE FROM product_media WHERE id=$1 RETURNING product_id`, id).Scan(&productID)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	_, err = s.db.Exec(ctx, `UPDATE products SET updated_at = now() WHERE id = $1`, productID)
+	return productID, err
 }
 
 func (s *Store) GetProductBySlug(ctx context.Context, slug string) (*model.Product, error) {
@@ -404,13 +511,15 @@ func (s *Store) GetProductBySlug(ctx context.Context, slug string) (*model.Produ
            COALESCE(subcategory, ''), 
            price, mrp, stock, 
            COALESCE(sku, ''),
-           attributes, tags, published, created_at, updated_at 
+           attributes, tags, published, created_at, updated_at,
+           vendor_id, is_bundle
     FROM products 
     WHERE slug = $1 AND published = true AND deleted_at IS NULL
   `, slug).Scan(
 		&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.Category, &p.Subcat,
 		&p.Price, &p.MRP, &p.Stock, &p.SKU,
 		&attrs, &p.Tags, &p.Published, &p.CreatedAt, &p.UpdatedAt,
+		&p.VendorID, &p.IsBundle,
 	)
 	if err != nil {
 		return nil, err
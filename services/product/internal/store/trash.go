@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrNotInTrash      = errors.New("product not found in trash")
+	ErrRestoreConflict = errors.New("restore conflict")
+	ErrInBundle        = errors.New("product is a bundle component")
+)
+
+func (s *Store) ListDeletedProducts(ctx context.Context, page, limit int) ([]model.Product, int, error) {
+	offset := (page - 1) * limit
+
+	var total int
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM products WHERE deleted_at IS NOT NULL`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+    SELECT id, slug, title,
+           COALESCE(short_desc, ''),
+           category,
+           COALESCE(subcategory, ''),
+           price, mrp, stock,
+           COALESCE(sku, ''),
+           published, created_at, updated_at, deleted_at
+    FROM products
+    WHERE deleted_at IS NOT NULL
+    ORDER BY deleted_at DESC
+    LIMIT $1 OFFSET $2
+  `, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	products := []model.Product{}
+	var productIDs []string
+	for rows.Next() {
+		var p model.Product
+		if err := rows.Scan(
+			&p.ID, &p.Slug, &p.Title, &p.ShortDesc, &p.Category, &p.Subcat,
+			&p.Price, &p.MRP, &p.Stock, &p.SKU, &p.Published,
+			&p.CreatedAt, &p.UpdatedAt, &p.DeletedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		p.InStock = p.Stock > 0
+		products = append(products, p)
+		productIDs = append(productIDs, p.ID)
+	}
+
+	mediaMap, _ := s.getMediaForProducts(ctx, productIDs)
+	for i := range products {
+		products[i].Media = mediaMap[products[i].ID]
+	}
+	return products, total, nil
+}
+
+// RestoreProduct clears deleted_at, refusing if a live product has since
+// taken the same slug or SKU.
+func (s *Store) RestoreProduct(ctx context.Context, id string) error {
+	var slug, sku string
+	err := s.db.QueryRow(ctx, `
+    SELECT slug, COALESCE(sku, '') FROM products
+    WHERE id = $1 AND deleted_at IS NOT NULL
+  `, id).Scan(&slug, &sku)
+	if err == pgx.ErrNoRows {
+		return ErrNotInTrash
+	}
+	if err != nil {
+		return err
+	}
+
+	var conflictID, field string
+	err = s.db.QueryRow(ctx, `
+    SELECT id, CASE WHEN slug = $2 THEN 'slug' ELSE 'sku' END
+    FROM products
+    WHERE deleted_at IS NULL AND id <> $1
+      AND (slug = $2 OR ($3 <> '' AND sku = $3))
+    LIMIT 1
+  `, id, slug, sku).Scan(&conflictID, &field)
+	if err == nil {
+		return fmt.Errorf("%w: %s is already used by product %s", ErrRestoreConflict, field, conflictID)
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+
+	cmd, err := s.db.Exec(ctx, `UPDATE products SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		// Lost a race with a create/update; the partial unique index caught it.
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return fmt.Errorf("%w: %s", ErrRestoreConflict, pgErr.Detail)
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrNotInTrash
+	}
+	return nil
+}
+
+// PurgeProduct permanently deletes a trashed product. Its product_media rows
+// go with it (ON DELETE CASCADE); the removed media is returned so the
+// caller can delete the stored files. A product that is still a component
+// of a bundle is refused with ErrInBundle naming the bundles.
+func (s *Store) PurgeProduct(ctx context.Context, id string) ([]model.Media, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var found bool
+	err = tx.QueryRow(ctx, `
+    SELECT true FROM products WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE
+  `, id).Scan(&found)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotInTrash
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := bundlesContaining(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(bundles) > 0 {
+		return nil, fmt.Errorf("%w of %s; remove it from them first", ErrInBundle, strings.Join(bundles, ", "))
+	}
+
+	rows, err := tx.Query(ctx, `
+    SELECT m.id, m.product_id, m.url, COALESCE(m.media_type, '')
+    FROM product_media m
+    JOIN products p ON p.id = m.product_id
+    WHERE m.product_id = $1 AND p.deleted_at IS NOT NULL
+  `, id)
+	if err != nil {
+		return nil, err
+	}
+	var media []model.Media
+	for rows.Next() {
+		var m model.Media
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.URL, &m.MediaType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	rows.Close()
+
+	cmd, err := tx.Exec(ctx, `DELETE FROM products WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		// Added to a bundle since the check; the RESTRICT foreign key caught it.
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return nil, fmt.Errorf("%w: %s", ErrInBundle, pgErr.Detail)
+		}
+		return nil, err
+	}
+	if cmd.RowsAffected() == 0 {
+		return nil, ErrNotInTrash
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// bundlesContaining returns the slugs of the bundles id is a component of.
+func bundlesContaining(ctx context.Context, tx pgx.Tx, id string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+    SELECT b.slug FROM product_bundle_items bi
+    JOIN products b ON b.id = bi.bundle_id
+    WHERE bi.component_id = $1
+    ORDER BY b.slug
+  `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+// ListExpiredTrash returns IDs of products that have been in the trash for
+// longer than the retention period. The cutoff is computed in SQL because
+// deleted_at is a zone-less timestamp written with now(). Products still
+// used by a bundle cannot be purged and are skipped.
+func (s *Store) ListExpiredTrash(ctx context.Context, retentionDays, limit int) ([]string, error) {
+	rows, err := s.db.Query(ctx, `
+    SELECT id FROM products
+    WHERE deleted_at IS NOT NULL
+      AND deleted_at < now() - make_interval(days => $1)
+      AND NOT EXISTS (SELECT 1 FROM product_bundle_items bi WHERE bi.component_id = products.id)
+    ORDER BY deleted_at ASC
+    LIMIT $2
+  `, retentionDays, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrVendorNotFound  = errors.New("vendor not found")
+	ErrNotVendorOwned  = errors.New("product does not belong to vendor")
+	ErrDuplicateVendor = errors.New("vendor slug already exists")
+)
+
+const vendorColumns = `
+    id, slug, name,
+    COALESCE(bio, ''), COALESCE(city, ''), COALESCE(state, ''),
+    COALESCE(payout_ref, ''), commission_rate::float8, active,
+    api_key_hash IS NOT NULL, created_at, updated_at`
+
+func scanVendor(row pgx.Row) (*model.Vendor, error) {
+	var v model.Vendor
+	err := row.Scan(
+		&v.ID, &v.Slug, &v.Name, &v.Bio, &v.City, &v.State,
+		&v.PayoutRef, &v.CommissionRate, &v.Active,
+		&v.HasAPIKey, &v.CreatedAt, &v.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrVendorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (s *Store) ListVendors(ctx context.Context) ([]model.Vendor, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+vendorColumns+` FROM vendors ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Vendor{}
+	for rows.Next() {
+		v, err := scanVendor(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) GetVendor(ctx context.Context, id string) (*model.Vendor, error) {
+	return scanVendor(s.db.QueryRow(ctx, `SELECT `+vendorColumns+` FROM vendors WHERE id = $1`, id))
+}
+
+// GetVendorByAPIKeyHash resolves an active vendor from the hash of the key
+// presented in X-VENDOR-KEY.
+func (s *Store) GetVendorByAPIKeyHash(ctx context.Context, hash string) (*model.Vendor, error) {
+	return scanVendor(s.db.QueryRow(ctx, `
+    SELECT `+vendorColumns+` FROM vendors
+    WHERE api_key_hash = $1 AND active = true
+  `, hash))
+}
+
+// GetArtisanProfile looks up an active vendor by slug or ID for the public
+// artisan page.
+func (s *Store) GetArtisanProfile(ctx context.Context, slugOrID string) (*model.ArtisanProfile, error) {
+	var a model.ArtisanProfile
+	err := s.db.QueryRow(ctx, `
+    SELECT id, slug, name, COALESCE(bio, ''), COALESCE(city, ''), COALESCE(state, '')
+    FROM vendors
+    WHERE active = true AND (slug = $1 OR id::text = $1)
+  `, slugOrID).Scan(&a.ID, &a.Slug, &a.Name, &a.Bio, &a.City, &a.State)
+	if err == pgx.ErrNoRows {
+		return nil, ErrVendorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *Store) CreateVendor(ctx context.Context, v *model.Vendor) (string, error) {
+	var id string
+	err := s.db.QueryRow(ctx, `
+    INSERT INTO vendors (slug, name, bio, city, state, payout_ref, commission_rate, active)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING id
+  `, v.Slug, v.Name, v.Bio, v.City, v.State, v.PayoutRef, v.CommissionRate, v.Active).Scan(&id)
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+		return "", ErrDuplicateVendor
+	}
+	return id, err
+}
+
+func (s *Store) UpdateVendor(ctx context.Context, id string, v *model.Vendor) error {
+	cmd, err := s.db.Exec(ctx, `
+    UPDATE vendors SET
+      slug = $1, name = $2, bio = $3, city = $4, state = $5,
+      payout_ref = $6, commission_rate = $7, active = $8
+    WHERE id = $9
+  `, v.Slug, v.Name, v.Bio, v.City, v.State, v.PayoutRef, v.CommissionRate, v.Active, id)
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+		return ErrDuplicateVendor
+	}
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrVendorNotFound
+	}
+	return nil
+}
+
+func (s *Store) SetVendorAPIKeyHash(ctx context.Context, id, hash string) error {
+	cmd, err := s.db.Exec(ctx, `UPDATE vendors SET api_key_hash = $1 WHERE id = $2`, hash, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrVendorNotFound
+	}
+	return nil
+}
+
+// AssignProductVendor links a product to a vendor, or unlinks it when
+// vendorID is nil.
+func (s *Store) AssignProductVendor(ctx context.Context, productID string, vendorID *string) error {
+	cmd, err := s.db.Exec(ctx, `UPDATE products SET vendor_id = $1 WHERE id = $2 AND deleted_at IS NULL`, vendorID, productID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("product not found")
+	}
+	return nil
+}
+
+// CheckVendorOwnership returns ErrNotVendorOwned unless the live product
+// belongs to the vendor.
+func (s *Store) CheckVendorOwnership(ctx context.Context, vendorID, productID string) error {
+	var ok bool
+	err := s.db.QueryRow(ctx, `
+    SELECT EXISTS (
+      SELECT 1 FROM products
+      WHERE id = $1 AND vendor_id = $2 AND deleted_at IS NULL
+    )
+  `, productID, vendorID).Scan(&ok)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotVendorOwned
+	}
+	return nil
+}
+
+func (s *Store) SetStock(ctx context.Context, productID string, stock int) error {
+	if stock < 0 {
+		return fmt.Errorf("invalid stock")
+	}
+	cmd, err := s.db.Exec(ctx, `UPDATE products SET stock = $1 WHERE id = $2 AND deleted_at IS NULL AND NOT is_bundle`, stock, productID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("product not found or is a bundle with derived stock")
+	}
+	return nil
+}
+
+// ListArtisanProducts returns a vendor's published products, newest first.
+func (s *Store) ListArtisanProducts(ctx context.Context, vendorID string, limit, offset int) ([]model.ProductCard, int, error) {
+	var total int
+	if err := s.db.QueryRow(ctx, `
+    SELECT COUNT(*) FROM products
+    WHERE vendor_id = $1 AND published = true AND deleted_at IS NULL
+  `, vendorID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+    SELECT
+      p.id, p.title, p.slug, p.published, p.category, p.price,
+      COALESCE((SELECT url FROM product_media WHERE product_id = p.id ORDER BY (meta->>'order')::int LIMIT 1), '') AS image_url,
+      p.attributes, p.stock > 0
+    FROM products p
+    WHERE p.vendor_id = $1 AND p.published = true AND p.deleted_at IS NULL
+    ORDER BY p.created_at DESC
+    LIMIT $2 OFFSET $3
+  `, vendorID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []model.ProductCard{}
+	for rows.Next() {
+		var pc model.ProductCard
+		if err := rows.Scan(&pc.ID, &pc.Title, &pc.Slug, &pc.Published, &pc.Category, &pc.Price, &pc.ImageURL, &pc.Attrs, &pc.InStock); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, pc)
+	}
+	return items, total, rows.Err()
+}
@@ -0,0 +1,75 @@
+package trash
+
+import (
+	"context"
+	"log"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/cache"
+	"github.com/devmanishoffl/sabhyatam-product/internal/gateway"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+)
+
+// PurgeResult reports what a hard delete removed. FileErrors lists stored
+// files that could not be deleted; the database rows are gone regardless.
+type PurgeResult struct {
+	ProductID    string   `json:"product_id"`
+	MediaRemoved int      `json:"media_removed"`
+	FilesRemoved int      `json:"files_removed"`
+	FileErrors   []string `json:"file_errors,omitempty"`
+}
+
+type Purger struct {
+	store *store.Store
+	media *gateway.S3Gateway
+	cache *cache.ProductCache
+}
+
+func NewPurger(s *store.Store, media *gateway.S3Gateway, pc *cache.ProductCache) *Purger {
+	return &Purger{store: s, media: media, cache: pc}
+}
+
+// Purge permanently removes a trashed product, its product_media rows and
+// the files they point at in the media bucket.
+func (p *Purger) Purge(ctx context.Context, productID string) (*PurgeResult, error) {
+	media, err := p.store.PurgeProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.Invalidate(ctx, productID)
+
+	res := &PurgeResult{ProductID: productID, MediaRemoved: len(media)}
+	for _, m := range media {
+		key, ok := p.media.KeyFromURL(m.URL)
+		if !ok {
+			continue
+		}
+		if err := p.media.DeleteObject(ctx, key); err != nil {
+			log.Println("purge: failed to delete media object:", key, err)
+			res.FileErrors = append(res.FileErrors, key)
+			continue
+		}
+		res.FilesRemoved++
+	}
+	return res, nil
+}
+
+// PurgeExpired hard-deletes up to limit products that have been in the
+// trash longer than retentionDays. Components of a bundle stay in the trash
+// until they are taken out of it.
+func (p *Purger) PurgeExpired(ctx context.Context, retentionDays, limit int) ([]PurgeResult, error) {
+	ids, err := p.store.ListExpiredTrash(ctx, retentionDays, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []PurgeResult
+	for _, id := range ids {
+		res, err := p.Purge(ctx, id)
+		if err != nil {
+			log.Println("purge: failed to purge product:", id, err)
+			continue
+		}
+		out = append(out, *res)
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/feed"
+)
+
+type FeedRefreshWorker struct {
+	generator *feed.Generator
+	interval  time.Duration
+}
+
+func NewFeedRefreshWorker(g *feed.Generator, interval time.Duration) *FeedRefreshWorker {
+	return &FeedRefreshWorker{
+		generator: g,
+		interval:  interval,
+	}
+}
+
+func (w *FeedRefreshWorker) Run(ctx context.Context) {
+	w.refresh(ctx)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.refresh(ctx)
+		}
+	}
+}
+
+func (w *FeedRefreshWorker) refresh(ctx context.Context) {
+	snap, err := w.generator.Regenerate(ctx)
+	if err != nil {
+		log.Println("feed refresh failed:", err)
+		return
+	}
+	log.Printf("feeds regenerated: %d items, %d excluded, %d issues",
+		snap.ItemCount, snap.Excluded, len(snap.Issues))
+}
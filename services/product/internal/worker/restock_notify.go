@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/config"
+	"github.com/devmanishoffl/sabhyatam-product/internal/model"
+	"github.com/devmanishoffl/sabhyatam-product/internal/notify"
+	"github.com/devmanishoffl/sabhyatam-product/internal/store"
+)
+
+// RestockNotifyWorker sends back-in-stock notices. Restocks are detected in
+// the database (see migration 012); the worker polls for due subscriptions
+// and can be kicked right after a stock change to send without waiting for
+// the next tick.
+type RestockNotifyWorker struct {
+	store    *store.Store
+	sender   notify.Sender
+	cfg      *config.Config
+	interval time.Duration
+	kick     chan struct{}
+}
+
+func NewRestockNotifyWorker(s *store.Store, sender notify.Sender, cfg *config.Config) *RestockNotifyWorker {
+	return &RestockNotifyWorker{
+		store:    s,
+		sender:   sender,
+		cfg:      cfg,
+		interval: 1 * time.Minute,
+		kick:     make(chan struct{}, 1),
+	}
+}
+
+// Kick asks for a sweep soon. It never blocks; kicks during a sweep
+// coalesce into one more sweep.
+func (w *RestockNotifyWorker) Kick() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// SendConfirmation mails an email subscriber the links that confirm or
+// cancel their subscription. It goes out right away through the same
+// sender as the notices.
+func (w *RestockNotifyWorker) SendConfirmation(ctx context.Context, c *model.RestockConfirmation, token string) error {
+	return w.sender.SendConfirmation(ctx, notify.Confirmation{
+		RestockConfirmation: *c,
+		ConfirmURL:          w.cfg.RestockConfirmURL(token),
+		CancelURL:           w.cfg.RestockCancelURL(token),
+	})
+}
+
+func (w *RestockNotifyWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		case <-w.kick:
+			w.sweep(ctx)
+		}
+	}
+}
+
+// sweep sends at most one batch, which together with the tick interval
+// bounds the send rate. Each recipient gets at most one notice per sweep
+// and per cooldown.
+func (w *RestockNotifyWorker) sweep(ctx context.Context) {
+	notices, err := w.store.ClaimRestockNotices(ctx, w.cfg.RestockRecipientCooldown, w.cfg.RestockBatchSize)
+	if err != nil {
+		log.Println("restock sweep failed:", err)
+		return
+	}
+
+	sent := map[string]bool{}
+	for _, n := range notices {
+		if sent[n.Recipient] {
+			if err := w.store.ReleaseRestockNotice(ctx, n.SubscriptionID); err != nil {
+				log.Println("restock release failed:", err)
+			}
+			continue
+		}
+		sent[n.Recipient] = true
+
+		msg := notify.Message{RestockNotice: n, URL: w.cfg.ProductURL(n.Slug)}
+		if err := w.sender.Send(ctx, msg); err != nil {
+			log.Printf("restock notice %s failed: %v", n.SubscriptionID, err)
+			if err := w.store.FailRestockNotice(ctx, n.SubscriptionID, err); err != nil {
+				log.Println("restock fail record failed:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/devmanishoffl/sabhyatam-product/internal/trash"
+)
+
+// trashBatchSize bounds how many products one sweep purges so a large
+// backlog is worked off gradually.
+const trashBatchSize = 100
+
+type TrashRetentionWorker struct {
+	purger        *trash.Purger
+	retentionDays int
+}
+
+func NewTrashRetentionWorker(p *trash.Purger, retentionDays int) *TrashRetentionWorker {
+	return &TrashRetentionWorker{
+		purger:        p,
+		retentionDays: retentionDays,
+	}
+}
+
+func (w *TrashRetentionWorker) Run(ctx context.Context) {
+	if w.retentionDays <= 0 {
+		log.Println("trash retention disabled")
+		return
+	}
+
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+func (w *TrashRetentionWorker) sweep(ctx context.Context) {
+	purged, err := w.purger.PurgeExpired(ctx, w.retentionDays, trashBatchSize)
+	if err != nil {
+		log.Println("trash sweep failed:", err)
+		return
+	}
+	for _, p := range purged {
+		log.Printf("purged trashed product %s (%d media, %d files)", p.ProductID, p.MediaRemoved, p.FilesRemoved)
+	}
+}
@@ -0,0 +1,14 @@
+-- Soft-deleted products no longer hold their slug/SKU; uniqueness is only
+-- enforced among live products so a trashed item can be recreated, and a
+-- restore has to re-check for conflicts.
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_slug_key;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_sku_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug_live
+ON products (slug) WHERE deleted_at IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku_live
+ON products (sku) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+ON products (deleted_at) WHERE deleted_at IS NOT NULL;
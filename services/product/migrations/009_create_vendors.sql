@@ -0,0 +1,57 @@
+-- vendors: artisans and workshops selling through the marketplace
+CREATE TABLE IF NOT EXISTS vendors (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  slug TEXT UNIQUE NOT NULL,
+  name TEXT NOT NULL,
+  bio TEXT,
+  city TEXT,
+  state TEXT,
+  -- reference into the payments provider / finance system; bank details
+  -- are never stored here
+  payout_ref TEXT,
+  commission_rate NUMERIC(5,2) NOT NULL DEFAULT 0
+    CHECK (commission_rate >= 0 AND commission_rate <= 100),
+  api_key_hash TEXT UNIQUE,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS set_timestamp_vendor ON vendors;
+CREATE TRIGGER set_timestamp_vendor BEFORE UPDATE ON vendors
+FOR EACH ROW EXECUTE FUNCTION trigger_set_timestamp();
+
+-- products.vendor_id has existed since 001 but was never constrained
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'products_vendor_id_fkey'
+  ) THEN
+    ALTER TABLE products
+    ADD CONSTRAINT products_vendor_id_fkey
+    FOREIGN KEY (vendor_id) REFERENCES vendors(id) ON DELETE SET NULL;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_products_vendor ON products (vendor_id);
+
+-- Backfill vendors from the legacy attributes.artisan blob
+INSERT INTO vendors (slug, name, city)
+SELECT DISTINCT ON (slug) slug, name, place
+FROM (
+  SELECT
+    trim(both '-' from lower(regexp_replace(attributes->'artisan'->>'name', '[^a-zA-Z0-9]+', '-', 'g'))) AS slug,
+    attributes->'artisan'->>'name' AS name,
+    attributes->'artisan'->>'place' AS place
+  FROM products
+  WHERE COALESCE(attributes->'artisan'->>'name', '') <> ''
+) a
+WHERE slug <> ''
+ON CONFLICT (slug) DO NOTHING;
+
+UPDATE products p
+SET vendor_id = v.id
+FROM vendors v
+WHERE p.vendor_id IS NULL
+  AND COALESCE(p.attributes->'artisan'->>'name', '') <> ''
+  AND v.slug = trim(both '-' from lower(regexp_replace(p.attributes->'artisan'->>'name', '[^a-zA-Z0-9]+', '-', 'g')));
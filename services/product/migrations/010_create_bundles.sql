@@ -0,0 +1,40 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS is_bundle BOOLEAN NOT NULL DEFAULT false;
+
+-- components of a bundle product; a component cannot itself be a bundle
+CREATE TABLE IF NOT EXISTS product_bundle_items (
+  bundle_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  component_id UUID NOT NULL REFERENCES products(id) ON DELETE RESTRICT,
+  quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0),
+  PRIMARY KEY (bundle_id, component_id),
+  CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bundle_items_component
+ON product_bundle_items (component_id);
+
+-- A bundle's stock is derived: how many complete sets the components can
+-- make. Keep it materialised on the bundle row so every read path (listing,
+-- search, detail, cart) sees the same number.
+CREATE OR REPLACE FUNCTION refresh_bundle_stock()
+RETURNS TRIGGER AS $$
+BEGIN
+  UPDATE products b
+  SET stock = COALESCE((
+    SELECT MIN(CASE WHEN c.deleted_at IS NULL THEN c.stock / bi.quantity ELSE 0 END)
+    FROM product_bundle_items bi
+    JOIN products c ON c.id = bi.component_id
+    WHERE bi.bundle_id = b.id
+  ), 0)
+  WHERE b.is_bundle
+    AND b.id IN (SELECT bundle_id FROM product_bundle_items WHERE component_id = NEW.id);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS bundle_stock_sync ON products;
+CREATE TRIGGER bundle_stock_sync
+AFTER UPDATE OF stock, deleted_at ON products
+FOR EACH ROW
+WHEN (NOT NEW.is_bundle AND (OLD.stock IS DISTINCT FROM NEW.stock OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at))
+EXECUTE FUNCTION refresh_bundle_stock();
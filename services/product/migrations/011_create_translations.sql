@@ -0,0 +1,35 @@
+-- per-locale product copy; the products row itself holds English
+CREATE TABLE IF NOT EXISTS product_translations (
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  locale TEXT NOT NULL,
+  title TEXT NOT NULL DEFAULT '',
+  short_desc TEXT NOT NULL DEFAULT '',
+  long_desc TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  PRIMARY KEY (product_id, locale)
+);
+
+DROP TRIGGER IF EXISTS set_timestamp_product_translation ON product_translations;
+CREATE TRIGGER set_timestamp_product_translation BEFORE UPDATE ON product_translations
+FOR EACH ROW EXECUTE FUNCTION trigger_set_timestamp();
+
+CREATE INDEX IF NOT EXISTS idx_product_translations_locale
+ON product_translations (locale);
+
+-- display names for category slugs, e.g. ('saree', 'hi', 'साड़ी')
+CREATE TABLE IF NOT EXISTS category_translations (
+  category TEXT NOT NULL,
+  locale TEXT NOT NULL,
+  label TEXT NOT NULL,
+  PRIMARY KEY (category, locale)
+);
+
+-- display labels for attribute values, e.g. ('fabric', 'silk', 'ta', 'பட்டு')
+CREATE TABLE IF NOT EXISTS attribute_value_translations (
+  attribute TEXT NOT NULL,
+  value TEXT NOT NULL,
+  locale TEXT NOT NULL,
+  label TEXT NOT NULL,
+  PRIMARY KEY (attribute, value, locale)
+);
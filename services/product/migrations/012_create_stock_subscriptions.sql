@@ -0,0 +1,49 @@
+-- back-in-stock subscriptions; recipient is 'user:<id>' or 'email:<address>'
+-- and is what deduplication and rate limiting key on
+CREATE TABLE IF NOT EXISTS stock_subscriptions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  recipient TEXT NOT NULL,
+  user_id TEXT,
+  email TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  -- set by the stock_restocked trigger when stock goes from 0 to positive
+  restocked_at TIMESTAMP WITH TIME ZONE,
+  notified_at TIMESTAMP WITH TIME ZONE,
+  failed_at TIMESTAMP WITH TIME ZONE,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  CHECK (user_id IS NOT NULL OR email IS NOT NULL)
+);
+
+-- one pending subscription per recipient and product
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_subscriptions_pending
+ON stock_subscriptions (product_id, recipient)
+WHERE notified_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_subscriptions_due
+ON stock_subscriptions (restocked_at)
+WHERE notified_at IS NULL AND failed_at IS NULL AND restocked_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_subscriptions_recipient_sent
+ON stock_subscriptions (recipient, notified_at);
+
+CREATE OR REPLACE FUNCTION mark_restocked_subscriptions()
+RETURNS TRIGGER AS $$
+BEGIN
+  UPDATE stock_subscriptions
+  SET restocked_at = now()
+  WHERE product_id = NEW.id
+    AND notified_at IS NULL AND failed_at IS NULL AND restocked_at IS NULL;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- fires for direct edits, released reservations, restores of stock and
+-- re-derived bundle stock alike
+DROP TRIGGER IF EXISTS stock_restocked ON products;
+CREATE TRIGGER stock_restocked
+AFTER UPDATE OF stock ON products
+FOR EACH ROW
+WHEN (OLD.stock <= 0 AND NEW.stock > 0)
+EXECUTE FUNCTION mark_restocked_subscriptions();
@@ -0,0 +1,15 @@
+-- background bulk product operations and their per-item results
+CREATE TABLE IF NOT EXISTS bulk_jobs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  status TEXT NOT NULL DEFAULT 'running',
+  operation JSONB NOT NULL,
+  total INT NOT NULL DEFAULT 0,
+  succeeded INT NOT NULL DEFAULT 0,
+  failed INT NOT NULL DEFAULT 0,
+  error TEXT,
+  results JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_bulk_jobs_created_at ON bulk_jobs (created_at DESC);
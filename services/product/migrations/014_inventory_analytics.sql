@@ -0,0 +1,27 @@
+-- purchase cost per unit in rupees, for inventory valuation; optional
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price INT CHECK (cost_price >= 0);
+
+-- sales counters maintained by DeductStock (a confirmed sale)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS units_sold INT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS last_sold_at TIMESTAMP WITH TIME ZONE;
+
+-- seed the counters from paid orders when the orders tables share this
+-- database; only the first run backfills
+DO $$
+BEGIN
+  IF to_regclass('public.order_items') IS NOT NULL
+     AND NOT EXISTS (SELECT 1 FROM products WHERE units_sold > 0) THEN
+    UPDATE products p
+    SET units_sold = s.qty, last_sold_at = s.last_at
+    FROM (
+      SELECT oi.product_id, SUM(oi.quantity) AS qty, MAX(o.created_at) AS last_at
+      FROM order_items oi
+      JOIN orders o ON o.id = oi.order_id
+      WHERE o.status = 'paid'
+      GROUP BY oi.product_id
+    ) s
+    WHERE p.id = s.product_id;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_products_last_sold_at ON products (last_sold_at);
@@ -0,0 +1,19 @@
+-- catalogue attribute definitions: display label, unit and ordering for
+-- product attributes (products.attributes keys)
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+  key TEXT PRIMARY KEY,
+  label TEXT NOT NULL,
+  value_type TEXT NOT NULL DEFAULT 'text'
+    CHECK (value_type IN ('text', 'number', 'boolean')),
+  unit TEXT,
+  position INT NOT NULL DEFAULT 0,
+  comparable BOOLEAN NOT NULL DEFAULT true
+);
+
+INSERT INTO attribute_definitions (key, label, value_type, unit, position) VALUES
+  ('fabric', 'Fabric', 'text', NULL, 10),
+  ('weave', 'Weave', 'text', NULL, 20),
+  ('length', 'Saree length', 'number', 'm', 30),
+  ('blouse_included', 'Blouse piece included', 'boolean', NULL, 40),
+  ('care', 'Care', 'text', NULL, 50)
+ON CONFLICT (key) DO NOTHING;
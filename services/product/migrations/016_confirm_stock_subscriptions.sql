@@ -0,0 +1,23 @@
+-- email subscriptions are only notified once the address is confirmed;
+-- token_hash is the sha256 of the token mailed to confirm or cancel, the
+-- token itself is only in the link
+ALTER TABLE stock_subscriptions
+  ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN IF NOT EXISTS token_hash TEXT,
+  ADD COLUMN IF NOT EXISTS confirmation_sent_at TIMESTAMP WITH TIME ZONE;
+
+-- subscriptions without an address never mail anyone; ones with an
+-- unconfirmed address wait until it is confirmed by subscribing again
+UPDATE stock_subscriptions
+SET confirmed_at = created_at
+WHERE confirmed_at IS NULL AND email IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_subscriptions_token
+ON stock_subscriptions (token_hash)
+WHERE token_hash IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_stock_subscriptions_due;
+CREATE INDEX idx_stock_subscriptions_due
+ON stock_subscriptions (restocked_at)
+WHERE notified_at IS NULL AND failed_at IS NULL AND restocked_at IS NOT NULL
+  AND confirmed_at IS NOT NULL;
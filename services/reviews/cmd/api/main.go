@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/devmanishoffl/sabhyatam-reviews/internal/client/orders"
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/domain"
 	reviewMiddleware "github.com/devmanishoffl/sabhyatam-reviews/internal/middleware"
 	"github.com/devmanishoffl/sabhyatam-reviews/internal/repository"
 	"github.com/devmanishoffl/sabhyatam-reviews/internal/service"
@@ -47,7 +48,8 @@ func main() {
 	repo := repository.New(db)
 	ordersClient := orders.New(ordersBaseURL)
 	reviewSvc := service.New(repo, ordersClient)
-	handler := transport.New(reviewSvc)
+	questionSvc := service.NewQuestionService(repository.NewQuestionRepository(db), ordersClient)
+	handler := transport.New(reviewSvc, questionSvc)
 
 	// ---------- Router ----------
 	r := chi.NewRouter()
@@ -69,6 +71,11 @@ func main() {
 		handler.RatingSummary,
 	)
 
+	r.Get(
+		"/v1/products/{productID}/questions",
+		handler.ListQuestions,
+	)
+
 	// ---------- Authenticated user routes ----------
 	r.Group(func(r chi.Router) {
 		r.Use(reviewMiddleware.SupabaseAuth)
@@ -77,6 +84,14 @@ func main() {
 			"/reviews",
 			handler.Create,
 		)
+
+		// Q&A
+		r.Post("/v1/products/{productID}/questions", handler.AskQuestion)
+		r.Post("/v1/questions/{id}/answers", handler.AnswerQuestion)
+		r.Post("/v1/questions/{id}/helpful", handler.VoteQuestion())
+		r.Delete("/v1/questions/{id}/helpful", handler.UnvoteQuestion())
+		r.Post("/v1/answers/{id}/helpful", handler.VoteAnswer())
+		r.Delete("/v1/answers/{id}/helpful", handler.UnvoteAnswer())
 	})
 
 	// ---------- Admin routes ----------
@@ -88,6 +103,14 @@ func main() {
 			"/v1/admin/reviews/{id}/approve",
 			handler.ApproveReview,
 		)
+
+		// Q&A moderation
+		r.Get("/v1/admin/questions/pending", handler.ListPendingQA)
+		r.Post("/v1/admin/questions/{id}/answers", handler.AdminAnswerQuestion)
+		r.Post("/v1/admin/questions/{id}/approve", handler.ModerateQuestion(domain.Approved))
+		r.Post("/v1/admin/questions/{id}/reject", handler.ModerateQuestion(domain.Rejected))
+		r.Post("/v1/admin/answers/{id}/approve", handler.ModerateAnswer(domain.Approved))
+		r.Post("/v1/admin/answers/{id}/reject", handler.ModerateAnswer(domain.Rejected))
 	})
 
 	// ---------- Start server ----------
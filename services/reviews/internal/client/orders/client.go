@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -65,3 +66,48 @@ func (c *Client) IsOrderItemDelivered(
 
 	return out.Eligible, nil
 }
+
+type purchaseResponse struct {
+	Purchased bool `json:"purchased"`
+}
+
+func (c *Client) HasPurchasedProduct(
+	ctx context.Context,
+	userID string,
+	productID string,
+) (bool, error) {
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf(
+			"%s/internal/products/%s/purchase-check",
+			c.baseURL,
+			productID,
+		),
+		nil,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("X-USER-ID", userID)
+	req.Header.Set("X-INTERNAL-KEY", os.Getenv("INTERNAL_SERVICE_KEY"))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("purchase check failed: %s", resp.Status)
+	}
+
+	var out purchaseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return false, err
+	}
+
+	return out.Purchased, nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// AuthorRole says who wrote an answer.
+type AuthorRole string
+
+const (
+	AuthorAdmin AuthorRole = "admin"
+	AuthorBuyer AuthorRole = "buyer"
+)
+
+type Question struct {
+	ID        string `json:"id"`
+	ProductID string `json:"product_id"`
+	UserID    string `json:"user_id"`
+
+	Body string `json:"body"`
+
+	Status       ReviewStatus `json:"status"`
+	HelpfulCount int          `json:"helpful_count"`
+
+	Answers []Answer `json:"answers"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Answer struct {
+	ID         string `json:"id"`
+	QuestionID string `json:"question_id"`
+	// UserID is empty for answers written by the shop.
+	UserID     string     `json:"user_id,omitempty"`
+	AuthorRole AuthorRole `json:"author_role"`
+
+	Body string `json:"body"`
+
+	Status       ReviewStatus `json:"status"`
+	HelpfulCount int          `json:"helpful_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuestionPage is one page of a product's approved questions.
+type QuestionPage struct {
+	Questions []Question `json:"questions"`
+	Page      int        `json:"page"`
+	Limit     int        `json:"limit"`
+	Total     int        `json:"total"`
+}
+
+// PendingQA is the moderation queue.
+type PendingQA struct {
+	Questions []Question `json:"questions"`
+	Answers   []Answer   `json:"answers"`
+}
@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("answer not found")
+	ErrAlreadyVoted     = errors.New("already voted")
+	ErrVoteNotFound     = errors.New("vote not found")
+	ErrOwnPost          = errors.New("cannot vote on your own post")
+)
+
+// Vote targets.
+const (
+	TargetQuestion = "question"
+	TargetAnswer   = "answer"
+)
+
+type QuestionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewQuestionRepository(db *pgxpool.Pool) *QuestionRepository {
+	return &QuestionRepository{db: db}
+}
+
+// CreateQuestion stores a pending question on a live, published product.
+// The products table belongs to the product service but shares the database.
+func (r *QuestionRepository) CreateQuestion(ctx context.Context, q *domain.Question) error {
+	err := r.db.QueryRow(ctx, `
+	insert into product_questions (product_id, user_id, body)
+	select $1, $2, $3
+	where exists (
+		select 1 from products
+		where id = $1 and deleted_at is null and published = true
+	)
+	returning id, status, helpful_count, created_at, updated_at
+	`, q.ProductID, q.UserID, q.Body).Scan(&q.ID, &q.Status, &q.HelpfulCount, &q.CreatedAt, &q.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return ErrProductNotFound
+	}
+	return err
+}
+
+func (r *QuestionRepository) GetQuestion(ctx context.Context, id string) (*domain.Question, error) {
+	var q domain.Question
+	err := r.db.QueryRow(ctx, `
+		select id, product_id, user_id, body, status, helpful_count, created_at, updated_at
+		from product_questions
+		where id = $1
+	`, id).Scan(&q.ID, &q.ProductID, &q.UserID, &q.Body, &q.Status, &q.HelpfulCount, &q.CreatedAt, &q.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *QuestionRepository) CreateAnswer(ctx context.Context, a *domain.Answer) error {
+	var userID *string
+	if a.UserID != "" {
+		userID = &a.UserID
+	}
+	return r.db.QueryRow(ctx, `
+	insert into product_answers (question_id, user_id, author_role, body, status)
+	values ($1, $2, $3, $4, $5)
+	returning id, helpful_count, created_at, updated_at
+	`, a.QuestionID, userID, a.AuthorRole, a.Body, a.Status).Scan(&a.ID, &a.HelpfulCount, &a.CreatedAt, &a.UpdatedAt)
+}
+
+// ListApprovedByProduct returns a page of approved questions with their
+// approved answers, and the total number of approved questions. sort is
+// "recent" or "helpful".
+func (r *QuestionRepository) ListApprovedByProduct(
+	ctx context.Context,
+	productID string,
+	sort string,
+	limit int,
+	offset int,
+) ([]domain.Question, int, error) {
+
+	var total int
+	if err := r.db.QueryRow(ctx, `
+		select count(*) from product_questions
+		where product_id = $1 and status = 'approved'
+	`, productID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := "helpful_count desc, created_at desc"
+	if sort == "recent" {
+		order = "created_at desc"
+	}
+
+	rows, err := r.db.Query(ctx, `
+		select id, product_id, user_id, body, status, helpful_count, created_at, updated_at
+		from product_questions
+		where product_id = $1
+		  and status = 'approved'
+		order by `+order+`
+		limit $2 offset $3
+	`, productID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	questions, err := scanQuestions(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]string, len(questions))
+	for i := range questions {
+		ids[i] = questions[i].ID
+	}
+	answers, err := r.approvedAnswers(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range questions {
+		questions[i].Answers = answers[questions[i].ID]
+		if questions[i].Answers == nil {
+			questions[i].Answers = []domain.Answer{}
+		}
+	}
+	return questions, total, nil
+}
+
+// approvedAnswers loads approved answers keyed by question. Shop answers
+// come first, then the most helpful.
+func (r *QuestionRepository) approvedAnswers(ctx context.Context, questionIDs []string) (map[string][]domain.Answer, error) {
+	out := map[string][]domain.Answer{}
+	if len(questionIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, `
+		select id, question_id, coalesce(user_id::text, ''), author_role, body,
+		       status, helpful_count, created_at, updated_at
+		from product_answers
+		where question_id = any($1::uuid[])
+		  and status = 'approved'
+		order by (author_role = 'admin') desc, helpful_count desc, created_at
+	`, questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := scanAnswers(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range answers {
+		out[a.QuestionID] = append(out[a.QuestionID], a)
+	}
+	return out, nil
+}
+
+func (r *QuestionRepository) ListPendingQuestions(ctx context.Context) ([]domain.Question, error) {
+	rows, err := r.db.Query(ctx, `
+		select id, product_id, user_id, body, status, helpful_count, created_at, updated_at
+		from product_questions
+		where status = 'pending'
+		order by created_at asc
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanQuestions(rows)
+}
+
+func (r *QuestionRepository) ListPendingAnswers(ctx context.Context) ([]domain.Answer, error) {
+	rows, err := r.db.Query(ctx, `
+		select id, question_id, coalesce(user_id::text, ''), author_role, body,
+		       status, helpful_count, created_at, updated_at
+		from product_answers
+		where status = 'pending'
+		order by created_at asc
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanAnswers(rows)
+}
+
+func (r *QuestionRepository) SetQuestionStatus(ctx context.Context, id string, status domain.ReviewStatus) error {
+	cmd, err := r.db.Exec(ctx, `
+	update product_questions
+	set status = $2, updated_at = now()
+	where id = $1
+	`, id, status)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrQuestionNotFound
+	}
+	return nil
+}
+
+func (r *QuestionRepository) SetAnswerStatus(ctx context.Context, id string, status domain.ReviewStatus) error {
+	cmd, err := r.db.Exec(ctx, `
+	update product_answers
+	set status = $2, updated_at = now()
+	where id = $1
+	`, id, status)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrAnswerNotFound
+	}
+	return nil
+}
+
+// Vote records a helpful vote on an approved question or answer and bumps
+// its count in the same transaction.
+func (r *QuestionRepository) Vote(ctx context.Context, targetType, targetID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkVoteTarget(ctx, tx, targetType, targetID, userID); err != nil {
+		return err
+	}
+
+	cmd, err := tx.Exec(ctx, `
+	insert into qa_helpful_votes (target_type, target_id, user_id)
+	values ($1, $2, $3)
+	on conflict do nothing
+	`, targetType, targetID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrAlreadyVoted
+	}
+
+	if _, err := tx.Exec(ctx, `
+	update `+voteTable(targetType)+`
+	set helpful_count = helpful_count + 1
+	where id = $1
+	`, targetID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *QuestionRepository) Unvote(ctx context.Context, targetType, targetID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, `
+	delete from qa_helpful_votes
+	where target_type = $1 and target_id = $2 and user_id = $3
+	`, targetType, targetID, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return ErrVoteNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+	update `+voteTable(targetType)+`
+	set helpful_count = greatest(helpful_count - 1, 0)
+	where id = $1
+	`, targetID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func voteTable(targetType string) string {
+	if targetType == TargetAnswer {
+		return "product_answers"
+	}
+	return "product_questions"
+}
+
+// checkVoteTarget locks the target row and rejects unapproved targets and
+// votes on one's own post.
+func checkVoteTarget(ctx context.Context, tx pgx.Tx, targetType, targetID, userID string) error {
+	notFound := ErrQuestionNotFound
+	if targetType == TargetAnswer {
+		notFound = ErrAnswerNotFound
+	}
+
+	var author string
+	err := tx.QueryRow(ctx, `
+	select coalesce(user_id::text, '')
+	from `+voteTable(targetType)+`
+	where id = $1 and status = 'approved'
+	for update
+	`, targetID).Scan(&author)
+	if err == pgx.ErrNoRows {
+		return notFound
+	}
+	if err != nil {
+		return err
+	}
+	if author == userID {
+		return ErrOwnPost
+	}
+	return nil
+}
+
+func scanQuestions(rows pgx.Rows) ([]domain.Question, error) {
+	defer rows.Close()
+
+	questions := []domain.Question{}
+	for rows.Next() {
+		var q domain.Question
+		if err := rows.Scan(
+			&q.ID, &q.ProductID, &q.UserID, &q.Body,
+			&q.Status, &q.HelpfulCount, &q.CreatedAt, &q.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+func scanAnswers(rows pgx.Rows) ([]domain.Answer, error) {
+	defer rows.Close()
+
+	answers := []domain.Answer{}
+	for rows.Next() {
+		var a domain.Answer
+		if err := rows.Scan(
+			&a.ID, &a.QuestionID, &a.UserID, &a.AuthorRole, &a.Body,
+			&a.Status, &a.HelpfulCount, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		answers = append(answers, a)
+	}
+	return answers, rows.Err()
+}
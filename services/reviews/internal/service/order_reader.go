@@ -9,3 +9,11 @@ type OrderReader interface {
 		userID string,
 	) (bool, error)
 }
+
+type PurchaseReader interface {
+	HasPurchasedProduct(
+		ctx context.Context,
+		userID string,
+		productID string,
+	) (bool, error)
+}
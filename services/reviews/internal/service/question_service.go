@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/domain"
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/repository"
+)
+
+var (
+	ErrInvalidBody      = errors.New("body must be between 10 and 1000 characters")
+	ErrNotVerifiedBuyer = errors.New("only buyers of this product can answer")
+	ErrQuestionClosed   = errors.New("question is not open for answers")
+)
+
+const (
+	minQABody = 10
+	maxQABody = 1000
+)
+
+type QuestionService struct {
+	repo   *repository.QuestionRepository
+	orders PurchaseReader
+}
+
+func NewQuestionService(repo *repository.QuestionRepository, orders PurchaseReader) *QuestionService {
+	return &QuestionService{repo: repo, orders: orders}
+}
+
+func cleanBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if n := utf8.RuneCountInString(body); n < minQABody || n > maxQABody {
+		return "", ErrInvalidBody
+	}
+	return body, nil
+}
+
+// Ask files a question for moderation.
+func (s *QuestionService) Ask(
+	ctx context.Context,
+	userID string,
+	productID string,
+	body string,
+) (*domain.Question, error) {
+
+	body, err := cleanBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &domain.Question{
+		ProductID: productID,
+		UserID:    userID,
+		Body:      body,
+		Answers:   []domain.Answer{},
+	}
+	if err := s.repo.CreateQuestion(ctx, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Answer files a customer answer for moderation. Only users with a paid
+// order for the product may answer, and only approved questions.
+func (s *QuestionService) Answer(
+	ctx context.Context,
+	userID string,
+	questionID string,
+	body string,
+) (*domain.Answer, error) {
+
+	body, err := cleanBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.repo.GetQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if q.Status != domain.Approved {
+		return nil, ErrQuestionClosed
+	}
+
+	ok, err := s.orders.HasPurchasedProduct(ctx, userID, q.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotVerifiedBuyer
+	}
+
+	a := &domain.Answer{
+		QuestionID: questionID,
+		UserID:     userID,
+		AuthorRole: domain.AuthorBuyer,
+		Body:       body,
+		Status:     domain.Pending,
+	}
+	if err := s.repo.CreateAnswer(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// AdminAnswer publishes a shop answer straight away. Answering a pending
+// question approves it too; rejected questions stay closed.
+func (s *QuestionService) AdminAnswer(
+	ctx context.Context,
+	questionID string,
+	body string,
+) (*domain.Answer, error) {
+
+	body, err := cleanBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.repo.GetQuestion(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	switch q.Status {
+	case domain.Rejected:
+		return nil, ErrQuestionClosed
+	case domain.Pending:
+		if err := s.repo.SetQuestionStatus(ctx, questionID, domain.Approved); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &domain.Answer{
+		QuestionID: questionID,
+		AuthorRole: domain.AuthorAdmin,
+		Body:       body,
+		Status:     domain.Approved,
+	}
+	if err := s.repo.CreateAnswer(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *QuestionService) ListProductQuestions(
+	ctx context.Context,
+	productID string,
+	sort string,
+	page int,
+	limit int,
+) (*domain.QuestionPage, error) {
+
+	if page < 1 {
+		page = 1
+	}
+	offset := (page - 1) * limit
+
+	questions, total, err := s.repo.ListApprovedByProduct(ctx, productID, sort, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.QuestionPage{
+		Questions: questions,
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+	}, nil
+}
+
+func (s *QuestionService) ListPending(ctx context.Context) (*domain.PendingQA, error) {
+	questions, err := s.repo.ListPendingQuestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.repo.ListPendingAnswers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PendingQA{Questions: questions, Answers: answers}, nil
+}
+
+func (s *QuestionService) ModerateQuestion(ctx context.Context, id string, status domain.ReviewStatus) error {
+	return s.repo.SetQuestionStatus(ctx, id, status)
+}
+
+func (s *QuestionService) ModerateAnswer(ctx context.Context, id string, status domain.ReviewStatus) error {
+	return s.repo.SetAnswerStatus(ctx, id, status)
+}
+
+func (s *QuestionService) Vote(ctx context.Context, userID, targetType, targetID string) error {
+	return s.repo.Vote(ctx, targetType, targetID, userID)
+}
+
+func (s *QuestionService) Unvote(ctx context.Context, userID, targetType, targetID string) error {
+	return s.repo.Unvote(ctx, targetType, targetID, userID)
+}
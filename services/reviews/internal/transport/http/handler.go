@@ -11,11 +11,12 @@ import (
 )
 
 type Handler struct {
-	reviews *service.ReviewService
+	reviews   *service.ReviewService
+	questions *service.QuestionService
 }
 
-func New(reviews *service.ReviewService) *Handler {
-	return &Handler{reviews: reviews}
+func New(reviews *service.ReviewService, questions *service.QuestionService) *Handler {
+	return &Handler{reviews: reviews, questions: questions}
 }
 
 type createRequest struct {
@@ -0,0 +1,237 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/domain"
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/middleware"
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/repository"
+	"github.com/devmanishoffl/sabhyatam-reviews/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultQuestionLimit = 10
+	maxQuestionLimit     = 50
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type bodyRequest struct {
+	Body string `json:"body"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeQAError maps Q&A errors to status codes.
+func writeQAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBody):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrNotVerifiedBuyer),
+		errors.Is(err, repository.ErrOwnPost):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrProductNotFound),
+		errors.Is(err, repository.ErrQuestionNotFound),
+		errors.Is(err, repository.ErrAnswerNotFound),
+		errors.Is(err, repository.ErrVoteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrQuestionClosed),
+		errors.Is(err, repository.ErrAlreadyVoted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// idParam returns a UUID path parameter, answering 404 for anything else so
+// malformed IDs never reach Postgres.
+func idParam(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	id := chi.URLParam(r, name)
+	if !uuidPattern.MatchString(id) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return "", false
+	}
+	return id, true
+}
+
+// ListQuestions serves a product's approved questions with their approved
+// answers. Query: page (1-based), limit (max 50) and sort ("helpful", the
+// default, or "recent").
+func (h *Handler) ListQuestions(w http.ResponseWriter, r *http.Request) {
+	productID, ok := idParam(w, r, "productID")
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = defaultQuestionLimit
+	}
+	if limit > maxQuestionLimit {
+		limit = maxQuestionLimit
+	}
+
+	result, err := h.questions.ListProductQuestions(r.Context(), productID, q.Get("sort"), page, limit)
+	if err != nil {
+		http.Error(w, "failed to fetch questions", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) AskQuestion(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	productID, ok := idParam(w, r, "productID")
+	if !ok {
+		return
+	}
+
+	var req bodyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	q, err := h.questions.Ask(r.Context(), userID, productID, req.Body)
+	if err != nil {
+		writeQAError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, q)
+}
+
+func (h *Handler) AnswerQuestion(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(string)
+	questionID, ok := idParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req bodyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	a, err := h.questions.Answer(r.Context(), userID, questionID, req.Body)
+	if err != nil {
+		writeQAError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// voteHandler builds the vote and unvote handlers for one target type.
+func (h *Handler) voteHandler(targetType string, remove bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Context().Value(middleware.UserIDKey).(string)
+		targetID, ok := idParam(w, r, "id")
+		if !ok {
+			return
+		}
+
+		var err error
+		if remove {
+			err = h.questions.Unvote(r.Context(), userID, targetType, targetID)
+		} else {
+			err = h.questions.Vote(r.Context(), userID, targetType, targetID)
+		}
+		if err != nil {
+			writeQAError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *Handler) VoteQuestion() http.HandlerFunc {
+	return h.voteHandler(repository.TargetQuestion, false)
+}
+
+func (h *Handler) UnvoteQuestion() http.HandlerFunc {
+	return h.voteHandler(repository.TargetQuestion, true)
+}
+
+func (h *Handler) VoteAnswer() http.HandlerFunc {
+	return h.voteHandler(repository.TargetAnswer, false)
+}
+
+func (h *Handler) UnvoteAnswer() http.HandlerFunc {
+	return h.voteHandler(repository.TargetAnswer, true)
+}
+
+// --- ADMIN ---
+
+func (h *Handler) ListPendingQA(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.questions.ListPending(r.Context())
+	if err != nil {
+		http.Error(w, "failed to fetch pending questions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
+func (h *Handler) AdminAnswerQuestion(w http.ResponseWriter, r *http.Request) {
+	questionID, ok := idParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req bodyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	a, err := h.questions.AdminAnswer(r.Context(), questionID, req.Body)
+	if err != nil {
+		writeQAError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// ModerateQuestion and ModerateAnswer build the approve and reject handlers.
+func (h *Handler) ModerateQuestion(status domain.ReviewStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := idParam(w, r, "id")
+		if !ok {
+			return
+		}
+		if err := h.questions.ModerateQuestion(r.Context(), id, status); err != nil {
+			writeQAError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *Handler) ModerateAnswer(status domain.ReviewStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := idParam(w, r, "id")
+		if !ok {
+			return
+		}
+		if err := h.questions.ModerateAnswer(r.Context(), id, status); err != nil {
+			writeQAError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
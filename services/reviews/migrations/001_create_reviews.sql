@@ -1,4 +1,4 @@
-create table reviews (
+create table if not exists reviews (
   id uuid primary key default gen_random_uuid(),
 
   user_id uuid not null,
@@ -20,9 +20,9 @@ create table reviews (
   unique (order_item_id)
 );
 
-create index idx_reviews_product_created
+create index if not exists idx_reviews_product_created
   on reviews (product_id, created_at desc);
   
 
-create index idx_reviews_product_status
+create index if not exists idx_reviews_product_status
   on reviews (product_id, status);
@@ -0,0 +1,53 @@
+create table if not exists product_questions (
+  id uuid primary key default gen_random_uuid(),
+
+  product_id uuid not null,
+  user_id uuid not null,
+
+  body text not null,
+
+  status text not null default 'pending'
+    check (status in ('pending', 'approved', 'rejected')),
+
+  helpful_count int not null default 0,
+
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists idx_questions_product_status
+  on product_questions (product_id, status, created_at desc);
+
+create table if not exists product_answers (
+  id uuid primary key default gen_random_uuid(),
+
+  question_id uuid not null references product_questions(id) on delete cascade,
+
+  -- null for answers written by the shop itself
+  user_id uuid,
+  author_role text not null
+    check (author_role in ('admin', 'buyer')),
+
+  body text not null,
+
+  status text not null default 'pending'
+    check (status in ('pending', 'approved', 'rejected')),
+
+  helpful_count int not null default 0,
+
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index if not exists idx_answers_question_status
+  on product_answers (question_id, status);
+
+-- one helpful vote per user per question or answer
+create table if not exists qa_helpful_votes (
+  target_type text not null check (target_type in ('question', 'answer')),
+  target_id uuid not null,
+  user_id uuid not null,
+  created_at timestamptz not null default now(),
+
+  primary key (target_type, target_id, user_id)
+);